@@ -10,9 +10,13 @@ import (
 	"github.com/loft-sh/devspace/pkg/util/imageselector"
 	"github.com/loft-sh/devspace/pkg/util/survey"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
@@ -31,6 +35,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/devspace/pullsecrets"
@@ -235,6 +240,9 @@ func (cmd *DevCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *c
 		dockerClient = nil
 	}
 
+	// Delete the resources that were created for this session on exit
+	defer exit.Cleanup()
+
 	// Build and deploy images
 	exitCode, err := cmd.buildAndDeploy(f, configInterface, configOptions, client, dockerClient, args)
 	if err != nil {
@@ -396,6 +404,10 @@ func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Confi
 		useTerminal     = config.Dev.Terminal != nil && config.Dev.Terminal.Disabled == false
 	)
 
+	// return through the exit channel on an interrupt, so that the resources of this session are cleaned up
+	stopInterrupt := notifyInterrupt(exitChan)
+	defer stopInterrupt()
+
 	// replace pods
 	err := servicesClient.ReplacePods()
 	if err != nil {
@@ -408,6 +420,15 @@ func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Confi
 		if err != nil {
 			return 0, errors.Errorf("Unable to start portforwarding: %v", err)
 		}
+
+		// save the local ports that were chosen for port mappings with allowAlternativePort
+		if len(servicesClient.LocalPorts()) > 0 {
+			err = cmd.configLoader.SaveGenerated(configInterface.Generated())
+			if err != nil {
+				return 0, errors.Errorf("error saving generated config: %v", err)
+			}
+		}
+
 		err = servicesClient.StartReversePortForwarding(nil)
 		if err != nil {
 			return 0, errors.Errorf("Unable to start portforwarding: %v", err)
@@ -509,9 +530,60 @@ func (cmd *DevCmd) startServices(f factory.Factory, configInterface config.Confi
 		}
 	}
 
+	printLocalPorts(servicesClient.LocalPorts(), logger)
 	return cmd.startOutput(configInterface, dependencies, client, args, servicesClient, exitChan, logger)
 }
 
+// notifyInterrupt sends an error to the exit channel on the first interrupt or termination signal until the
+// returned function is called. Further signals terminate the process as usual
+func notifyInterrupt(exitChan chan error) func() {
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			signal.Stop(signals)
+			select {
+			case exitChan <- &exit.ReturnCodeError{ExitCode: 1}:
+			case <-done:
+			}
+		case <-done:
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// printLocalPorts prints the local ports that are used for the port mappings with allowAlternativePort
+func printLocalPorts(localPorts map[int]int, logger log.Logger) {
+	if len(localPorts) == 0 {
+		return
+	}
+
+	configuredPorts := make([]int, 0, len(localPorts))
+	for configured := range localPorts {
+		configuredPorts = append(configuredPorts, configured)
+	}
+	sort.Ints(configuredPorts)
+
+	rows := make([][]string, 0, len(configuredPorts))
+	for _, configured := range configuredPorts {
+		rows = append(rows, []string{
+			strconv.Itoa(configured),
+			strconv.Itoa(localPorts[configured]),
+			"${" + variable.LocalPortVariablePrefix + strconv.Itoa(configured) + "}",
+		})
+	}
+
+	logger.WriteString("\n")
+	logger.Info("Forwarded local ports:")
+	log.PrintTable(logger, []string{"Configured Port", "Local Port", "Variable"}, rows)
+}
+
 func (cmd *DevCmd) startOutput(configInterface config.Config, dependencies []types.Dependency, client kubectl.Client, args []string, servicesClient services.Client, exitChan chan error, logger log.Logger) (int, error) {
 	if configInterface == nil {
 		return 0, fmt.Errorf("config is nil")
@@ -775,14 +847,17 @@ func addDependenciesDevConfig(config *latest.Config, dependencies []types.Depend
 					}
 
 					config.Dev.Ports = append(config.Dev.Ports, &latest.PortForwardingConfig{
-						ImageName:           imageName,
-						ImageSelector:       p.ImageSelector,
-						LabelSelector:       p.LabelSelector,
-						ContainerName:       p.ContainerName,
-						Namespace:           p.Namespace,
-						Arch:                p.Arch,
-						PortMappings:        p.PortMappings,
-						PortMappingsReverse: p.PortMappingsReverse,
+						ImageName:             imageName,
+						ImageSelector:         p.ImageSelector,
+						LabelSelector:         p.LabelSelector,
+						ContainerName:         p.ContainerName,
+						Namespace:             p.Namespace,
+						Service:               p.Service,
+						Arch:                  p.Arch,
+						Multiplex:             p.Multiplex,
+						PortMappings:          p.PortMappings,
+						PortMappingsReverse:   p.PortMappingsReverse,
+						ReverseForwardService: p.ReverseForwardService,
 					})
 				}
 			}
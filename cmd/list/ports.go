@@ -4,6 +4,7 @@ import (
 	"strconv"
 
 	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/factory"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
@@ -64,6 +65,7 @@ func (cmd *portsCmd) RunListPort(f factory.Factory, cobraCmd *cobra.Command, arg
 		"Image",
 		"ImageSelector",
 		"LabelSelector",
+		"Service",
 		"Ports (Local:Remote)",
 	}
 
@@ -93,6 +95,9 @@ func (cmd *portsCmd) RunListPort(f factory.Factory, cobraCmd *cobra.Command, arg
 				}
 
 				portMappings += strconv.Itoa(*v.LocalPort) + ":" + strconv.Itoa(remotePort)
+				if v.Protocol == latest.PortMappingProtocolUDP {
+					portMappings += "/udp"
+				}
 			}
 		}
 
@@ -100,6 +105,7 @@ func (cmd *portsCmd) RunListPort(f factory.Factory, cobraCmd *cobra.Command, arg
 			value.ImageName,
 			value.ImageSelector,
 			selector,
+			value.Service,
 			portMappings,
 		})
 	}
@@ -0,0 +1,160 @@
+package status
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/server"
+	"github.com/loft-sh/devspace/pkg/devspace/services/synccontroller"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+type syncCmd struct {
+	*flags.GlobalFlags
+
+	Host string
+	Port int
+}
+
+func newSyncCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &syncCmd{GlobalFlags: globalFlags}
+
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Shows the status of the running syncs",
+		Long: `
+#######################################################
+################ devspace status sync #################
+#######################################################
+Shows the status of the syncs that were started by a
+running devspace dev command.
+
+The status is retrieved from the ui server of devspace
+dev, which is searched on the default ui ports if no
+port is specified.
+#######################################################
+	`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.RunStatusSync(f, cobraCmd, args)
+		}}
+
+	syncCmd.Flags().StringVar(&cmd.Host, "host", "localhost", "The host of the ui server")
+	syncCmd.Flags().IntVar(&cmd.Port, "port", 0, "The port of the ui server (by default the ports starting at 8090 are searched)")
+	return syncCmd
+}
+
+// RunStatusSync runs the status sync command logic
+func (cmd *syncCmd) RunStatusSync(f factory.Factory, cobraCmd *cobra.Command, args []string) error {
+	logger := f.GetLog()
+
+	addresses, err := server.FindServers(cmd.Host, cmd.Port)
+	if err != nil {
+		return err
+	}
+
+	// Collect the syncs of all running ui servers
+	statuses := []synccontroller.Status{}
+	for _, address := range addresses {
+		serverStatuses, err := server.GetSyncStatus(address)
+		if err != nil {
+			return errors.Wrapf(err, "retrieve sync status from %s", address)
+		}
+
+		statuses = append(statuses, serverStatuses...)
+	}
+
+	if len(statuses) == 0 {
+		logger.Info("No running syncs found. Please make sure `devspace dev` is running")
+		return nil
+	}
+
+	headerColumnNames := []string{
+		"Pod",
+		"Local Path",
+		"Container Path",
+		"Status",
+		"Pending (Up/Down)",
+		"Transferred (Up/Down)",
+		"Last Upload",
+		"Last Download",
+		"Conflicts",
+		"Last Error",
+	}
+
+	values := make([][]string, 0, len(statuses))
+	for _, status := range statuses {
+		state := "Watching"
+		if status.Stopped {
+			state = "Stopped"
+		} else if status.InitialSyncCompleted == false {
+			state = "Initial Sync"
+		}
+		if status.Stopped == false && status.UploadProgress != nil {
+			state += ", " + formatProgress(status.UploadProgress)
+		}
+		if status.Stopped == false && status.DownloadProgress != nil {
+			state += ", " + formatProgress(status.DownloadProgress)
+		}
+
+		values = append(values, []string{
+			status.Pod,
+			status.LocalPath,
+			status.ContainerPath,
+			state,
+			fmt.Sprintf("%d/%d", status.PendingUploads, status.PendingDownloads),
+			sync.FormatBytes(status.BytesUploaded) + "/" + sync.FormatBytes(status.BytesDownloaded),
+			formatSince(status.LastUpload),
+			formatSince(status.LastDownload),
+			strconv.Itoa(status.Conflicts),
+			status.LastError,
+		})
+	}
+
+	log.PrintTable(logger, headerColumnNames, values)
+
+	// Paths that collide locally are never synced, so they need to be resolved by the user
+	for _, status := range statuses {
+		for _, collision := range status.Collisions {
+			if collision.CollidesWith != "" {
+				logger.Warnf("%s: .%s %s from .%s and is not synced, because the local filesystem is case insensitive", status.ContainerPath, collision.Path, collision.Reason, collision.CollidesWith)
+			} else {
+				logger.Warnf("%s: .%s %s and is not synced", status.ContainerPath, collision.Path, collision.Reason)
+			}
+		}
+	}
+
+	return nil
+}
+
+// formatProgress formats a running transfer, e.g. "Uploading 45% (ETA 12s)"
+func formatProgress(progress *sync.Progress) string {
+	action := "Uploading"
+	if progress.Direction == sync.ProgressDirectionDownload {
+		action = "Downloading"
+	}
+
+	percent := int64(100)
+	if progress.BytesTotal > 0 {
+		percent = progress.BytesDone * 100 / progress.BytesTotal
+	}
+	if progress.ETA < 0 {
+		return fmt.Sprintf("%s %d%%", action, percent)
+	}
+
+	return fmt.Sprintf("%s %d%% (ETA %s)", action, percent, time.Duration(progress.ETA)*time.Second)
+}
+
+func formatSince(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+
+	return time.Since(*t).Round(time.Second).String() + " ago"
+}
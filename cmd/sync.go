@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/plugin"
@@ -14,7 +15,10 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/config/loader"
 	latest "github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	"github.com/loft-sh/devspace/pkg/util/exit"
 	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/survey"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -34,6 +38,7 @@ type SyncCmd struct {
 	LocalPath     string
 
 	InitialSync string
+	Symlinks    string
 
 	Verbose bool
 
@@ -41,6 +46,9 @@ type SyncCmd struct {
 	DownloadOnInitialSync bool
 	DownloadOnly          bool
 	UploadOnly            bool
+
+	DryRun bool
+	Output string
 }
 
 // NewSyncCmd creates a new init command
@@ -62,6 +70,7 @@ devspace sync --local-path=subfolder --container-path=/app
 devspace sync --exclude=node_modules --exclude=test
 devspace sync --pod=my-pod --container=my-container
 devspace sync --container-path=/my-path
+devspace sync --initial-sync=mirrorLocal --dry-run
 #######################################################`,
 		RunE: func(cobraCmd *cobra.Command, args []string) error {
 			// Print upgrade message if new version available
@@ -82,6 +91,7 @@ devspace sync --container-path=/my-path
 
 	syncCmd.Flags().BoolVar(&cmd.DownloadOnInitialSync, "download-on-initial-sync", true, "DEPRECATED: Downloads all locally non existing remote files in the beginning")
 	syncCmd.Flags().StringVar(&cmd.InitialSync, "initial-sync", "", "The initial sync strategy to use (mirrorLocal, mirrorRemote, preferLocal, preferRemote, preferNewest, keepAll)")
+	syncCmd.Flags().StringVar(&cmd.Symlinks, "symlinks", "", "How symlinks are synced (follow, preserve, ignore)")
 
 	syncCmd.Flags().BoolVar(&cmd.NoWatch, "no-watch", false, "Synchronizes local and remote and then stops")
 	syncCmd.Flags().BoolVar(&cmd.Verbose, "verbose", false, "Shows every file that is synced")
@@ -89,6 +99,11 @@ devspace sync --container-path=/my-path
 	syncCmd.Flags().BoolVar(&cmd.UploadOnly, "upload-only", false, "If set DevSpace will only upload files")
 	syncCmd.Flags().BoolVar(&cmd.DownloadOnly, "download-only", false, "If set DevSpace will only download files")
 
+	syncCmd.Flags().BoolVar(&cmd.DryRun, "dry-run", false, "Prints the changes the initial sync would apply without changing anything")
+	syncCmd.Flags().StringVarP(&cmd.Output, "output", "o", "", "The output format of --dry-run. Can be either empty or json")
+
+	syncCmd.AddCommand(NewSyncWaitCmd(f, globalFlags))
+
 	return syncCmd
 }
 
@@ -160,6 +175,11 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 	if cmd.DownloadOnly && cmd.UploadOnly {
 		return errors.New("--upload-only cannot be used together with --download-only")
 	}
+	if cmd.Output != "" && cmd.DryRun == false {
+		return errors.New("--output can only be used together with --dry-run")
+	} else if cmd.Output != "" && cmd.Output != "json" {
+		return errors.Errorf("unsupported value for flag --output: %s", cmd.Output)
+	}
 
 	syncConfig := &latest.SyncConfig{
 		LocalSubPath:    cmd.LocalPath,
@@ -182,6 +202,13 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 
 		syncConfig.InitialSync = latest.InitialSyncStrategy(cmd.InitialSync)
 	}
+	if cmd.Symlinks != "" {
+		if loader.ValidSyncSymlinks(latest.SyncSymlinks(cmd.Symlinks)) == false {
+			return errors.Errorf("--symlinks is not valid '%s'", cmd.Symlinks)
+		}
+
+		syncConfig.Symlinks = latest.SyncSymlinks(cmd.Symlinks)
+	}
 
 	if cmd.GlobalFlags.ConfigPath != "" && config != nil && len(config.Dev.Sync) > 0 {
 		// Check which sync config should be used
@@ -207,6 +234,8 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 					selector = "img-selector: " + sc.ImageSelector
 				} else if len(sc.LabelSelector) > 0 {
 					selector = "selector: " + labels.Set(sc.LabelSelector).String()
+				} else if sc.PersistentVolumeClaim != nil {
+					selector = "pvc: " + sc.PersistentVolumeClaim.Name
 				}
 
 				syncConfigNames = append(syncConfigNames, fmt.Sprintf("%d: Sync %s: %s <-> %s ", idx, selector, localPath, remotePath))
@@ -242,6 +271,9 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 		if len(syncConfig.ExcludePaths) > 0 {
 			loadedSyncConfig.ExcludePaths = syncConfig.ExcludePaths
 		}
+		if syncConfig.Symlinks != "" {
+			loadedSyncConfig.Symlinks = syncConfig.Symlinks
+		}
 		if options.ContainerName != "" {
 			loadedSyncConfig.ContainerName = options.ContainerName
 		}
@@ -249,6 +281,7 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 			loadedSyncConfig.LabelSelector = nil
 			loadedSyncConfig.ImageName = ""
 			loadedSyncConfig.ImageSelector = ""
+			loadedSyncConfig.PersistentVolumeClaim = nil
 		}
 		if options.Namespace != "" {
 			loadedSyncConfig.Namespace = ""
@@ -264,6 +297,70 @@ func (cmd *SyncCmd) Run(f factory.Factory, plugins []plugin.Metadata, cobraCmd *
 		options = options.ApplyConfigParameter(syncConfig.LabelSelector, syncConfig.Namespace, syncConfig.ContainerName, "")
 	}
 
-	// Start terminal
-	return f.NewServicesClient(configInterface, nil, client, logger).StartSyncFromCmd(options, syncConfig, nil, cmd.Verbose)
+	if cmd.DryRun {
+		// keep the json output parsable
+		dryRunLog := logger
+		if cmd.Output == "json" {
+			dryRunLog = log.Discard
+		}
+
+		diff, err := f.NewServicesClient(configInterface, nil, client, dryRunLog).DryRunSyncFromCmd(options, syncConfig, cmd.Verbose)
+		if err != nil {
+			return err
+		}
+
+		return printSyncDiff(diff, cmd.Output, logger)
+	}
+
+	// Delete the resources that were created for this session on exit
+	defer exit.Cleanup()
+
+	// Start sync
+	exitChan := make(chan error, 1)
+	stopInterrupt := notifyInterrupt(exitChan)
+	defer stopInterrupt()
+	go func() {
+		exitChan <- f.NewServicesClient(configInterface, nil, client, logger).StartSyncFromCmd(options, syncConfig, nil, cmd.Verbose)
+	}()
+
+	return <-exitChan
+}
+
+func printSyncDiff(diff *sync.Diff, output string, logger log.Logger) error {
+	switch output {
+	case "":
+		if diff.Empty() {
+			logger.Info("The initial sync would not change anything")
+			return nil
+		}
+
+		rows := [][]string{}
+		for _, change := range []struct {
+			name  string
+			paths []string
+		}{
+			{"upload", diff.Upload},
+			{"download", diff.Download},
+			{"delete local", diff.DeleteLocal},
+			{"delete remote", diff.DeleteRemote},
+		} {
+			for _, path := range change.paths {
+				rows = append(rows, []string{change.name, path})
+			}
+		}
+
+		log.PrintTable(logger, []string{"Change", "Path"}, rows)
+		logger.Infof("%d to upload, %d to download, %d to delete locally, %d to delete remotely", len(diff.Upload), len(diff.Download), len(diff.DeleteLocal), len(diff.DeleteRemote))
+	case "json":
+		out, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(out))
+	default:
+		return errors.Errorf("unsupported value for flag --output: %s", output)
+	}
+
+	return nil
 }
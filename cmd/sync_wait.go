@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/loft-sh/devspace/cmd/flags"
+	"github.com/loft-sh/devspace/pkg/devspace/server"
+	"github.com/loft-sh/devspace/pkg/util/factory"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// SyncWaitCmd is a struct that defines a command call for "sync wait"
+type SyncWaitCmd struct {
+	*flags.GlobalFlags
+
+	Host    string
+	Port    int
+	Timeout int
+}
+
+// NewSyncWaitCmd creates a new sync wait command
+func NewSyncWaitCmd(f factory.Factory, globalFlags *flags.GlobalFlags) *cobra.Command {
+	cmd := &SyncWaitCmd{GlobalFlags: globalFlags}
+
+	syncWaitCmd := &cobra.Command{
+		Use:   "wait",
+		Short: "Waits until the running syncs have uploaded all local changes",
+		Long: `
+#######################################################
+################# devspace sync wait ##################
+#######################################################
+Waits until the syncs of a running devspace dev command
+have completed the initial sync and uploaded all local
+changes that were observed before this command was
+called:
+
+devspace sync wait
+devspace sync wait --timeout=30
+devspace sync wait --port=8091
+#######################################################`,
+		Args: cobra.NoArgs,
+		RunE: func(cobraCmd *cobra.Command, args []string) error {
+			return cmd.Run(f, cobraCmd, args)
+		},
+	}
+
+	syncWaitCmd.Flags().StringVar(&cmd.Host, "host", "localhost", "The host of the ui server")
+	syncWaitCmd.Flags().IntVar(&cmd.Port, "port", 0, "The port of the ui server (by default the ports starting at 8090 are searched)")
+	syncWaitCmd.Flags().IntVar(&cmd.Timeout, "timeout", 120, "Timeout in seconds until the command should stop waiting and fail (0 waits indefinitely)")
+	return syncWaitCmd
+}
+
+// Run executes the command logic
+func (cmd *SyncWaitCmd) Run(f factory.Factory, cobraCmd *cobra.Command, args []string) error {
+	logger := f.GetLog()
+	addresses, err := server.FindServers(cmd.Host, cmd.Port)
+	if err != nil {
+		return err
+	} else if len(addresses) == 0 {
+		return errors.New("Couldn't find a running ui server. Please make sure `devspace dev` is running with the ui enabled")
+	}
+
+	logger.StartWait("Waiting for the sync to upload all local changes")
+	defer logger.StopWait()
+
+	syncs := 0
+	for _, address := range addresses {
+		statuses, err := server.WaitForSync(address, time.Duration(cmd.Timeout)*time.Second)
+		if err != nil {
+			return errors.Wrapf(err, "wait for sync of %s", address)
+		}
+
+		syncs += len(statuses)
+	}
+
+	logger.StopWait()
+	if syncs == 0 {
+		logger.Warn("No running syncs found")
+		return nil
+	}
+
+	logger.Donef("Successfully uploaded all local changes of %d sync(s)", syncs)
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/loft-sh/devspace/helper/mux"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// MuxCmd holds the mux cmd flags
+type MuxCmd struct{}
+
+// NewMuxCmd creates a new mux command
+func NewMuxCmd() *cobra.Command {
+	cmd := &MuxCmd{}
+	muxCmd := &cobra.Command{
+		Use:   "mux",
+		Short: "Serves the sync, tunnel and restart commands over a single multiplexed stream",
+		Args:  cobra.NoArgs,
+		RunE:  cmd.Run,
+	}
+
+	return muxCmd
+}
+
+// Run runs the command logic
+func (cmd *MuxCmd) Run(cobraCmd *cobra.Command, args []string) error {
+	return mux.Serve(os.Stdin, os.Stdout, func(args []string, stream io.ReadWriter) error {
+		if len(args) > 0 && args[0] == "mux" {
+			return errors.New("cannot nest multiplexed sessions")
+		}
+
+		rootCmd := BuildRoot()
+		rootCmd.SetArgs(args)
+		rootCmd.SetIn(stream)
+		rootCmd.SetOut(stream)
+		rootCmd.SetErr(os.Stderr)
+		rootCmd.SilenceErrors = true
+		rootCmd.SilenceUsage = true
+		return rootCmd.Execute()
+	})
+}
@@ -36,6 +36,7 @@ func BuildRoot() *cobra.Command {
 	rootCmd.AddCommand(NewRestartCmd())
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewTunnelCmd())
+	rootCmd.AddCommand(NewMuxCmd())
 	rootCmd.AddCommand(sync.NewSyncCmd())
 
 	return rootCmd
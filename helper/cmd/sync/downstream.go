@@ -2,17 +2,21 @@ package sync
 
 import (
 	"github.com/loft-sh/devspace/helper/server"
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 // DownstreamCmd holds the downstream cmd flags
 type DownstreamCmd struct {
 	Exclude []string
 
+	UseGitignore    bool
+	UseDockerignore bool
+
 	Throttle int64
 
-	Polling bool
+	Polling  bool
+	Symlinks string
 }
 
 // NewDownstreamCmd creates a new downstream command
@@ -26,8 +30,11 @@ func NewDownstreamCmd() *cobra.Command {
 	}
 
 	downstreamCmd.Flags().StringSliceVar(&cmd.Exclude, "exclude", []string{}, "The exclude paths for downstream watching")
+	downstreamCmd.Flags().BoolVar(&cmd.UseGitignore, "use-gitignore", false, "If true, the patterns of all .gitignore files are excluded as well")
+	downstreamCmd.Flags().BoolVar(&cmd.UseDockerignore, "use-dockerignore", false, "If true, the patterns of the .dockerignore file are excluded as well")
 	downstreamCmd.Flags().Int64Var(&cmd.Throttle, "throttle", 5, "The amount of milliseconds to throttle change detection per 100 files")
 	downstreamCmd.Flags().BoolVar(&cmd.Polling, "polling", false, "If true, DevSpace will use polling instead of inotify")
+	downstreamCmd.Flags().StringVar(&cmd.Symlinks, "symlinks", util.SymlinksFollow, "How symlinks are handled, either follow, preserve or ignore")
 	return downstreamCmd
 }
 
@@ -38,12 +45,17 @@ func (cmd *DownstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return server.StartDownstreamServer(os.Stdin, os.Stdout, &server.DownstreamOptions{
+	reader, writer, exitOnClose := util.Streams(cobraCmd)
+	return server.StartDownstreamServer(reader, writer, &server.DownstreamOptions{
 		RemotePath:   absolutePath,
 		ExcludePaths: cmd.Exclude,
 
+		UseGitignore:    cmd.UseGitignore,
+		UseDockerignore: cmd.UseDockerignore,
+
 		Throttle:    cmd.Throttle,
 		Polling:     cmd.Polling,
-		ExitOnClose: true,
+		Symlinks:    cmd.Symlinks,
+		ExitOnClose: exitOnClose,
 	})
 }
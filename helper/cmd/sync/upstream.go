@@ -1,8 +1,13 @@
 package sync
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/loft-sh/devspace/helper/server"
+	"github.com/loft-sh/devspace/helper/server/permissions"
+	"github.com/loft-sh/devspace/helper/server/uploadrules"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"os"
 	"path/filepath"
@@ -21,6 +26,13 @@ type UpstreamCmd struct {
 
 	OverridePermissions bool
 	Exclude             []string
+
+	UseGitignore    bool
+	UseDockerignore bool
+
+	Permissions string
+	UploadRules string
+	Symlinks    string
 }
 
 // NewUpstreamCmd creates a new upstream command
@@ -44,6 +56,11 @@ func NewUpstreamCmd() *cobra.Command {
 
 	upstreamCmd.Flags().BoolVar(&cmd.OverridePermissions, "override-permissions", false, "If enabled will override file permissions")
 	upstreamCmd.Flags().StringSliceVar(&cmd.Exclude, "exclude", []string{}, "The exclude paths for upstream watching")
+	upstreamCmd.Flags().BoolVar(&cmd.UseGitignore, "use-gitignore", false, "If true, the patterns of all .gitignore files are excluded as well")
+	upstreamCmd.Flags().BoolVar(&cmd.UseDockerignore, "use-dockerignore", false, "If true, the patterns of the .dockerignore file are excluded as well")
+	upstreamCmd.Flags().StringVar(&cmd.Permissions, "permissions", "", "The json encoded rules that map the permissions and ownership of written files")
+	upstreamCmd.Flags().StringVar(&cmd.UploadRules, "upload-rules", "", "The json encoded rules that run commands once per batch if an uploaded path matches")
+	upstreamCmd.Flags().StringVar(&cmd.Symlinks, "symlinks", util.SymlinksFollow, "How symlinks are handled, either follow, preserve or ignore")
 	return upstreamCmd
 }
 
@@ -54,10 +71,30 @@ func (cmd *UpstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	return server.StartUpstreamServer(os.Stdin, os.Stdout, &server.UpstreamOptions{
+	var rules []permissions.Rule
+	if cmd.Permissions != "" {
+		err = json.Unmarshal([]byte(cmd.Permissions), &rules)
+		if err != nil {
+			return errors.Wrap(err, "parse permissions")
+		}
+	}
+
+	var uploadRules []uploadrules.Rule
+	if cmd.UploadRules != "" {
+		err = json.Unmarshal([]byte(cmd.UploadRules), &uploadRules)
+		if err != nil {
+			return errors.Wrap(err, "parse upload rules")
+		}
+	}
+
+	reader, writer, exitOnClose := util.Streams(cobraCmd)
+	return server.StartUpstreamServer(reader, writer, &server.UpstreamOptions{
 		UploadPath:  absolutePath,
 		ExludePaths: cmd.Exclude,
 
+		UseGitignore:    cmd.UseGitignore,
+		UseDockerignore: cmd.UseDockerignore,
+
 		BatchCmd:  cmd.BatchCmd,
 		BatchArgs: cmd.BatchArgs,
 
@@ -68,7 +105,10 @@ func (cmd *UpstreamCmd) Run(cobraCmd *cobra.Command, args []string) error {
 		DirCreateArgs: cmd.DirCreateArgs,
 
 		OverridePermission: cmd.OverridePermissions,
-		ExitOnClose:        true,
+		Permissions:        rules,
+		UploadRules:        uploadRules,
+		Symlinks:           cmd.Symlinks,
+		ExitOnClose:        exitOnClose,
 	})
 }
 
@@ -2,8 +2,8 @@ package cmd
 
 import (
 	"github.com/loft-sh/devspace/helper/tunnel"
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 // TunnelCmd holds the tunnel cmd flags
@@ -24,5 +24,6 @@ func NewTunnelCmd() *cobra.Command {
 
 // Run runs the command logic
 func (cmd *TunnelCmd) Run(cobraCmd *cobra.Command, args []string) error {
-	return tunnel.StartTunnelServer(os.Stdin, os.Stdout, true)
+	reader, writer, exitOnClose := util.Streams(cobraCmd)
+	return tunnel.StartTunnelServer(reader, writer, exitOnClose)
 }
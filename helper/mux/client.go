@@ -0,0 +1,93 @@
+package mux
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/moby/spdystream"
+	"github.com/pkg/errors"
+)
+
+// streamTimeout is the time to wait for the helper to accept a new stream
+const streamTimeout = time.Minute
+
+// Session is a multiplexed connection to a helper process that was started with the mux command
+type Session struct {
+	conn *spdystream.Connection
+}
+
+// NewSession creates a new session on the given reader and writer
+func NewSession(reader io.Reader, writer io.Writer) (*Session, error) {
+	conn, err := spdystream.NewConnection(util.NewStdStreamJoint(reader, writer, false), false)
+	if err != nil {
+		return nil, errors.Wrap(err, "create connection")
+	}
+
+	go conn.Serve(spdystream.NoOpStreamHandler)
+	return &Session{
+		conn: conn,
+	}, nil
+}
+
+// Exec runs the helper command with the given arguments on a new stream of the session. The reader
+// is copied to the stream and the stream is copied to the writer until the helper command exits
+func (s *Session) Exec(args []string, reader io.Reader, writer io.Writer) error {
+	select {
+	case <-s.conn.CloseChan():
+		return errors.New("session closed")
+	default:
+	}
+
+	out, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+
+	stream, err := s.conn.CreateStream(http.Header{argsHeader: []string{string(out)}}, nil, false)
+	if err != nil {
+		return errors.Wrap(err, "create stream")
+	}
+
+	err = stream.WaitTimeout(streamTimeout)
+	if err != nil {
+		return errors.Wrap(err, "wait for stream")
+	}
+
+	// the helper sends an error header before closing the stream if the command failed
+	errChan := make(chan string, 1)
+	go func() {
+		header, err := stream.ReceiveHeader()
+		if err != nil {
+			errChan <- ""
+			return
+		}
+
+		errChan <- header.Get(errorHeader)
+	}()
+
+	go func() {
+		_, _ = io.Copy(stream, reader)
+		_ = stream.Close()
+	}()
+
+	_, _ = io.Copy(writer, stream)
+	if commandErr := <-errChan; commandErr != "" {
+		return errors.New(commandErr)
+	}
+
+	select {
+	case <-s.conn.CloseChan():
+		return errors.New("session closed")
+	default:
+	}
+
+	return nil
+}
+
+// Closed returns a channel that is closed if the session is closed
+func (s *Session) Closed() <-chan bool {
+	return s.conn.CloseChan()
+}
@@ -0,0 +1,73 @@
+package mux
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestSession(t *testing.T) {
+	serverReader, clientWriter := io.Pipe()
+	clientReader, serverWriter := io.Pipe()
+
+	go func() {
+		_ = Serve(serverReader, serverWriter, func(args []string, stream io.ReadWriter) error {
+			switch args[0] {
+			case "echo":
+				_, err := io.Copy(stream, stream)
+				return err
+			case "fail":
+				return errors.Errorf("unknown command %s", strings.Join(args[1:], " "))
+			}
+
+			return nil
+		})
+	}()
+
+	session, err := NewSession(clientReader, clientWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// multiple streams are served concurrently over the same connection
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 5)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			input := strings.Repeat(fmt.Sprintf("stream %d ", i), 10000)
+			output := &bytes.Buffer{}
+			err := session.Exec([]string{"echo"}, strings.NewReader(input), output)
+			if err != nil {
+				errs <- err
+			} else if output.String() != input {
+				errs <- errors.Errorf("unexpected output of stream %d", i)
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	err = session.Exec([]string{"fail", "sync", "sideways"}, strings.NewReader(""), &bytes.Buffer{})
+	if err == nil || err.Error() != "unknown command sync sideways" {
+		t.Fatalf("Expected command error, got %v", err)
+	}
+
+	// streams fail if the connection is lost
+	_ = serverWriter.Close()
+	<-session.Closed()
+	err = session.Exec([]string{"echo"}, strings.NewReader("test"), &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("Expected error after the session was closed")
+	}
+}
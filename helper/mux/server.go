@@ -0,0 +1,57 @@
+package mux
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/moby/spdystream"
+	"github.com/pkg/errors"
+)
+
+const (
+	// argsHeader holds the json encoded arguments of the helper command that should be run on a stream
+	argsHeader = "X-Devspace-Args"
+
+	// errorHeader holds the error of the helper command, it is sent before the stream is closed
+	errorHeader = "X-Devspace-Error"
+)
+
+// Handler runs the helper command with the given arguments on the stream
+type Handler func(args []string, stream io.ReadWriter) error
+
+// Serve serves a multiplexed session on the given reader and writer. Every stream that is opened
+// by the client runs a helper command, the function returns after the connection is closed
+func Serve(reader io.Reader, writer io.Writer, handler Handler) error {
+	conn, err := spdystream.NewConnection(util.NewStdStreamJoint(reader, writer, false), true)
+	if err != nil {
+		return errors.Wrap(err, "create connection")
+	}
+
+	conn.Serve(func(stream *spdystream.Stream) {
+		args := []string{}
+		err := json.Unmarshal([]byte(stream.Headers().Get(argsHeader)), &args)
+		if err != nil {
+			_ = stream.Refuse()
+			return
+		}
+
+		err = stream.SendReply(http.Header{}, false)
+		if err != nil {
+			return
+		}
+
+		// the stream handler must not block, otherwise no further frames are processed
+		go func() {
+			err := handler(args, stream)
+			if err != nil {
+				_ = stream.SendHeader(http.Header{errorHeader: []string{err.Error()}}, false)
+			}
+
+			_ = stream.Close()
+		}()
+	})
+
+	return nil
+}
@@ -153,6 +153,10 @@ func (m *LogMessage) GetMessage() string {
 	return ""
 }
 
+// SocketDataRequest opens a tunnel with the first request and carries the data of
+// its sessions afterwards. Udp tunnels send every datagram as a separate request.
+// If forward is set for a udp tunnel, the client listens and the helper dials the
+// port for every session, otherwise the helper listens on the port
 type SocketDataRequest struct {
 	Port                 int32        `protobuf:"varint,1,opt,name=port,proto3" json:"port,omitempty"`
 	RequestId            string       `protobuf:"bytes,2,opt,name=requestId,proto3" json:"requestId,omitempty"`
@@ -160,6 +164,7 @@ type SocketDataRequest struct {
 	Scheme               TunnelScheme `protobuf:"varint,4,opt,name=scheme,proto3,enum=remote.TunnelScheme" json:"scheme,omitempty"`
 	Data                 []byte       `protobuf:"bytes,5,opt,name=data,proto3" json:"data,omitempty"`
 	ShouldClose          bool         `protobuf:"varint,6,opt,name=shouldClose,proto3" json:"shouldClose,omitempty"`
+	Forward              bool         `protobuf:"varint,7,opt,name=forward,proto3" json:"forward,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
 	XXX_unrecognized     []byte       `json:"-"`
 	XXX_sizecache        int32        `json:"-"`
@@ -232,6 +237,13 @@ func (m *SocketDataRequest) GetShouldClose() bool {
 	return false
 }
 
+func (m *SocketDataRequest) GetForward() bool {
+	if m != nil {
+		return m.Forward
+	}
+	return false
+}
+
 type SocketDataResponse struct {
 	HasErr               bool        `protobuf:"varint,1,opt,name=hasErr,proto3" json:"hasErr,omitempty"`
 	LogMessage           *LogMessage `protobuf:"bytes,2,opt,name=logMessage,proto3" json:"logMessage,omitempty"`
@@ -303,6 +315,95 @@ func (m *SocketDataResponse) GetShouldClose() bool {
 	return false
 }
 
+// HandshakeRequest is sent by the client after connecting and contains the
+// compression codecs the client supports in the order of its preference
+type HandshakeRequest struct {
+	Compression          []string `protobuf:"bytes,1,rep,name=Compression,proto3" json:"Compression,omitempty"`
+	CompressionLevel     int32    `protobuf:"varint,2,opt,name=CompressionLevel,proto3" json:"CompressionLevel,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HandshakeRequest) Reset()         { *m = HandshakeRequest{} }
+func (m *HandshakeRequest) String() string { return proto.CompactTextString(m) }
+func (*HandshakeRequest) ProtoMessage()    {}
+func (*HandshakeRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{3}
+}
+
+func (m *HandshakeRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HandshakeRequest.Unmarshal(m, b)
+}
+func (m *HandshakeRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HandshakeRequest.Marshal(b, m, deterministic)
+}
+func (m *HandshakeRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HandshakeRequest.Merge(m, src)
+}
+func (m *HandshakeRequest) XXX_Size() int {
+	return xxx_messageInfo_HandshakeRequest.Size(m)
+}
+func (m *HandshakeRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_HandshakeRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HandshakeRequest proto.InternalMessageInfo
+
+func (m *HandshakeRequest) GetCompression() []string {
+	if m != nil {
+		return m.Compression
+	}
+	return nil
+}
+
+func (m *HandshakeRequest) GetCompressionLevel() int32 {
+	if m != nil {
+		return m.CompressionLevel
+	}
+	return 0
+}
+
+// HandshakeResponse contains the compression codec that was chosen by the server
+type HandshakeResponse struct {
+	Compression          string   `protobuf:"bytes,1,opt,name=Compression,proto3" json:"Compression,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HandshakeResponse) Reset()         { *m = HandshakeResponse{} }
+func (m *HandshakeResponse) String() string { return proto.CompactTextString(m) }
+func (*HandshakeResponse) ProtoMessage()    {}
+func (*HandshakeResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{4}
+}
+
+func (m *HandshakeResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HandshakeResponse.Unmarshal(m, b)
+}
+func (m *HandshakeResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HandshakeResponse.Marshal(b, m, deterministic)
+}
+func (m *HandshakeResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HandshakeResponse.Merge(m, src)
+}
+func (m *HandshakeResponse) XXX_Size() int {
+	return xxx_messageInfo_HandshakeResponse.Size(m)
+}
+func (m *HandshakeResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_HandshakeResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HandshakeResponse proto.InternalMessageInfo
+
+func (m *HandshakeResponse) GetCompression() string {
+	if m != nil {
+		return m.Compression
+	}
+	return ""
+}
+
 type Watch struct {
 	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
 	Exclude              []string `protobuf:"bytes,2,rep,name=Exclude,proto3" json:"Exclude,omitempty"`
@@ -315,7 +416,7 @@ func (m *Watch) Reset()         { *m = Watch{} }
 func (m *Watch) String() string { return proto.CompactTextString(m) }
 func (*Watch) ProtoMessage()    {}
 func (*Watch) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{3}
+	return fileDescriptor_eefc82927d57d89b, []int{5}
 }
 
 func (m *Watch) XXX_Unmarshal(b []byte) error {
@@ -361,7 +462,7 @@ func (m *ChangeAmount) Reset()         { *m = ChangeAmount{} }
 func (m *ChangeAmount) String() string { return proto.CompactTextString(m) }
 func (*ChangeAmount) ProtoMessage()    {}
 func (*ChangeAmount) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{4}
+	return fileDescriptor_eefc82927d57d89b, []int{6}
 }
 
 func (m *ChangeAmount) XXX_Unmarshal(b []byte) error {
@@ -400,7 +501,7 @@ func (m *ChangeChunk) Reset()         { *m = ChangeChunk{} }
 func (m *ChangeChunk) String() string { return proto.CompactTextString(m) }
 func (*ChangeChunk) ProtoMessage()    {}
 func (*ChangeChunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{5}
+	return fileDescriptor_eefc82927d57d89b, []int{7}
 }
 
 func (m *ChangeChunk) XXX_Unmarshal(b []byte) error {
@@ -429,22 +530,25 @@ func (m *ChangeChunk) GetChanges() []*Change {
 }
 
 type Change struct {
-	ChangeType           ChangeType `protobuf:"varint,1,opt,name=ChangeType,proto3,enum=remote.ChangeType" json:"ChangeType,omitempty"`
-	Path                 string     `protobuf:"bytes,2,opt,name=Path,proto3" json:"Path,omitempty"`
-	MtimeUnix            int64      `protobuf:"varint,3,opt,name=MtimeUnix,proto3" json:"MtimeUnix,omitempty"`
-	MtimeUnixNano        int64      `protobuf:"varint,4,opt,name=MtimeUnixNano,proto3" json:"MtimeUnixNano,omitempty"`
-	Size                 int64      `protobuf:"varint,5,opt,name=Size,proto3" json:"Size,omitempty"`
-	IsDir                bool       `protobuf:"varint,6,opt,name=IsDir,proto3" json:"IsDir,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
-	XXX_unrecognized     []byte     `json:"-"`
-	XXX_sizecache        int32      `json:"-"`
+	ChangeType    ChangeType `protobuf:"varint,1,opt,name=ChangeType,proto3,enum=remote.ChangeType" json:"ChangeType,omitempty"`
+	Path          string     `protobuf:"bytes,2,opt,name=Path,proto3" json:"Path,omitempty"`
+	MtimeUnix     int64      `protobuf:"varint,3,opt,name=MtimeUnix,proto3" json:"MtimeUnix,omitempty"`
+	MtimeUnixNano int64      `protobuf:"varint,4,opt,name=MtimeUnixNano,proto3" json:"MtimeUnixNano,omitempty"`
+	Size          int64      `protobuf:"varint,5,opt,name=Size,proto3" json:"Size,omitempty"`
+	IsDir         bool       `protobuf:"varint,6,opt,name=IsDir,proto3" json:"IsDir,omitempty"`
+	// LinkTarget is the target of a preserved symlink, it is empty for
+	// regular files and directories
+	LinkTarget           string   `protobuf:"bytes,7,opt,name=LinkTarget,proto3" json:"LinkTarget,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Change) Reset()         { *m = Change{} }
 func (m *Change) String() string { return proto.CompactTextString(m) }
 func (*Change) ProtoMessage()    {}
 func (*Change) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{6}
+	return fileDescriptor_eefc82927d57d89b, []int{8}
 }
 
 func (m *Change) XXX_Unmarshal(b []byte) error {
@@ -507,258 +611,844 @@ func (m *Change) GetIsDir() bool {
 	return false
 }
 
-type Paths struct {
-	Paths                []string `protobuf:"bytes,1,rep,name=Paths,proto3" json:"Paths,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Change) GetLinkTarget() string {
+	if m != nil {
+		return m.LinkTarget
+	}
+	return ""
 }
 
-func (m *Paths) Reset()         { *m = Paths{} }
-func (m *Paths) String() string { return proto.CompactTextString(m) }
-func (*Paths) ProtoMessage()    {}
-func (*Paths) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{7}
+type ChecksumChunk struct {
+	Checksums            []*Checksum `protobuf:"bytes,1,rep,name=checksums,proto3" json:"checksums,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
 }
 
-func (m *Paths) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Paths.Unmarshal(m, b)
+func (m *ChecksumChunk) Reset()         { *m = ChecksumChunk{} }
+func (m *ChecksumChunk) String() string { return proto.CompactTextString(m) }
+func (*ChecksumChunk) ProtoMessage()    {}
+func (*ChecksumChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{9}
 }
-func (m *Paths) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Paths.Marshal(b, m, deterministic)
+
+func (m *ChecksumChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ChecksumChunk.Unmarshal(m, b)
 }
-func (m *Paths) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Paths.Merge(m, src)
+func (m *ChecksumChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ChecksumChunk.Marshal(b, m, deterministic)
 }
-func (m *Paths) XXX_Size() int {
-	return xxx_messageInfo_Paths.Size(m)
+func (m *ChecksumChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ChecksumChunk.Merge(m, src)
 }
-func (m *Paths) XXX_DiscardUnknown() {
-	xxx_messageInfo_Paths.DiscardUnknown(m)
+func (m *ChecksumChunk) XXX_Size() int {
+	return xxx_messageInfo_ChecksumChunk.Size(m)
+}
+func (m *ChecksumChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_ChecksumChunk.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Paths proto.InternalMessageInfo
+var xxx_messageInfo_ChecksumChunk proto.InternalMessageInfo
 
-func (m *Paths) GetPaths() []string {
+func (m *ChecksumChunk) GetChecksums() []*Checksum {
 	if m != nil {
-		return m.Paths
+		return m.Checksums
 	}
 	return nil
 }
 
-type Chunk struct {
-	Content              []byte   `protobuf:"bytes,1,opt,name=Content,proto3" json:"Content,omitempty"`
+type Checksum struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	Checksum             string   `protobuf:"bytes,2,opt,name=Checksum,proto3" json:"Checksum,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *Chunk) Reset()         { *m = Chunk{} }
-func (m *Chunk) String() string { return proto.CompactTextString(m) }
-func (*Chunk) ProtoMessage()    {}
-func (*Chunk) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{8}
+func (m *Checksum) Reset()         { *m = Checksum{} }
+func (m *Checksum) String() string { return proto.CompactTextString(m) }
+func (*Checksum) ProtoMessage()    {}
+func (*Checksum) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{10}
 }
 
-func (m *Chunk) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Chunk.Unmarshal(m, b)
+func (m *Checksum) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Checksum.Unmarshal(m, b)
 }
-func (m *Chunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Chunk.Marshal(b, m, deterministic)
+func (m *Checksum) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Checksum.Marshal(b, m, deterministic)
 }
-func (m *Chunk) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Chunk.Merge(m, src)
+func (m *Checksum) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Checksum.Merge(m, src)
 }
-func (m *Chunk) XXX_Size() int {
-	return xxx_messageInfo_Chunk.Size(m)
+func (m *Checksum) XXX_Size() int {
+	return xxx_messageInfo_Checksum.Size(m)
 }
-func (m *Chunk) XXX_DiscardUnknown() {
-	xxx_messageInfo_Chunk.DiscardUnknown(m)
+func (m *Checksum) XXX_DiscardUnknown() {
+	xxx_messageInfo_Checksum.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Chunk proto.InternalMessageInfo
+var xxx_messageInfo_Checksum proto.InternalMessageInfo
 
-func (m *Chunk) GetContent() []byte {
+func (m *Checksum) GetPath() string {
 	if m != nil {
-		return m.Content
+		return m.Path
 	}
-	return nil
+	return ""
 }
 
-type Empty struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *Checksum) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
 }
 
-func (m *Empty) Reset()         { *m = Empty{} }
-func (m *Empty) String() string { return proto.CompactTextString(m) }
-func (*Empty) ProtoMessage()    {}
-func (*Empty) Descriptor() ([]byte, []int) {
-	return fileDescriptor_eefc82927d57d89b, []int{9}
+type FileSignature struct {
+	Path                 string            `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	BlockSize            int64             `protobuf:"varint,2,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	Blocks               []*BlockSignature `protobuf:"bytes,3,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
-func (m *Empty) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_Empty.Unmarshal(m, b)
+func (m *FileSignature) Reset()         { *m = FileSignature{} }
+func (m *FileSignature) String() string { return proto.CompactTextString(m) }
+func (*FileSignature) ProtoMessage()    {}
+func (*FileSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{11}
 }
-func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+
+func (m *FileSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileSignature.Unmarshal(m, b)
 }
-func (m *Empty) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_Empty.Merge(m, src)
+func (m *FileSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileSignature.Marshal(b, m, deterministic)
 }
-func (m *Empty) XXX_Size() int {
-	return xxx_messageInfo_Empty.Size(m)
+func (m *FileSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileSignature.Merge(m, src)
 }
-func (m *Empty) XXX_DiscardUnknown() {
-	xxx_messageInfo_Empty.DiscardUnknown(m)
+func (m *FileSignature) XXX_Size() int {
+	return xxx_messageInfo_FileSignature.Size(m)
+}
+func (m *FileSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileSignature.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_Empty proto.InternalMessageInfo
+var xxx_messageInfo_FileSignature proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterEnum("remote.LogLevel", LogLevel_name, LogLevel_value)
-	proto.RegisterEnum("remote.TunnelScheme", TunnelScheme_name, TunnelScheme_value)
-	proto.RegisterEnum("remote.ChangeType", ChangeType_name, ChangeType_value)
-	proto.RegisterType((*LogMessage)(nil), "remote.LogMessage")
-	proto.RegisterType((*SocketDataRequest)(nil), "remote.SocketDataRequest")
-	proto.RegisterType((*SocketDataResponse)(nil), "remote.SocketDataResponse")
-	proto.RegisterType((*Watch)(nil), "remote.Watch")
-	proto.RegisterType((*ChangeAmount)(nil), "remote.ChangeAmount")
-	proto.RegisterType((*ChangeChunk)(nil), "remote.ChangeChunk")
-	proto.RegisterType((*Change)(nil), "remote.Change")
-	proto.RegisterType((*Paths)(nil), "remote.Paths")
-	proto.RegisterType((*Chunk)(nil), "remote.Chunk")
-	proto.RegisterType((*Empty)(nil), "remote.Empty")
+func (m *FileSignature) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
 }
 
-func init() { proto.RegisterFile("remote.proto", fileDescriptor_eefc82927d57d89b) }
-
-var fileDescriptor_eefc82927d57d89b = []byte{
-	// 730 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0x41, 0x6f, 0xf2, 0x46,
-	0x10, 0x65, 0x63, 0x6c, 0x60, 0x20, 0x91, 0xbb, 0x8d, 0x2a, 0x17, 0xa5, 0x12, 0xb5, 0xa2, 0xc8,
-	0x42, 0x51, 0x94, 0xba, 0x8a, 0x7a, 0x4e, 0xc0, 0x4d, 0x91, 0x08, 0x41, 0x0b, 0x34, 0x67, 0x17,
-	0x56, 0x80, 0x62, 0x7b, 0xa9, 0x77, 0x49, 0x93, 0xfe, 0xae, 0x9e, 0xf3, 0x13, 0x7a, 0xed, 0xdf,
-	0xa9, 0x76, 0xd7, 0x06, 0x3b, 0xf9, 0xa2, 0x7c, 0xb7, 0x79, 0x33, 0xf3, 0x86, 0x79, 0x6f, 0xc7,
-	0x02, 0x5a, 0x29, 0x8d, 0x99, 0xa0, 0x17, 0x9b, 0x94, 0x09, 0x86, 0x2d, 0x8d, 0xdc, 0x29, 0xc0,
-	0x90, 0x2d, 0xef, 0x28, 0xe7, 0xe1, 0x92, 0xe2, 0x73, 0xa8, 0x47, 0x6c, 0x39, 0xa4, 0x4f, 0x34,
-	0x72, 0x50, 0x07, 0x79, 0x47, 0xbe, 0x7d, 0x91, 0xd1, 0x86, 0x59, 0x9e, 0xec, 0x3a, 0xb0, 0x03,
-	0xb5, 0x58, 0x13, 0x9d, 0x83, 0x0e, 0xf2, 0x1a, 0x24, 0x87, 0xee, 0x7f, 0x08, 0xbe, 0x99, 0xb0,
-	0xf9, 0x23, 0x15, 0xfd, 0x50, 0x84, 0x84, 0xfe, 0xb9, 0xa5, 0x5c, 0x60, 0x0c, 0xd5, 0x0d, 0x4b,
-	0x85, 0x9a, 0x6c, 0x12, 0x15, 0xe3, 0x13, 0x68, 0xa4, 0xba, 0x3c, 0x58, 0x64, 0x53, 0xf6, 0x89,
-	0xd2, 0x3e, 0xc6, 0xa7, 0xfb, 0x9c, 0x83, 0xc5, 0xe7, 0x2b, 0x1a, 0x53, 0xa7, 0xaa, 0x7a, 0x8f,
-	0xf3, 0xde, 0xe9, 0x36, 0x49, 0x68, 0x34, 0x51, 0x35, 0x92, 0xf5, 0xc8, 0x6d, 0x16, 0xa1, 0x08,
-	0x1d, 0xb3, 0x83, 0xbc, 0x16, 0x51, 0x31, 0xee, 0x40, 0x93, 0xaf, 0xd8, 0x36, 0x5a, 0xf4, 0x22,
-	0xc6, 0xa9, 0x63, 0x75, 0x90, 0x57, 0x27, 0xc5, 0x94, 0xfb, 0x0f, 0x02, 0x5c, 0x54, 0xc6, 0x37,
-	0x2c, 0xe1, 0x14, 0x7f, 0x07, 0xd6, 0x2a, 0xe4, 0x41, 0x9a, 0x2a, 0x71, 0x75, 0x92, 0x21, 0xec,
-	0x03, 0x44, 0x3b, 0x7b, 0x95, 0xbe, 0xa6, 0x8f, 0x0b, 0x12, 0xb2, 0x0a, 0x29, 0x74, 0x95, 0x2d,
-	0x31, 0xde, 0x5a, 0x92, 0xaf, 0x5d, 0xfd, 0x78, 0x6d, 0xf3, 0xfd, 0xda, 0x57, 0x60, 0x3e, 0x84,
-	0x62, 0xbe, 0x92, 0xf4, 0x71, 0x28, 0x56, 0x6a, 0xcd, 0x06, 0x51, 0xb1, 0x7c, 0xc7, 0xe0, 0x79,
-	0x1e, 0x6d, 0x17, 0x72, 0x43, 0x43, 0xbe, 0x63, 0x06, 0xdd, 0x33, 0x68, 0xf5, 0x56, 0x61, 0xb2,
-	0xa4, 0xd7, 0x31, 0xdb, 0x26, 0x42, 0xca, 0xd4, 0x91, 0xe2, 0x1b, 0x24, 0x43, 0xee, 0x2f, 0xd0,
-	0xd4, 0x7d, 0xbd, 0xd5, 0x36, 0x79, 0xc4, 0x1e, 0xd4, 0xe6, 0x0a, 0x72, 0x07, 0x75, 0x0c, 0xaf,
-	0xe9, 0x1f, 0xe5, 0x92, 0x75, 0x17, 0xc9, 0xcb, 0xee, 0x2b, 0x02, 0x4b, 0xe7, 0xa4, 0x55, 0x3a,
-	0x9a, 0xbe, 0x6c, 0x68, 0x76, 0x7d, 0xb8, 0xcc, 0x93, 0x15, 0x52, 0xe8, 0xda, 0xa9, 0x39, 0x28,
-	0xa8, 0x39, 0x81, 0xc6, 0x9d, 0x58, 0xc7, 0x74, 0x96, 0xac, 0x9f, 0x95, 0x7d, 0x06, 0xd9, 0x27,
-	0xf0, 0x29, 0x1c, 0xee, 0xc0, 0x28, 0x4c, 0x98, 0xf2, 0xd1, 0x20, 0xe5, 0xa4, 0x9c, 0x3b, 0x59,
-	0xff, 0xad, 0x9d, 0x34, 0x88, 0x8a, 0xf1, 0x31, 0x98, 0x03, 0xde, 0x5f, 0xa7, 0xd9, 0x55, 0x68,
-	0xe0, 0xfe, 0x00, 0xa6, 0xfc, 0x55, 0x2e, 0xcb, 0x2a, 0x50, 0x8a, 0x1b, 0x44, 0x03, 0xf7, 0x47,
-	0x30, 0xb5, 0x25, 0x0e, 0xd4, 0x7a, 0x2c, 0x11, 0x34, 0xb3, 0xae, 0x45, 0x72, 0xe8, 0xd6, 0xc0,
-	0x0c, 0xe2, 0x8d, 0x78, 0xe9, 0xf6, 0xa1, 0x9e, 0x1f, 0x35, 0xae, 0x43, 0x75, 0x30, 0xfa, 0xf5,
-	0xde, 0xae, 0xe0, 0x26, 0xd4, 0x7e, 0x0f, 0xc8, 0xcd, 0xfd, 0x24, 0xb0, 0x11, 0x6e, 0x80, 0xd9,
-	0x0f, 0x6e, 0x66, 0xb7, 0xf6, 0x81, 0xcc, 0x3f, 0x5c, 0x93, 0xd1, 0x60, 0x74, 0x6b, 0x1b, 0x32,
-	0x1f, 0x10, 0x72, 0x4f, 0xec, 0x6a, 0xb7, 0x03, 0xad, 0xe2, 0xb9, 0xe3, 0x1a, 0x18, 0xd3, 0xde,
-	0xd8, 0xae, 0xc8, 0x60, 0xd6, 0x1f, 0xdb, 0xa8, 0x7b, 0x5a, 0x34, 0x1a, 0x03, 0x58, 0xbd, 0xdf,
-	0xae, 0x47, 0xb7, 0x81, 0x5d, 0x91, 0x71, 0x3f, 0x18, 0x06, 0xd3, 0xc0, 0x46, 0xfe, 0x04, 0x2c,
-	0x3d, 0x07, 0x0f, 0x00, 0x06, 0xc9, 0x5a, 0x64, 0xe8, 0xfb, 0xfc, 0x49, 0xde, 0x7d, 0xdf, 0xed,
-	0xf6, 0x97, 0x4a, 0xfa, 0x03, 0x71, 0x2b, 0x1e, 0xba, 0x44, 0xfe, 0xbf, 0x08, 0xa0, 0xcf, 0xfe,
-	0x4a, 0xb8, 0x48, 0x69, 0x18, 0xe3, 0x0b, 0xa8, 0x4b, 0x14, 0xb1, 0x70, 0x81, 0x0f, 0x73, 0xb2,
-	0x32, 0xae, 0x7d, 0xb8, 0x7f, 0xf9, 0x6d, 0xf2, 0xa8, 0xe9, 0xf8, 0x27, 0xa8, 0xe9, 0xcd, 0xf9,
-	0xbe, 0x5d, 0x79, 0xd7, 0xfe, 0xb6, 0x7c, 0x28, 0x19, 0xe9, 0x12, 0xe1, 0xab, 0xfc, 0x82, 0x79,
-	0x4f, 0x5d, 0xf0, 0x1b, 0xde, 0x71, 0x99, 0x97, 0x9d, 0x73, 0x05, 0x9f, 0x41, 0x75, 0xbc, 0x4e,
-	0x96, 0x6f, 0xdb, 0xcb, 0xd0, 0xad, 0xf8, 0xaf, 0x08, 0xea, 0xb3, 0x4d, 0x26, 0xa7, 0x0b, 0xd6,
-	0x6c, 0x53, 0x16, 0xa3, 0x16, 0x79, 0x47, 0xf3, 0x10, 0xf6, 0xc1, 0x26, 0x94, 0x8b, 0x30, 0x15,
-	0xf2, 0x0e, 0xc2, 0x75, 0x42, 0xd3, 0xcf, 0x7e, 0x4c, 0xce, 0x27, 0x34, 0x66, 0x4f, 0xf4, 0x43,
-	0xb3, 0xf6, 0xf3, 0xbf, 0x52, 0xc0, 0x1f, 0x96, 0xfa, 0x3b, 0xf8, 0xf9, 0xff, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x13, 0x5a, 0x36, 0x87, 0x1e, 0x06, 0x00, 0x00,
+func (m *FileSignature) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+func (m *FileSignature) GetBlocks() []*BlockSignature {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+type BlockSignature struct {
+	Weak                 uint32   `protobuf:"varint,1,opt,name=Weak,proto3" json:"Weak,omitempty"`
+	Strong               []byte   `protobuf:"bytes,2,opt,name=Strong,proto3" json:"Strong,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
 
-// TunnelClient is the client API for Tunnel service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type TunnelClient interface {
-	InitTunnel(ctx context.Context, opts ...grpc.CallOption) (Tunnel_InitTunnelClient, error)
+func (m *BlockSignature) Reset()         { *m = BlockSignature{} }
+func (m *BlockSignature) String() string { return proto.CompactTextString(m) }
+func (*BlockSignature) ProtoMessage()    {}
+func (*BlockSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{12}
 }
 
-type tunnelClient struct {
-	cc *grpc.ClientConn
+func (m *BlockSignature) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BlockSignature.Unmarshal(m, b)
+}
+func (m *BlockSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BlockSignature.Marshal(b, m, deterministic)
+}
+func (m *BlockSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BlockSignature.Merge(m, src)
+}
+func (m *BlockSignature) XXX_Size() int {
+	return xxx_messageInfo_BlockSignature.Size(m)
+}
+func (m *BlockSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_BlockSignature.DiscardUnknown(m)
 }
 
-func NewTunnelClient(cc *grpc.ClientConn) TunnelClient {
-	return &tunnelClient{cc}
+var xxx_messageInfo_BlockSignature proto.InternalMessageInfo
+
+func (m *BlockSignature) GetWeak() uint32 {
+	if m != nil {
+		return m.Weak
+	}
+	return 0
 }
 
-func (c *tunnelClient) InitTunnel(ctx context.Context, opts ...grpc.CallOption) (Tunnel_InitTunnelClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Tunnel_serviceDesc.Streams[0], "/remote.Tunnel/InitTunnel", opts...)
-	if err != nil {
-		return nil, err
+func (m *BlockSignature) GetStrong() []byte {
+	if m != nil {
+		return m.Strong
 	}
-	x := &tunnelInitTunnelClient{stream}
-	return x, nil
+	return nil
 }
 
-type Tunnel_InitTunnelClient interface {
-	Send(*SocketDataRequest) error
-	Recv() (*SocketDataResponse, error)
-	grpc.ClientStream
+// DeltaChunk is a part of a delta encoded file. The first chunk of a file
+// contains the header (Path, MtimeUnix, Size, Mode, BlockSize) and the last
+// chunk of a file has EOF set and contains the checksum of the complete file
+type DeltaChunk struct {
+	Path                 string            `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	MtimeUnix            int64             `protobuf:"varint,2,opt,name=MtimeUnix,proto3" json:"MtimeUnix,omitempty"`
+	Size                 int64             `protobuf:"varint,3,opt,name=Size,proto3" json:"Size,omitempty"`
+	Mode                 uint32            `protobuf:"varint,4,opt,name=Mode,proto3" json:"Mode,omitempty"`
+	BlockSize            int64             `protobuf:"varint,5,opt,name=BlockSize,proto3" json:"BlockSize,omitempty"`
+	Operations           []*DeltaOperation `protobuf:"bytes,6,rep,name=Operations,proto3" json:"Operations,omitempty"`
+	EOF                  bool              `protobuf:"varint,7,opt,name=EOF,proto3" json:"EOF,omitempty"`
+	Checksum             string            `protobuf:"bytes,8,opt,name=Checksum,proto3" json:"Checksum,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
-type tunnelInitTunnelClient struct {
-	grpc.ClientStream
+func (m *DeltaChunk) Reset()         { *m = DeltaChunk{} }
+func (m *DeltaChunk) String() string { return proto.CompactTextString(m) }
+func (*DeltaChunk) ProtoMessage()    {}
+func (*DeltaChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{13}
 }
 
-func (x *tunnelInitTunnelClient) Send(m *SocketDataRequest) error {
-	return x.ClientStream.SendMsg(m)
+func (m *DeltaChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeltaChunk.Unmarshal(m, b)
+}
+func (m *DeltaChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeltaChunk.Marshal(b, m, deterministic)
+}
+func (m *DeltaChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeltaChunk.Merge(m, src)
+}
+func (m *DeltaChunk) XXX_Size() int {
+	return xxx_messageInfo_DeltaChunk.Size(m)
+}
+func (m *DeltaChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeltaChunk.DiscardUnknown(m)
 }
 
-func (x *tunnelInitTunnelClient) Recv() (*SocketDataResponse, error) {
-	m := new(SocketDataResponse)
-	if err := x.ClientStream.RecvMsg(m); err != nil {
-		return nil, err
+var xxx_messageInfo_DeltaChunk proto.InternalMessageInfo
+
+func (m *DeltaChunk) GetPath() string {
+	if m != nil {
+		return m.Path
 	}
-	return m, nil
+	return ""
 }
 
-// TunnelServer is the server API for Tunnel service.
-type TunnelServer interface {
-	InitTunnel(Tunnel_InitTunnelServer) error
+func (m *DeltaChunk) GetMtimeUnix() int64 {
+	if m != nil {
+		return m.MtimeUnix
+	}
+	return 0
 }
 
-func RegisterTunnelServer(s *grpc.Server, srv TunnelServer) {
-	s.RegisterService(&_Tunnel_serviceDesc, srv)
+func (m *DeltaChunk) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
 }
 
-func _Tunnel_InitTunnel_Handler(srv interface{}, stream grpc.ServerStream) error {
-	return srv.(TunnelServer).InitTunnel(&tunnelInitTunnelServer{stream})
+func (m *DeltaChunk) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
 }
 
-type Tunnel_InitTunnelServer interface {
-	Send(*SocketDataResponse) error
-	Recv() (*SocketDataRequest, error)
-	grpc.ServerStream
+func (m *DeltaChunk) GetBlockSize() int64 {
+	if m != nil {
+		return m.BlockSize
+	}
+	return 0
 }
 
-type tunnelInitTunnelServer struct {
-	grpc.ServerStream
+func (m *DeltaChunk) GetOperations() []*DeltaOperation {
+	if m != nil {
+		return m.Operations
+	}
+	return nil
+}
+
+func (m *DeltaChunk) GetEOF() bool {
+	if m != nil {
+		return m.EOF
+	}
+	return false
+}
+
+func (m *DeltaChunk) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
+}
+
+// DeltaOperation either copies the block with the given index from the
+// base file or, if Data is not empty, writes the literal data
+type DeltaOperation struct {
+	BlockIndex           int64    `protobuf:"varint,1,opt,name=BlockIndex,proto3" json:"BlockIndex,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=Data,proto3" json:"Data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeltaOperation) Reset()         { *m = DeltaOperation{} }
+func (m *DeltaOperation) String() string { return proto.CompactTextString(m) }
+func (*DeltaOperation) ProtoMessage()    {}
+func (*DeltaOperation) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{14}
+}
+
+func (m *DeltaOperation) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeltaOperation.Unmarshal(m, b)
+}
+func (m *DeltaOperation) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeltaOperation.Marshal(b, m, deterministic)
+}
+func (m *DeltaOperation) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeltaOperation.Merge(m, src)
+}
+func (m *DeltaOperation) XXX_Size() int {
+	return xxx_messageInfo_DeltaOperation.Size(m)
+}
+func (m *DeltaOperation) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeltaOperation.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeltaOperation proto.InternalMessageInfo
+
+func (m *DeltaOperation) GetBlockIndex() int64 {
+	if m != nil {
+		return m.BlockIndex
+	}
+	return 0
+}
+
+func (m *DeltaOperation) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+// FileChunk is a part of a large file that is uploaded on its own. The first
+// chunk contains the header (Path, MtimeUnix, Size, Mode), the following chunks
+// contain the Content at Offset and the last chunk contains the checksum of the
+// complete file
+type FileChunk struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=Path,proto3" json:"Path,omitempty"`
+	MtimeUnix            int64    `protobuf:"varint,2,opt,name=MtimeUnix,proto3" json:"MtimeUnix,omitempty"`
+	Size                 int64    `protobuf:"varint,3,opt,name=Size,proto3" json:"Size,omitempty"`
+	Mode                 uint32   `protobuf:"varint,4,opt,name=Mode,proto3" json:"Mode,omitempty"`
+	Offset               int64    `protobuf:"varint,5,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	Content              []byte   `protobuf:"bytes,6,opt,name=Content,proto3" json:"Content,omitempty"`
+	Checksum             string   `protobuf:"bytes,7,opt,name=Checksum,proto3" json:"Checksum,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FileChunk) Reset()         { *m = FileChunk{} }
+func (m *FileChunk) String() string { return proto.CompactTextString(m) }
+func (*FileChunk) ProtoMessage()    {}
+func (*FileChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{15}
+}
+
+func (m *FileChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileChunk.Unmarshal(m, b)
+}
+func (m *FileChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileChunk.Marshal(b, m, deterministic)
+}
+func (m *FileChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileChunk.Merge(m, src)
+}
+func (m *FileChunk) XXX_Size() int {
+	return xxx_messageInfo_FileChunk.Size(m)
+}
+func (m *FileChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileChunk proto.InternalMessageInfo
+
+func (m *FileChunk) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *FileChunk) GetMtimeUnix() int64 {
+	if m != nil {
+		return m.MtimeUnix
+	}
+	return 0
+}
+
+func (m *FileChunk) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *FileChunk) GetMode() uint32 {
+	if m != nil {
+		return m.Mode
+	}
+	return 0
+}
+
+func (m *FileChunk) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *FileChunk) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (m *FileChunk) GetChecksum() string {
+	if m != nil {
+		return m.Checksum
+	}
+	return ""
+}
+
+// FileOffset is the amount of bytes of a large file the server has written. The
+// first offset is sent after the header and is where the client resumes the
+// upload, ChecksumMismatch is set if the completed file was corrupted
+type FileOffset struct {
+	Offset               int64    `protobuf:"varint,1,opt,name=Offset,proto3" json:"Offset,omitempty"`
+	ChecksumMismatch     bool     `protobuf:"varint,2,opt,name=ChecksumMismatch,proto3" json:"ChecksumMismatch,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *FileOffset) Reset()         { *m = FileOffset{} }
+func (m *FileOffset) String() string { return proto.CompactTextString(m) }
+func (*FileOffset) ProtoMessage()    {}
+func (*FileOffset) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{16}
+}
+
+func (m *FileOffset) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FileOffset.Unmarshal(m, b)
+}
+func (m *FileOffset) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FileOffset.Marshal(b, m, deterministic)
+}
+func (m *FileOffset) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FileOffset.Merge(m, src)
+}
+func (m *FileOffset) XXX_Size() int {
+	return xxx_messageInfo_FileOffset.Size(m)
+}
+func (m *FileOffset) XXX_DiscardUnknown() {
+	xxx_messageInfo_FileOffset.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FileOffset proto.InternalMessageInfo
+
+func (m *FileOffset) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *FileOffset) GetChecksumMismatch() bool {
+	if m != nil {
+		return m.ChecksumMismatch
+	}
+	return false
+}
+
+// UploadResult contains the files of an upload the server couldn't apply,
+// because they were corrupted or couldn't be written. Incomplete is set if
+// the archive broke off, then Received contains the files the server got
+// before and all other files of the archive weren't applied either
+type UploadResult struct {
+	Failed               []string `protobuf:"bytes,1,rep,name=Failed,proto3" json:"Failed,omitempty"`
+	Incomplete           bool     `protobuf:"varint,2,opt,name=Incomplete,proto3" json:"Incomplete,omitempty"`
+	Received             []string `protobuf:"bytes,3,rep,name=Received,proto3" json:"Received,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UploadResult) Reset()         { *m = UploadResult{} }
+func (m *UploadResult) String() string { return proto.CompactTextString(m) }
+func (*UploadResult) ProtoMessage()    {}
+func (*UploadResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{17}
+}
+
+func (m *UploadResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UploadResult.Unmarshal(m, b)
+}
+func (m *UploadResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UploadResult.Marshal(b, m, deterministic)
+}
+func (m *UploadResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UploadResult.Merge(m, src)
+}
+func (m *UploadResult) XXX_Size() int {
+	return xxx_messageInfo_UploadResult.Size(m)
+}
+func (m *UploadResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_UploadResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UploadResult proto.InternalMessageInfo
+
+func (m *UploadResult) GetFailed() []string {
+	if m != nil {
+		return m.Failed
+	}
+	return nil
+}
+
+func (m *UploadResult) GetIncomplete() bool {
+	if m != nil {
+		return m.Incomplete
+	}
+	return false
+}
+
+func (m *UploadResult) GetReceived() []string {
+	if m != nil {
+		return m.Received
+	}
+	return nil
+}
+
+type Paths struct {
+	Paths                []string `protobuf:"bytes,1,rep,name=Paths,proto3" json:"Paths,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Paths) Reset()         { *m = Paths{} }
+func (m *Paths) String() string { return proto.CompactTextString(m) }
+func (*Paths) ProtoMessage()    {}
+func (*Paths) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{18}
+}
+
+func (m *Paths) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Paths.Unmarshal(m, b)
+}
+func (m *Paths) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Paths.Marshal(b, m, deterministic)
+}
+func (m *Paths) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Paths.Merge(m, src)
+}
+func (m *Paths) XXX_Size() int {
+	return xxx_messageInfo_Paths.Size(m)
+}
+func (m *Paths) XXX_DiscardUnknown() {
+	xxx_messageInfo_Paths.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Paths proto.InternalMessageInfo
+
+func (m *Paths) GetPaths() []string {
+	if m != nil {
+		return m.Paths
+	}
+	return nil
+}
+
+type Chunk struct {
+	Content              []byte   `protobuf:"bytes,1,opt,name=Content,proto3" json:"Content,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Chunk) Reset()         { *m = Chunk{} }
+func (m *Chunk) String() string { return proto.CompactTextString(m) }
+func (*Chunk) ProtoMessage()    {}
+func (*Chunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{19}
+}
+
+func (m *Chunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Chunk.Unmarshal(m, b)
+}
+func (m *Chunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Chunk.Marshal(b, m, deterministic)
+}
+func (m *Chunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Chunk.Merge(m, src)
+}
+func (m *Chunk) XXX_Size() int {
+	return xxx_messageInfo_Chunk.Size(m)
+}
+func (m *Chunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_Chunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Chunk proto.InternalMessageInfo
+
+func (m *Chunk) GetContent() []byte {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+type Empty struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Empty) Reset()         { *m = Empty{} }
+func (m *Empty) String() string { return proto.CompactTextString(m) }
+func (*Empty) ProtoMessage()    {}
+func (*Empty) Descriptor() ([]byte, []int) {
+	return fileDescriptor_eefc82927d57d89b, []int{20}
+}
+
+func (m *Empty) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Empty.Unmarshal(m, b)
+}
+func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+}
+func (m *Empty) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Empty.Merge(m, src)
+}
+func (m *Empty) XXX_Size() int {
+	return xxx_messageInfo_Empty.Size(m)
+}
+func (m *Empty) XXX_DiscardUnknown() {
+	xxx_messageInfo_Empty.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Empty proto.InternalMessageInfo
+
+func init() {
+	proto.RegisterEnum("remote.LogLevel", LogLevel_name, LogLevel_value)
+	proto.RegisterEnum("remote.TunnelScheme", TunnelScheme_name, TunnelScheme_value)
+	proto.RegisterEnum("remote.ChangeType", ChangeType_name, ChangeType_value)
+	proto.RegisterType((*LogMessage)(nil), "remote.LogMessage")
+	proto.RegisterType((*SocketDataRequest)(nil), "remote.SocketDataRequest")
+	proto.RegisterType((*SocketDataResponse)(nil), "remote.SocketDataResponse")
+	proto.RegisterType((*HandshakeRequest)(nil), "remote.HandshakeRequest")
+	proto.RegisterType((*HandshakeResponse)(nil), "remote.HandshakeResponse")
+	proto.RegisterType((*Watch)(nil), "remote.Watch")
+	proto.RegisterType((*ChangeAmount)(nil), "remote.ChangeAmount")
+	proto.RegisterType((*ChangeChunk)(nil), "remote.ChangeChunk")
+	proto.RegisterType((*Change)(nil), "remote.Change")
+	proto.RegisterType((*ChecksumChunk)(nil), "remote.ChecksumChunk")
+	proto.RegisterType((*Checksum)(nil), "remote.Checksum")
+	proto.RegisterType((*FileSignature)(nil), "remote.FileSignature")
+	proto.RegisterType((*BlockSignature)(nil), "remote.BlockSignature")
+	proto.RegisterType((*DeltaChunk)(nil), "remote.DeltaChunk")
+	proto.RegisterType((*DeltaOperation)(nil), "remote.DeltaOperation")
+	proto.RegisterType((*FileChunk)(nil), "remote.FileChunk")
+	proto.RegisterType((*FileOffset)(nil), "remote.FileOffset")
+	proto.RegisterType((*UploadResult)(nil), "remote.UploadResult")
+	proto.RegisterType((*Paths)(nil), "remote.Paths")
+	proto.RegisterType((*Chunk)(nil), "remote.Chunk")
+	proto.RegisterType((*Empty)(nil), "remote.Empty")
+}
+
+func init() { proto.RegisterFile("remote.proto", fileDescriptor_eefc82927d57d89b) }
+
+var fileDescriptor_eefc82927d57d89b = []byte{
+	// 1251 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xbc, 0x57, 0xdd, 0x8e, 0xdb, 0x44,
+	0x14, 0x5e, 0xaf, 0x13, 0x27, 0x3e, 0x49, 0x56, 0xee, 0xb0, 0x54, 0x69, 0x54, 0x50, 0xb0, 0xaa,
+	0x2a, 0x5a, 0xaa, 0x55, 0x1b, 0x28, 0x05, 0x84, 0x04, 0xdd, 0xc4, 0xdb, 0x46, 0xda, 0x3f, 0x4d,
+	0x76, 0xe9, 0x2d, 0xd3, 0x64, 0x9a, 0x58, 0xb1, 0x3d, 0xa9, 0x67, 0xd2, 0x1f, 0xde, 0x81, 0x3b,
+	0xde, 0x04, 0x9e, 0x87, 0x07, 0xe0, 0x8e, 0x37, 0x40, 0xf3, 0x63, 0xc7, 0xce, 0x66, 0xb5, 0x57,
+	0x70, 0x77, 0xfe, 0xe7, 0x7c, 0xdf, 0x19, 0x9f, 0x49, 0xa0, 0x99, 0xd2, 0x98, 0x09, 0x7a, 0xb8,
+	0x4c, 0x99, 0x60, 0xc8, 0xd1, 0x9a, 0x7f, 0x09, 0x70, 0xc2, 0x66, 0xa7, 0x94, 0x73, 0x32, 0xa3,
+	0xe8, 0x11, 0xd4, 0x23, 0x36, 0x3b, 0xa1, 0xef, 0x68, 0xd4, 0xb6, 0xba, 0x56, 0x6f, 0xaf, 0xef,
+	0x1d, 0x9a, 0xb4, 0x13, 0x63, 0xc7, 0x79, 0x04, 0x6a, 0x43, 0x2d, 0xd6, 0x89, 0xed, 0xdd, 0xae,
+	0xd5, 0x73, 0x71, 0xa6, 0xfa, 0xff, 0x58, 0x70, 0x67, 0xcc, 0x26, 0x0b, 0x2a, 0x86, 0x44, 0x10,
+	0x4c, 0xdf, 0xae, 0x28, 0x17, 0x08, 0x41, 0x65, 0xc9, 0x52, 0xa1, 0x2a, 0x57, 0xb1, 0x92, 0xd1,
+	0x7d, 0x70, 0x53, 0xed, 0x1e, 0x4d, 0x4d, 0x95, 0xb5, 0xa1, 0xd4, 0x8f, 0x7d, 0x6b, 0x3f, 0x8f,
+	0xc0, 0xe1, 0x93, 0x39, 0x8d, 0x69, 0xbb, 0xa2, 0x62, 0xf7, 0xb3, 0xd8, 0xcb, 0x55, 0x92, 0xd0,
+	0x68, 0xac, 0x7c, 0xd8, 0xc4, 0xc8, 0x6e, 0xa6, 0x44, 0x90, 0x76, 0xb5, 0x6b, 0xf5, 0x9a, 0x58,
+	0xc9, 0xa8, 0x0b, 0x0d, 0x3e, 0x67, 0xab, 0x68, 0x3a, 0x88, 0x18, 0xa7, 0x6d, 0xa7, 0x6b, 0xf5,
+	0xea, 0xb8, 0x68, 0x92, 0x98, 0xdf, 0xb0, 0xf4, 0x3d, 0x49, 0xa7, 0xed, 0x9a, 0xf2, 0x66, 0xaa,
+	0xff, 0xa7, 0x05, 0xa8, 0x88, 0x99, 0x2f, 0x59, 0xc2, 0x29, 0xba, 0x0b, 0xce, 0x9c, 0xf0, 0x20,
+	0x4d, 0x15, 0xec, 0x3a, 0x36, 0x1a, 0xea, 0x03, 0x44, 0x39, 0xf1, 0x0a, 0x79, 0xa3, 0x8f, 0x0a,
+	0xe0, 0x8c, 0x07, 0x17, 0xa2, 0xca, 0x64, 0xd9, 0x9b, 0x64, 0x65, 0x80, 0x2a, 0x37, 0x03, 0xaa,
+	0x5e, 0x03, 0xe4, 0xff, 0x02, 0xde, 0x4b, 0x92, 0x4c, 0xf9, 0x9c, 0x2c, 0x68, 0x36, 0xa8, 0x2e,
+	0x34, 0x06, 0x2c, 0x5e, 0xa6, 0x94, 0xf3, 0x90, 0x25, 0x6d, 0xab, 0x6b, 0xf7, 0x5c, 0x5c, 0x34,
+	0xa1, 0x03, 0xf0, 0x0a, 0xaa, 0x1e, 0xd0, 0xae, 0x1a, 0xeb, 0x35, 0xbb, 0xff, 0x14, 0xee, 0x14,
+	0x4e, 0x30, 0xb4, 0x5c, 0x3b, 0xc2, 0xda, 0x38, 0xc2, 0x7f, 0x0a, 0xd5, 0x57, 0x44, 0x4c, 0xe6,
+	0x12, 0xd7, 0x05, 0x11, 0x73, 0x13, 0xa3, 0x64, 0x39, 0x86, 0xe0, 0xc3, 0x24, 0x5a, 0x4d, 0x25,
+	0x75, 0xb2, 0xbb, 0x4c, 0xf5, 0x1f, 0x42, 0x73, 0x30, 0x27, 0xc9, 0x8c, 0x3e, 0x8f, 0xd9, 0x2a,
+	0x11, 0x92, 0x7f, 0x2d, 0xa9, 0x7c, 0x1b, 0x1b, 0xcd, 0x7f, 0x06, 0x0d, 0x1d, 0x37, 0x98, 0xaf,
+	0x92, 0x05, 0xea, 0x41, 0x6d, 0xa2, 0x54, 0xae, 0xe0, 0x36, 0xfa, 0x7b, 0xd9, 0x2c, 0x74, 0x14,
+	0xce, 0xdc, 0xfe, 0x5f, 0x16, 0x38, 0xda, 0x26, 0x67, 0xa8, 0xa5, 0xcb, 0x8f, 0x4b, 0x6a, 0x3e,
+	0x18, 0x54, 0xce, 0x93, 0x1e, 0x5c, 0x88, 0xca, 0xd1, 0xec, 0x16, 0xd0, 0xdc, 0x07, 0xf7, 0x54,
+	0x84, 0x31, 0xbd, 0x4a, 0xc2, 0x0f, 0x6a, 0xae, 0x36, 0x5e, 0x1b, 0xd0, 0x03, 0x68, 0xe5, 0xca,
+	0x19, 0x49, 0x98, 0x1a, 0xb0, 0x8d, 0xcb, 0x46, 0x59, 0x77, 0x1c, 0xfe, 0xaa, 0x47, 0x6c, 0x63,
+	0x25, 0xa3, 0x7d, 0xa8, 0x8e, 0xf8, 0x30, 0x4c, 0xcd, 0x45, 0xd6, 0x0a, 0xfa, 0x1c, 0xe0, 0x24,
+	0x4c, 0x16, 0x97, 0x24, 0x9d, 0x51, 0xa1, 0x6e, 0xb1, 0x8b, 0x0b, 0x16, 0xff, 0x47, 0x68, 0x0d,
+	0xe6, 0x74, 0xb2, 0xe0, 0xab, 0x58, 0x73, 0x73, 0x08, 0xee, 0xc4, 0x18, 0x32, 0x76, 0xbc, 0x35,
+	0x4a, 0xed, 0xc0, 0xeb, 0x10, 0xff, 0x7b, 0xa8, 0x67, 0xe6, 0xad, 0xc3, 0xeb, 0xac, 0xfd, 0x86,
+	0x86, 0x5c, 0xf7, 0xdf, 0x42, 0xeb, 0x38, 0x8c, 0xe8, 0x38, 0x9c, 0x25, 0x44, 0xac, 0x52, 0xba,
+	0xb5, 0xc0, 0x7d, 0x70, 0x8f, 0x22, 0x36, 0x59, 0x28, 0xc0, 0xbb, 0x9a, 0xaf, 0xdc, 0x80, 0x0e,
+	0xc1, 0x51, 0x0a, 0x6f, 0xdb, 0xaa, 0xd7, 0xbb, 0x59, 0xaf, 0x26, 0xc4, 0x54, 0xc6, 0x26, 0xca,
+	0xff, 0x01, 0xf6, 0xca, 0x1e, 0x79, 0xe6, 0x2b, 0x4a, 0x16, 0xea, 0xcc, 0x16, 0x56, 0xb2, 0xbc,
+	0x47, 0x63, 0x91, 0xb2, 0x64, 0xa6, 0x0e, 0x6c, 0x62, 0xa3, 0xf9, 0x7f, 0x5b, 0x00, 0x43, 0x1a,
+	0x09, 0xa2, 0xb9, 0xba, 0xa1, 0xdd, 0xf5, 0x78, 0x77, 0x37, 0xc7, 0x9b, 0x0d, 0xce, 0x2e, 0x0c,
+	0x0e, 0x41, 0xe5, 0x94, 0x4d, 0xf5, 0x1e, 0x6b, 0x61, 0x25, 0x97, 0x41, 0x57, 0x37, 0x41, 0x7f,
+	0x03, 0x70, 0xbe, 0xa4, 0x29, 0x11, 0x21, 0x4b, 0x78, 0xdb, 0x29, 0x03, 0x57, 0xfd, 0xe5, 0x6e,
+	0x5c, 0x88, 0x44, 0x1e, 0xd8, 0xc1, 0xf9, 0xb1, 0xd9, 0x65, 0x52, 0x2c, 0x4d, 0xa7, 0xbe, 0x31,
+	0x9d, 0x21, 0xec, 0x95, 0x6b, 0xc9, 0xcb, 0xa4, 0x9a, 0x18, 0x25, 0x53, 0xfa, 0xc1, 0x7c, 0x62,
+	0x05, 0x8b, 0x44, 0x22, 0xd7, 0xa1, 0x21, 0x4d, 0xc9, 0xfe, 0x1f, 0x16, 0xb8, 0x72, 0xc8, 0xff,
+	0x35, 0x63, 0x77, 0xc1, 0x39, 0x7f, 0xf3, 0x86, 0x53, 0x61, 0xe8, 0x32, 0x9a, 0x5c, 0x1e, 0x03,
+	0x96, 0x08, 0x9a, 0x08, 0xf5, 0x61, 0x34, 0x71, 0xa6, 0x96, 0xb0, 0xd7, 0x36, 0xb0, 0x5f, 0x00,
+	0xc8, 0xa6, 0x4d, 0x8d, 0x75, 0x6d, 0xab, 0x54, 0x5b, 0x2e, 0x46, 0x93, 0x71, 0x1a, 0xf2, 0x58,
+	0x2e, 0x30, 0x05, 0xa0, 0x8e, 0xaf, 0xd9, 0xfd, 0xd7, 0xd0, 0xbc, 0x5a, 0x46, 0x8c, 0x4c, 0x31,
+	0xe5, 0xab, 0x48, 0xd5, 0x3c, 0x26, 0x61, 0x44, 0xa7, 0x66, 0xe3, 0x1a, 0x4d, 0x72, 0x3c, 0x4a,
+	0x26, 0x2c, 0x5e, 0x46, 0x54, 0x50, 0x53, 0xad, 0x60, 0x91, 0x5d, 0x63, 0x3a, 0xa1, 0xe1, 0x3b,
+	0x3a, 0x55, 0x57, 0xde, 0xc5, 0xb9, 0xee, 0x7f, 0x06, 0x55, 0xc9, 0x28, 0x47, 0xfb, 0x46, 0x30,
+	0xb5, 0xb5, 0xe2, 0x7f, 0x01, 0x55, 0x3d, 0x85, 0x02, 0x27, 0x56, 0x89, 0x13, 0xbf, 0x06, 0xd5,
+	0x20, 0x5e, 0x8a, 0x8f, 0x07, 0x43, 0xa8, 0x67, 0x8f, 0x2e, 0xaa, 0x43, 0x65, 0x74, 0x76, 0x7c,
+	0xee, 0xed, 0xa0, 0x06, 0xd4, 0x7e, 0x0e, 0xf0, 0xd1, 0xf9, 0x38, 0xf0, 0x2c, 0xe4, 0x42, 0x75,
+	0x18, 0x1c, 0x5d, 0xbd, 0xf0, 0x76, 0xa5, 0xfd, 0xd5, 0x73, 0x7c, 0x36, 0x3a, 0x7b, 0xe1, 0xd9,
+	0xd2, 0x1e, 0x60, 0x7c, 0x8e, 0xbd, 0xca, 0x41, 0x17, 0x9a, 0xc5, 0xe7, 0x18, 0xd5, 0xc0, 0xbe,
+	0x1c, 0x5c, 0x78, 0x3b, 0x52, 0xb8, 0x1a, 0x5e, 0x78, 0xd6, 0xc1, 0x83, 0xe2, 0x56, 0x45, 0x00,
+	0xce, 0xe0, 0xe5, 0xf3, 0xb3, 0x17, 0x81, 0xb7, 0x23, 0xe5, 0x61, 0x70, 0x12, 0x5c, 0x06, 0x9e,
+	0xd5, 0x1f, 0x83, 0xa3, 0xeb, 0xa0, 0x91, 0xa4, 0x27, 0x14, 0x46, 0xbb, 0x97, 0x5d, 0xfa, 0x6b,
+	0xbf, 0x3f, 0x3a, 0x9d, 0x6d, 0x2e, 0xfd, 0x1e, 0xf9, 0x3b, 0x3d, 0xeb, 0xb1, 0xd5, 0xff, 0xcd,
+	0x06, 0x18, 0xb2, 0xf7, 0x09, 0x17, 0x29, 0x25, 0x31, 0x3a, 0x84, 0xba, 0xd4, 0xe4, 0x88, 0x50,
+	0x2b, 0x4b, 0x56, 0xc4, 0x75, 0x5a, 0xeb, 0x05, 0xb8, 0x4a, 0x16, 0x3a, 0x1d, 0x3d, 0x81, 0x9a,
+	0xee, 0x9c, 0xaf, 0xc3, 0x15, 0x77, 0x9d, 0x4f, 0xca, 0xaf, 0x82, 0x49, 0x7a, 0x6c, 0xa1, 0xa7,
+	0xd9, 0x73, 0xc5, 0x07, 0xea, 0xb9, 0xda, 0xc8, 0xdb, 0x2f, 0xe7, 0x99, 0xb7, 0x6b, 0x07, 0x3d,
+	0x03, 0x37, 0xbb, 0x4e, 0x7c, 0xb3, 0xb5, 0x4f, 0x37, 0x77, 0x73, 0xb1, 0xc5, 0x9f, 0xa0, 0x95,
+	0x41, 0x52, 0x5f, 0x32, 0xca, 0xa3, 0x4b, 0x6b, 0xb7, 0x83, 0x4a, 0xbb, 0xa3, 0x58, 0xe1, 0x08,
+	0xdc, 0xfc, 0x39, 0x47, 0xed, 0x2c, 0x6c, 0xf3, 0x37, 0x44, 0xe7, 0xde, 0x16, 0x4f, 0xc6, 0x35,
+	0x7a, 0x08, 0x95, 0x8b, 0x30, 0x99, 0x6d, 0xa2, 0x2d, 0xab, 0xfe, 0x4e, 0xff, 0xf7, 0x0a, 0xd4,
+	0xaf, 0x96, 0x66, 0x1a, 0x4f, 0xc0, 0xd1, 0x9f, 0x0b, 0x2a, 0x93, 0xbf, 0x26, 0xa9, 0xf8, 0x35,
+	0xc9, 0x6e, 0xd1, 0xb7, 0x00, 0x39, 0xa4, 0x9b, 0x79, 0x2a, 0x21, 0x37, 0x28, 0xbf, 0x86, 0x86,
+	0xae, 0xa6, 0x59, 0xda, 0x42, 0x47, 0xa7, 0x5c, 0x4e, 0x9d, 0xf7, 0x1d, 0x80, 0xce, 0x92, 0x25,
+	0xd1, 0x9d, 0xe2, 0x01, 0x3a, 0x07, 0x15, 0x4d, 0x7a, 0x65, 0xe4, 0x77, 0xa7, 0x3a, 0x16, 0x44,
+	0x5c, 0xeb, 0x72, 0xfb, 0xcd, 0x51, 0x29, 0x7d, 0xf0, 0x30, 0xe5, 0x82, 0xa4, 0x42, 0x7e, 0xab,
+	0x24, 0x4c, 0x68, 0x7a, 0x1b, 0xa3, 0xe8, 0x00, 0x1c, 0x4c, 0x63, 0xf6, 0x8e, 0xde, 0x78, 0xa1,
+	0x4d, 0x64, 0xcf, 0x42, 0x5f, 0x82, 0x7b, 0x24, 0x17, 0xd5, 0x90, 0x25, 0xf4, 0xd6, 0xc2, 0xff,
+	0xe3, 0xb5, 0x78, 0xed, 0xa8, 0xff, 0x30, 0x5f, 0xfd, 0x3b, 0x00, 0x86, 0xc1, 0xe5, 0xf1, 0xd3,
+	0x0c, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// TunnelClient is the client API for Tunnel service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type TunnelClient interface {
+	InitTunnel(ctx context.Context, opts ...grpc.CallOption) (Tunnel_InitTunnelClient, error)
+}
+
+type tunnelClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewTunnelClient(cc *grpc.ClientConn) TunnelClient {
+	return &tunnelClient{cc}
+}
+
+func (c *tunnelClient) InitTunnel(ctx context.Context, opts ...grpc.CallOption) (Tunnel_InitTunnelClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Tunnel_serviceDesc.Streams[0], "/remote.Tunnel/InitTunnel", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &tunnelInitTunnelClient{stream}
+	return x, nil
+}
+
+type Tunnel_InitTunnelClient interface {
+	Send(*SocketDataRequest) error
+	Recv() (*SocketDataResponse, error)
+	grpc.ClientStream
+}
+
+type tunnelInitTunnelClient struct {
+	grpc.ClientStream
+}
+
+func (x *tunnelInitTunnelClient) Send(m *SocketDataRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *tunnelInitTunnelClient) Recv() (*SocketDataResponse, error) {
+	m := new(SocketDataResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TunnelServer is the server API for Tunnel service.
+type TunnelServer interface {
+	InitTunnel(Tunnel_InitTunnelServer) error
+}
+
+func RegisterTunnelServer(s *grpc.Server, srv TunnelServer) {
+	s.RegisterService(&_Tunnel_serviceDesc, srv)
+}
+
+func _Tunnel_InitTunnel_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(TunnelServer).InitTunnel(&tunnelInitTunnelServer{stream})
+}
+
+type Tunnel_InitTunnelServer interface {
+	Send(*SocketDataResponse) error
+	Recv() (*SocketDataRequest, error)
+	grpc.ServerStream
+}
+
+type tunnelInitTunnelServer struct {
+	grpc.ServerStream
 }
 
 func (x *tunnelInitTunnelServer) Send(m *SocketDataResponse) error {
@@ -795,6 +1485,9 @@ type DownstreamClient interface {
 	Download(ctx context.Context, opts ...grpc.CallOption) (Downstream_DownloadClient, error)
 	Changes(ctx context.Context, in *Empty, opts ...grpc.CallOption) (Downstream_ChangesClient, error)
 	ChangesCount(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ChangeAmount, error)
+	Checksums(ctx context.Context, opts ...grpc.CallOption) (Downstream_ChecksumsClient, error)
+	DownloadDelta(ctx context.Context, opts ...grpc.CallOption) (Downstream_DownloadDeltaClient, error)
+	Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -878,6 +1571,77 @@ func (c *downstreamClient) ChangesCount(ctx context.Context, in *Empty, opts ...
 	return out, nil
 }
 
+func (c *downstreamClient) Checksums(ctx context.Context, opts ...grpc.CallOption) (Downstream_ChecksumsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Downstream_serviceDesc.Streams[2], "/remote.Downstream/Checksums", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &downstreamChecksumsClient{stream}
+	return x, nil
+}
+
+type Downstream_ChecksumsClient interface {
+	Send(*Paths) error
+	Recv() (*ChecksumChunk, error)
+	grpc.ClientStream
+}
+
+type downstreamChecksumsClient struct {
+	grpc.ClientStream
+}
+
+func (x *downstreamChecksumsClient) Send(m *Paths) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *downstreamChecksumsClient) Recv() (*ChecksumChunk, error) {
+	m := new(ChecksumChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *downstreamClient) DownloadDelta(ctx context.Context, opts ...grpc.CallOption) (Downstream_DownloadDeltaClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Downstream_serviceDesc.Streams[3], "/remote.Downstream/DownloadDelta", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &downstreamDownloadDeltaClient{stream}
+	return x, nil
+}
+
+type Downstream_DownloadDeltaClient interface {
+	Send(*FileSignature) error
+	Recv() (*DeltaChunk, error)
+	grpc.ClientStream
+}
+
+type downstreamDownloadDeltaClient struct {
+	grpc.ClientStream
+}
+
+func (x *downstreamDownloadDeltaClient) Send(m *FileSignature) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *downstreamDownloadDeltaClient) Recv() (*DeltaChunk, error) {
+	m := new(DeltaChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *downstreamClient) Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error) {
+	out := new(HandshakeResponse)
+	err := c.cc.Invoke(ctx, "/remote.Downstream/Handshake", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *downstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/remote.Downstream/Ping", in, out, opts...)
@@ -892,6 +1656,9 @@ type DownstreamServer interface {
 	Download(Downstream_DownloadServer) error
 	Changes(*Empty, Downstream_ChangesServer) error
 	ChangesCount(context.Context, *Empty) (*ChangeAmount, error)
+	Checksums(Downstream_ChecksumsServer) error
+	DownloadDelta(Downstream_DownloadDeltaServer) error
+	Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
 	Ping(context.Context, *Empty) (*Empty, error)
 }
 
@@ -930,36 +1697,106 @@ func _Downstream_Changes_Handler(srv interface{}, stream grpc.ServerStream) erro
 	if err := stream.RecvMsg(m); err != nil {
 		return err
 	}
-	return srv.(DownstreamServer).Changes(m, &downstreamChangesServer{stream})
+	return srv.(DownstreamServer).Changes(m, &downstreamChangesServer{stream})
+}
+
+type Downstream_ChangesServer interface {
+	Send(*ChangeChunk) error
+	grpc.ServerStream
+}
+
+type downstreamChangesServer struct {
+	grpc.ServerStream
+}
+
+func (x *downstreamChangesServer) Send(m *ChangeChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Downstream_ChangesCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DownstreamServer).ChangesCount(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Downstream/ChangesCount",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DownstreamServer).ChangesCount(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Downstream_Checksums_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DownstreamServer).Checksums(&downstreamChecksumsServer{stream})
+}
+
+type Downstream_ChecksumsServer interface {
+	Send(*ChecksumChunk) error
+	Recv() (*Paths, error)
+	grpc.ServerStream
+}
+
+type downstreamChecksumsServer struct {
+	grpc.ServerStream
+}
+
+func (x *downstreamChecksumsServer) Send(m *ChecksumChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *downstreamChecksumsServer) Recv() (*Paths, error) {
+	m := new(Paths)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
 }
 
-type Downstream_ChangesServer interface {
-	Send(*ChangeChunk) error
+func _Downstream_DownloadDelta_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(DownstreamServer).DownloadDelta(&downstreamDownloadDeltaServer{stream})
+}
+
+type Downstream_DownloadDeltaServer interface {
+	Send(*DeltaChunk) error
+	Recv() (*FileSignature, error)
 	grpc.ServerStream
 }
 
-type downstreamChangesServer struct {
+type downstreamDownloadDeltaServer struct {
 	grpc.ServerStream
 }
 
-func (x *downstreamChangesServer) Send(m *ChangeChunk) error {
+func (x *downstreamDownloadDeltaServer) Send(m *DeltaChunk) error {
 	return x.ServerStream.SendMsg(m)
 }
 
-func _Downstream_ChangesCount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
+func (x *downstreamDownloadDeltaServer) Recv() (*FileSignature, error) {
+	m := new(FileSignature)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Downstream_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandshakeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(DownstreamServer).ChangesCount(ctx, in)
+		return srv.(DownstreamServer).Handshake(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: "/remote.Downstream/ChangesCount",
+		FullMethod: "/remote.Downstream/Handshake",
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(DownstreamServer).ChangesCount(ctx, req.(*Empty))
+		return srv.(DownstreamServer).Handshake(ctx, req.(*HandshakeRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -990,6 +1827,10 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ChangesCount",
 			Handler:    _Downstream_ChangesCount_Handler,
 		},
+		{
+			MethodName: "Handshake",
+			Handler:    _Downstream_Handshake_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _Downstream_Ping_Handler,
@@ -1007,6 +1848,18 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Downstream_Changes_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "Checksums",
+			Handler:       _Downstream_Checksums_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadDelta",
+			Handler:       _Downstream_DownloadDelta_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "remote.proto",
 }
@@ -1016,8 +1869,14 @@ var _Downstream_serviceDesc = grpc.ServiceDesc{
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
 type UpstreamClient interface {
 	Upload(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadClient, error)
+	Signatures(ctx context.Context, opts ...grpc.CallOption) (Upstream_SignaturesClient, error)
+	UploadDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadDeltaClient, error)
+	UploadFile(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadFileClient, error)
+	Stats(ctx context.Context, opts ...grpc.CallOption) (Upstream_StatsClient, error)
 	RestartContainer(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 	Remove(ctx context.Context, opts ...grpc.CallOption) (Upstream_RemoveClient, error)
+	BatchDone(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
+	Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error)
 	Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error)
 }
 
@@ -1040,7 +1899,7 @@ func (c *upstreamClient) Upload(ctx context.Context, opts ...grpc.CallOption) (U
 
 type Upstream_UploadClient interface {
 	Send(*Chunk) error
-	CloseAndRecv() (*Empty, error)
+	CloseAndRecv() (*UploadResult, error)
 	grpc.ClientStream
 }
 
@@ -1052,11 +1911,138 @@ func (x *upstreamUploadClient) Send(m *Chunk) error {
 	return x.ClientStream.SendMsg(m)
 }
 
-func (x *upstreamUploadClient) CloseAndRecv() (*Empty, error) {
+func (x *upstreamUploadClient) CloseAndRecv() (*UploadResult, error) {
 	if err := x.ClientStream.CloseSend(); err != nil {
 		return nil, err
 	}
-	m := new(Empty)
+	m := new(UploadResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) Signatures(ctx context.Context, opts ...grpc.CallOption) (Upstream_SignaturesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[1], "/remote.Upstream/Signatures", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamSignaturesClient{stream}
+	return x, nil
+}
+
+type Upstream_SignaturesClient interface {
+	Send(*Paths) error
+	Recv() (*FileSignature, error)
+	grpc.ClientStream
+}
+
+type upstreamSignaturesClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamSignaturesClient) Send(m *Paths) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamSignaturesClient) Recv() (*FileSignature, error) {
+	m := new(FileSignature)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) UploadDelta(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadDeltaClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[2], "/remote.Upstream/UploadDelta", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamUploadDeltaClient{stream}
+	return x, nil
+}
+
+type Upstream_UploadDeltaClient interface {
+	Send(*DeltaChunk) error
+	CloseAndRecv() (*Paths, error)
+	grpc.ClientStream
+}
+
+type upstreamUploadDeltaClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamUploadDeltaClient) Send(m *DeltaChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamUploadDeltaClient) CloseAndRecv() (*Paths, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Paths)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) UploadFile(ctx context.Context, opts ...grpc.CallOption) (Upstream_UploadFileClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[3], "/remote.Upstream/UploadFile", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamUploadFileClient{stream}
+	return x, nil
+}
+
+type Upstream_UploadFileClient interface {
+	Send(*FileChunk) error
+	Recv() (*FileOffset, error)
+	grpc.ClientStream
+}
+
+type upstreamUploadFileClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamUploadFileClient) Send(m *FileChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamUploadFileClient) Recv() (*FileOffset, error) {
+	m := new(FileOffset)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *upstreamClient) Stats(ctx context.Context, opts ...grpc.CallOption) (Upstream_StatsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[4], "/remote.Upstream/Stats", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &upstreamStatsClient{stream}
+	return x, nil
+}
+
+type Upstream_StatsClient interface {
+	Send(*Paths) error
+	Recv() (*ChangeChunk, error)
+	grpc.ClientStream
+}
+
+type upstreamStatsClient struct {
+	grpc.ClientStream
+}
+
+func (x *upstreamStatsClient) Send(m *Paths) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *upstreamStatsClient) Recv() (*ChangeChunk, error) {
+	m := new(ChangeChunk)
 	if err := x.ClientStream.RecvMsg(m); err != nil {
 		return nil, err
 	}
@@ -1073,7 +2059,7 @@ func (c *upstreamClient) RestartContainer(ctx context.Context, in *Empty, opts .
 }
 
 func (c *upstreamClient) Remove(ctx context.Context, opts ...grpc.CallOption) (Upstream_RemoveClient, error) {
-	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[1], "/remote.Upstream/Remove", opts...)
+	stream, err := c.cc.NewStream(ctx, &_Upstream_serviceDesc.Streams[5], "/remote.Upstream/Remove", opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -1106,6 +2092,24 @@ func (x *upstreamRemoveClient) CloseAndRecv() (*Empty, error) {
 	return m, nil
 }
 
+func (c *upstreamClient) BatchDone(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/remote.Upstream/BatchDone", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *upstreamClient) Handshake(ctx context.Context, in *HandshakeRequest, opts ...grpc.CallOption) (*HandshakeResponse, error) {
+	out := new(HandshakeResponse)
+	err := c.cc.Invoke(ctx, "/remote.Upstream/Handshake", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *upstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Empty, error) {
 	out := new(Empty)
 	err := c.cc.Invoke(ctx, "/remote.Upstream/Ping", in, out, opts...)
@@ -1118,8 +2122,14 @@ func (c *upstreamClient) Ping(ctx context.Context, in *Empty, opts ...grpc.CallO
 // UpstreamServer is the server API for Upstream service.
 type UpstreamServer interface {
 	Upload(Upstream_UploadServer) error
+	Signatures(Upstream_SignaturesServer) error
+	UploadDelta(Upstream_UploadDeltaServer) error
+	UploadFile(Upstream_UploadFileServer) error
+	Stats(Upstream_StatsServer) error
 	RestartContainer(context.Context, *Empty) (*Empty, error)
 	Remove(Upstream_RemoveServer) error
+	BatchDone(context.Context, *Empty) (*Empty, error)
+	Handshake(context.Context, *HandshakeRequest) (*HandshakeResponse, error)
 	Ping(context.Context, *Empty) (*Empty, error)
 }
 
@@ -1132,7 +2142,7 @@ func _Upstream_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
 }
 
 type Upstream_UploadServer interface {
-	SendAndClose(*Empty) error
+	SendAndClose(*UploadResult) error
 	Recv() (*Chunk, error)
 	grpc.ServerStream
 }
@@ -1141,7 +2151,7 @@ type upstreamUploadServer struct {
 	grpc.ServerStream
 }
 
-func (x *upstreamUploadServer) SendAndClose(m *Empty) error {
+func (x *upstreamUploadServer) SendAndClose(m *UploadResult) error {
 	return x.ServerStream.SendMsg(m)
 }
 
@@ -1153,6 +2163,110 @@ func (x *upstreamUploadServer) Recv() (*Chunk, error) {
 	return m, nil
 }
 
+func _Upstream_Signatures_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).Signatures(&upstreamSignaturesServer{stream})
+}
+
+type Upstream_SignaturesServer interface {
+	Send(*FileSignature) error
+	Recv() (*Paths, error)
+	grpc.ServerStream
+}
+
+type upstreamSignaturesServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamSignaturesServer) Send(m *FileSignature) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamSignaturesServer) Recv() (*Paths, error) {
+	m := new(Paths)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Upstream_UploadDelta_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).UploadDelta(&upstreamUploadDeltaServer{stream})
+}
+
+type Upstream_UploadDeltaServer interface {
+	SendAndClose(*Paths) error
+	Recv() (*DeltaChunk, error)
+	grpc.ServerStream
+}
+
+type upstreamUploadDeltaServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamUploadDeltaServer) SendAndClose(m *Paths) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamUploadDeltaServer) Recv() (*DeltaChunk, error) {
+	m := new(DeltaChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Upstream_UploadFile_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).UploadFile(&upstreamUploadFileServer{stream})
+}
+
+type Upstream_UploadFileServer interface {
+	Send(*FileOffset) error
+	Recv() (*FileChunk, error)
+	grpc.ServerStream
+}
+
+type upstreamUploadFileServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamUploadFileServer) Send(m *FileOffset) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamUploadFileServer) Recv() (*FileChunk, error) {
+	m := new(FileChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _Upstream_Stats_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(UpstreamServer).Stats(&upstreamStatsServer{stream})
+}
+
+type Upstream_StatsServer interface {
+	Send(*ChangeChunk) error
+	Recv() (*Paths, error)
+	grpc.ServerStream
+}
+
+type upstreamStatsServer struct {
+	grpc.ServerStream
+}
+
+func (x *upstreamStatsServer) Send(m *ChangeChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *upstreamStatsServer) Recv() (*Paths, error) {
+	m := new(Paths)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 func _Upstream_RestartContainer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -1197,6 +2311,42 @@ func (x *upstreamRemoveServer) Recv() (*Paths, error) {
 	return m, nil
 }
 
+func _Upstream_BatchDone_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpstreamServer).BatchDone(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Upstream/BatchDone",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpstreamServer).BatchDone(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Upstream_Handshake_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HandshakeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UpstreamServer).Handshake(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/remote.Upstream/Handshake",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UpstreamServer).Handshake(ctx, req.(*HandshakeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Upstream_Ping_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(Empty)
 	if err := dec(in); err != nil {
@@ -1223,6 +2373,14 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 			MethodName: "RestartContainer",
 			Handler:    _Upstream_RestartContainer_Handler,
 		},
+		{
+			MethodName: "BatchDone",
+			Handler:    _Upstream_BatchDone_Handler,
+		},
+		{
+			MethodName: "Handshake",
+			Handler:    _Upstream_Handshake_Handler,
+		},
 		{
 			MethodName: "Ping",
 			Handler:    _Upstream_Ping_Handler,
@@ -1234,6 +2392,29 @@ var _Upstream_serviceDesc = grpc.ServiceDesc{
 			Handler:       _Upstream_Upload_Handler,
 			ClientStreams: true,
 		},
+		{
+			StreamName:    "Signatures",
+			Handler:       _Upstream_Signatures_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadDelta",
+			Handler:       _Upstream_UploadDelta_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadFile",
+			Handler:       _Upstream_UploadFile_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Stats",
+			Handler:       _Upstream_Stats_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 		{
 			StreamName:    "Remove",
 			Handler:       _Upstream_Remove_Handler,
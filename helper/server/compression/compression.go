@@ -0,0 +1,338 @@
+package compression
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Codec is the compression algorithm that is used for the archives of the sync
+type Codec string
+
+const (
+	// Legacy compresses the complete archive as a single gzip stream. It is used if the other side
+	// does not support the handshake
+	Legacy Codec = ""
+	None   Codec = "none"
+	Gzip   Codec = "gzip"
+	Zstd   Codec = "zstd"
+)
+
+// Supported holds all codecs that can be negotiated
+var Supported = []Codec{None, Gzip, Zstd}
+
+const (
+	frameRaw byte = iota
+	frameGzip
+	frameZstd
+)
+
+// frameSize is the amount of uncompressed bytes that are compressed into a single frame
+const frameSize = 256 * 1024
+
+// maxFrameSize is the maximum size of a frame that is accepted by the reader
+const maxFrameSize = 4 * frameSize
+
+// alreadyCompressed are the extensions of file types that do not gain anything from another compression
+var alreadyCompressed = map[string]bool{
+	".7z": true, ".apk": true, ".avi": true, ".br": true, ".bz2": true, ".gif": true, ".gz": true, ".jar": true,
+	".jpeg": true, ".jpg": true, ".lz4": true, ".mkv": true, ".mov": true, ".mp3": true, ".mp4": true, ".ogg": true,
+	".png": true, ".rar": true, ".tgz": true, ".war": true, ".webm": true, ".webp": true, ".whl": true, ".woff": true,
+	".woff2": true, ".xz": true, ".zip": true, ".zst": true,
+}
+
+// Valid checks if the codec is known
+func Valid(codec Codec) bool {
+	for _, c := range Supported {
+		if c == codec {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Negotiate returns the first requested codec that is supported or the legacy codec if there is none
+func Negotiate(requested []string) Codec {
+	for _, codec := range requested {
+		if Valid(Codec(codec)) {
+			return Codec(codec)
+		}
+	}
+
+	return Legacy
+}
+
+// IsCompressed checks if the file type of the given path is already compressed
+func IsCompressed(name string) bool {
+	return alreadyCompressed[strings.ToLower(path.Ext(name))]
+}
+
+// Writer compresses the written data with the negotiated codec. Except for the legacy codec,
+// the data is written in frames that are either compressed or raw, which allows to skip the
+// compression for data that is already compressed
+type Writer struct {
+	writer io.Writer
+	codec  Codec
+	skip   bool
+	buffer []byte
+
+	legacy *gzip.Writer
+	gzip   *gzip.Writer
+	zstd   *zstd.Encoder
+}
+
+// NewWriter creates a new writer for the codec. The level is codec specific, gzip uses levels
+// 1 - 9 and zstd uses levels 1 - 22. Zero selects the default level of the codec
+func NewWriter(writer io.Writer, codec Codec, level int) (*Writer, error) {
+	w := &Writer{
+		writer: writer,
+		codec:  codec,
+	}
+
+	var err error
+	switch codec {
+	case Legacy:
+		w.legacy = gzip.NewWriter(writer)
+	case None:
+	case Gzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+
+		w.gzip, err = gzip.NewWriterLevel(ioutil.Discard, level)
+		if err != nil {
+			return nil, errors.Wrap(err, "create gzip writer")
+		}
+	case Zstd:
+		options := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != 0 {
+			options = append(options, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+
+		w.zstd, err = zstd.NewWriter(nil, options...)
+		if err != nil {
+			return nil, errors.Wrap(err, "create zstd writer")
+		}
+	default:
+		return nil, errors.Errorf("unknown compression %s", codec)
+	}
+
+	return w, nil
+}
+
+// Write implements io.Writer
+func (w *Writer) Write(p []byte) (int, error) {
+	if w.legacy != nil {
+		return w.legacy.Write(p)
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := frameSize - len(w.buffer)
+		if n > len(p) {
+			n = len(p)
+		}
+
+		w.buffer = append(w.buffer, p[:n]...)
+		written += n
+		p = p[n:]
+		if len(w.buffer) >= frameSize {
+			err := w.flush()
+			if err != nil {
+				return written, err
+			}
+		}
+	}
+
+	return written, nil
+}
+
+// SkipCompression writes the following data uncompressed if skip is true. This has no effect
+// for the legacy codec
+func (w *Writer) SkipCompression(skip bool) error {
+	if w == nil || w.skip == skip {
+		return nil
+	}
+
+	err := w.flush()
+	if err != nil {
+		return err
+	}
+
+	w.skip = skip
+	return nil
+}
+
+// Close writes the remaining data, it does not close the underlying writer
+func (w *Writer) Close() error {
+	if w.legacy != nil {
+		return w.legacy.Close()
+	}
+
+	err := w.flush()
+	if w.zstd != nil {
+		w.zstd.Close()
+	}
+
+	return err
+}
+
+func (w *Writer) flush() error {
+	if len(w.buffer) == 0 {
+		return nil
+	}
+
+	var (
+		frameType = frameRaw
+		payload   = w.buffer
+	)
+	if w.skip == false && w.gzip != nil {
+		compressed := &bytes.Buffer{}
+		w.gzip.Reset(compressed)
+		_, err := w.gzip.Write(w.buffer)
+		if err != nil {
+			return errors.Wrap(err, "gzip")
+		}
+
+		err = w.gzip.Close()
+		if err != nil {
+			return errors.Wrap(err, "gzip")
+		}
+
+		frameType = frameGzip
+		payload = compressed.Bytes()
+	} else if w.skip == false && w.zstd != nil {
+		frameType = frameZstd
+		payload = w.zstd.EncodeAll(w.buffer, nil)
+	}
+
+	// fall back to raw frames if the compression does not pay off
+	if frameType != frameRaw && len(payload) >= len(w.buffer) {
+		frameType = frameRaw
+		payload = w.buffer
+	}
+
+	header := make([]byte, 5)
+	header[0] = frameType
+	binary.BigEndian.PutUint32(header[1:], uint32(len(payload)))
+	_, err := w.writer.Write(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.writer.Write(payload)
+	if err != nil {
+		return err
+	}
+
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// NewReader creates a reader that decompresses the data that was written by a writer of the given codec
+func NewReader(reader io.Reader, codec Codec) (io.ReadCloser, error) {
+	if codec == Legacy {
+		return gzip.NewReader(reader)
+	} else if Valid(codec) == false {
+		return nil, errors.Errorf("unknown compression %s", codec)
+	}
+
+	return &frameReader{reader: reader}, nil
+}
+
+type frameReader struct {
+	reader io.Reader
+	frame  []byte
+
+	gzip *gzip.Reader
+	zstd *zstd.Decoder
+}
+
+// Read implements io.Reader
+func (r *frameReader) Read(p []byte) (int, error) {
+	for len(r.frame) == 0 {
+		err := r.nextFrame()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.frame)
+	r.frame = r.frame[n:]
+	return n, nil
+}
+
+func (r *frameReader) nextFrame() error {
+	header := make([]byte, 5)
+	_, err := io.ReadFull(r.reader, header)
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return errors.New("unexpected end of frame header")
+		}
+
+		return err
+	}
+
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > maxFrameSize {
+		return errors.Errorf("frame size %d exceeds the maximum of %d", size, maxFrameSize)
+	}
+
+	payload := make([]byte, size)
+	_, err = io.ReadFull(r.reader, payload)
+	if err != nil {
+		return errors.Wrap(err, "read frame")
+	}
+
+	switch header[0] {
+	case frameRaw:
+		r.frame = payload
+	case frameGzip:
+		if r.gzip == nil {
+			r.gzip, err = gzip.NewReader(bytes.NewReader(payload))
+		} else {
+			err = r.gzip.Reset(bytes.NewReader(payload))
+		}
+		if err != nil {
+			return errors.Wrap(err, "gzip")
+		}
+
+		r.frame, err = ioutil.ReadAll(r.gzip)
+		if err != nil {
+			return errors.Wrap(err, "gzip")
+		}
+	case frameZstd:
+		if r.zstd == nil {
+			r.zstd, err = zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return errors.Wrap(err, "create zstd reader")
+			}
+		}
+
+		r.frame, err = r.zstd.DecodeAll(payload, nil)
+		if err != nil {
+			return errors.Wrap(err, "zstd")
+		}
+	default:
+		return errors.Errorf("unknown frame type %d", header[0])
+	}
+
+	return nil
+}
+
+// Close releases the resources of the reader, it does not close the underlying reader
+func (r *frameReader) Close() error {
+	if r.zstd != nil {
+		r.zstd.Close()
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package compression
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	text := bytes.Repeat([]byte("devspace sync "), 50000)
+	random := make([]byte, 300*1024)
+	rand.New(rand.NewSource(1)).Read(random)
+
+	for _, codec := range []Codec{Legacy, None, Gzip, Zstd} {
+		buffer := &bytes.Buffer{}
+		writer, err := NewWriter(buffer, codec, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = writer.Write(text[:1000])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = writer.SkipCompression(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = writer.Write(random)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = writer.SkipCompression(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = writer.Write(text[1000:])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = writer.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := append(append(append([]byte{}, text[:1000]...), random...), text[1000:]...)
+		if codec != None && buffer.Len() >= len(expected) {
+			t.Fatalf("Expected %s to compress the data, got %d bytes for %d bytes", codec, buffer.Len(), len(expected))
+		}
+
+		reader, err := NewReader(buffer, codec)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := ioutil.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Error reading %s: %v", codec, err)
+		}
+
+		reader.Close()
+		if bytes.Equal(out, expected) == false {
+			t.Fatalf("Unexpected output for %s", codec)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	if codec := Negotiate([]string{"brotli", "zstd", "gzip"}); codec != Zstd {
+		t.Fatalf("Expected zstd, got %s", codec)
+	}
+	if codec := Negotiate([]string{"brotli"}); codec != Legacy {
+		t.Fatalf("Expected legacy, got %s", codec)
+	}
+}
+
+func TestIsCompressed(t *testing.T) {
+	if IsCompressed("/models/model.ZIP") == false || IsCompressed("/src/main.go") {
+		t.Fatal("Unexpected compressed file detection")
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/delta"
+	"github.com/pkg/errors"
+)
+
+// Signatures computes the block signatures of the requested files, which are used by the
+// client to compute the delta for an upload. For files that do not exist an empty signature is returned
+func (u *Upstream) Signatures(stream remote.Upstream_SignaturesServer) error {
+	paths := make([]string, 0, 16)
+	for {
+		chunk, err := stream.Recv()
+		if chunk != nil {
+			paths = append(paths, chunk.Paths...)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	for _, path := range paths {
+		signature, err := delta.Signature(filepath.Join(u.options.UploadPath, path), path)
+		if err != nil {
+			signature = &remote.FileSignature{Path: path}
+		}
+
+		err = stream.Send(signature)
+		if err != nil {
+			return errors.Wrap(err, "send signature")
+		}
+	}
+
+	return nil
+}
+
+// UploadDelta reconstructs the uploaded files from the received deltas and the existing files. The paths of
+// the files that couldn't be reconstructed are returned to the client, which should upload them completely instead
+func (u *Upstream) UploadDelta(stream remote.Upstream_UploadDeltaServer) error {
+	patcher := delta.NewPatcher(u.options.UploadPath)
+	defer patcher.Close()
+
+	// the batch commands are executed after the client completed the batch
+	batch := u.options.uploadRuleMatcher.NewBatch()
+	defer u.addToBatch(batch)
+
+	failed := []string{}
+	for {
+		chunk, err := stream.Recv()
+		if chunk != nil {
+			file, err := patcher.Apply(chunk)
+			if err != nil {
+				return errors.Wrap(err, "apply delta")
+			} else if file != nil {
+				err = u.commitPatchedFile(file)
+				if err != nil {
+					failed = append(failed, file.Header.Path)
+				} else {
+					batch.Add(file.Header.Path, false)
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return stream.SendAndClose(&remote.Paths{
+		Paths: failed,
+	})
+}
+
+func (u *Upstream) commitPatchedFile(file *delta.PatchedFile) error {
+	if file.Err != nil {
+		return file.Err
+	}
+
+	stat, _ := os.Stat(file.TargetPath)
+	err := file.Commit()
+	if err != nil {
+		file.Discard()
+		return err
+	}
+
+	applyFileMetadata(file.TargetPath, stat, os.FileMode(file.Header.Mode), time.Unix(file.Header.MtimeUnix, 0), u.options)
+	return executeFileChangeCmd(file.TargetPath, u.options)
+}
+
+// DownloadDelta computes the deltas between the requested files and the received signatures
+// of the local files. For files that cannot be found an empty file with EOF is sent
+func (d *Downstream) DownloadDelta(stream remote.Downstream_DownloadDeltaServer) error {
+	signatures := make([]*remote.FileSignature, 0, 16)
+	for {
+		signature, err := stream.Recv()
+		if signature != nil {
+			signatures = append(signatures, signature)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	for _, signature := range signatures {
+		absolutePath := filepath.Join(d.options.RemotePath, signature.Path)
+		stat, err := os.Stat(absolutePath)
+		if err != nil || stat.IsDir() || signature.BlockSize <= 0 {
+			err = stream.Send(&remote.DeltaChunk{Path: signature.Path, BlockSize: signature.BlockSize, EOF: true})
+			if err != nil {
+				return errors.Wrap(err, "send delta")
+			}
+
+			continue
+		}
+
+		_, err = delta.Send(absolutePath, signature, stream.Send)
+		if err != nil {
+			return errors.Wrapf(err, "send delta %s", signature.Path)
+		}
+	}
+
+	return nil
+}
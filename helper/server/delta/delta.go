@@ -0,0 +1,299 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"math"
+	"os"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/pkg/errors"
+)
+
+const (
+	minBlockSize = 4 * 1024
+	maxBlocks    = 65536
+
+	// maxLiteralSize is the maximum size of a single literal operation
+	maxLiteralSize = 64 * 1024
+
+	// maxChunkOperations is the maximum number of operations sent in a single chunk
+	maxChunkOperations = 256
+)
+
+// BlockSize returns the block size that should be used for the signature of a file with the given size.
+// Similar to rsync we use the square root of the file size, but make sure the amount of blocks stays
+// small enough to fit a signature into a single grpc message
+func BlockSize(size int64) int64 {
+	blockSize := int64(math.Sqrt(float64(size)))
+	if blockSize < size/maxBlocks {
+		blockSize = size / maxBlocks
+	}
+
+	// round up to the next kilobyte
+	blockSize = ((blockSize + 1023) / 1024) * 1024
+	if blockSize < minBlockSize {
+		return minBlockSize
+	}
+
+	return blockSize
+}
+
+// Signature computes the block signature of the file at absPath
+func Signature(absPath, relativePath string) (*remote.FileSignature, error) {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, err
+	} else if stat.IsDir() {
+		return nil, errors.Errorf("%s is a directory", absPath)
+	}
+
+	signature := &remote.FileSignature{
+		Path:      relativePath,
+		BlockSize: BlockSize(stat.Size()),
+		Blocks:    make([]*remote.BlockSignature, 0, stat.Size()/BlockSize(stat.Size())+1),
+	}
+
+	buf := make([]byte, signature.BlockSize)
+	for {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 {
+			strong := md5.Sum(buf[:n])
+			signature.Blocks = append(signature.Blocks, &remote.BlockSignature{
+				Weak:   newRollsum(buf[:n]).digest(),
+				Strong: strong[:],
+			})
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return signature, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Send computes the delta between the file at absPath and the given signature of the base file
+// and sends the result as chunks. The first chunk contains the header of the file and the last
+// chunk the checksum of the complete file. Returns the amount of literal bytes that were sent.
+func Send(absPath string, signature *remote.FileSignature, send func(chunk *remote.DeltaChunk) error) (int64, error) {
+	f, err := os.Open(absPath)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		hash    = sha256.New()
+		reader  = bufio.NewReaderSize(io.TeeReader(f, hash), 256*1024)
+		literal = 0
+		chunk   = &remote.DeltaChunk{
+			Path:      signature.Path,
+			MtimeUnix: stat.ModTime().Unix(),
+			Size:      stat.Size(),
+			Mode:      uint32(stat.Mode().Perm()),
+			BlockSize: signature.BlockSize,
+		}
+	)
+
+	emit := func(op *remote.DeltaOperation) error {
+		literal += len(op.Data)
+		chunk.Operations = append(chunk.Operations, op)
+		if len(chunk.Operations) >= maxChunkOperations || len(op.Data) >= maxLiteralSize {
+			err := send(chunk)
+			if err != nil {
+				return err
+			}
+
+			chunk = &remote.DeltaChunk{}
+		}
+
+		return nil
+	}
+
+	err = computeDelta(reader, signature, emit)
+	if err != nil {
+		return 0, errors.Wrapf(err, "compute delta %s", absPath)
+	}
+
+	chunk.EOF = true
+	chunk.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return int64(literal), send(chunk)
+}
+
+func computeDelta(reader *bufio.Reader, signature *remote.FileSignature, emit func(op *remote.DeltaOperation) error) error {
+	blockSize := int(signature.BlockSize)
+	if blockSize <= 0 {
+		return errors.New("invalid block size")
+	}
+
+	lookup := make(map[uint32][]int, len(signature.Blocks))
+	for idx, block := range signature.Blocks {
+		lookup[block.Weak] = append(lookup[block.Weak], idx)
+	}
+
+	// window holds the bytes that are currently checked against the signature blocks,
+	// literal holds the bytes that couldn't be matched so far
+	var (
+		buffer  = make([]byte, 2*blockSize)
+		window  = buffer[:0]
+		literal = make([]byte, 0, maxLiteralSize)
+		eof     = false
+	)
+
+	fill := func() error {
+		for len(window) < blockSize && !eof {
+			b, err := reader.ReadByte()
+			if err == io.EOF {
+				eof = true
+				break
+			} else if err != nil {
+				return err
+			}
+
+			window = append(window, b)
+		}
+
+		return nil
+	}
+	flushLiteral := func() error {
+		if len(literal) == 0 {
+			return nil
+		}
+
+		data := make([]byte, len(literal))
+		copy(data, literal)
+		literal = literal[:0]
+		return emit(&remote.DeltaOperation{Data: data})
+	}
+
+	err := fill()
+	if err != nil {
+		return err
+	}
+
+	sum := newRollsum(window)
+	for len(window) > 0 {
+		if idx, ok := match(lookup, signature.Blocks, sum.digest(), window); ok {
+			err := flushLiteral()
+			if err != nil {
+				return err
+			}
+
+			err = emit(&remote.DeltaOperation{BlockIndex: int64(idx)})
+			if err != nil {
+				return err
+			}
+
+			window = buffer[:0]
+			err = fill()
+			if err != nil {
+				return err
+			}
+
+			sum = newRollsum(window)
+			continue
+		}
+
+		// no match, so move the window by one byte
+		out := window[0]
+		literal = append(literal, out)
+		if len(literal) >= maxLiteralSize {
+			err := flushLiteral()
+			if err != nil {
+				return err
+			}
+		}
+
+		if eof {
+			sum.rollOut(out)
+			window = window[1:]
+			continue
+		}
+
+		in, err := reader.ReadByte()
+		if err == io.EOF {
+			eof = true
+			sum.rollOut(out)
+			window = window[1:]
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		sum.roll(out, in)
+
+		// move the window back to the start of the buffer if there is no space left
+		if len(window) == cap(window) {
+			copy(buffer, window[1:])
+			window = buffer[:len(window)-1]
+		} else {
+			window = window[1:]
+		}
+		window = append(window, in)
+	}
+
+	return flushLiteral()
+}
+
+func match(lookup map[uint32][]int, blocks []*remote.BlockSignature, weak uint32, window []byte) (int, bool) {
+	candidates, ok := lookup[weak]
+	if !ok {
+		return 0, false
+	}
+
+	strong := md5.Sum(window)
+	for _, idx := range candidates {
+		if bytes.Equal(blocks[idx].Strong, strong[:]) {
+			return idx, true
+		}
+	}
+
+	return 0, false
+}
+
+// rollsum is the rsync rolling checksum
+type rollsum struct {
+	a, b uint32
+	n    uint32
+}
+
+func newRollsum(data []byte) *rollsum {
+	r := &rollsum{n: uint32(len(data))}
+	for i, b := range data {
+		r.a += uint32(b)
+		r.b += uint32(len(data)-i) * uint32(b)
+	}
+
+	return r
+}
+
+func (r *rollsum) roll(out, in byte) {
+	r.a = r.a - uint32(out) + uint32(in)
+	r.b = r.b - r.n*uint32(out) + r.a
+}
+
+func (r *rollsum) rollOut(out byte) {
+	r.a -= uint32(out)
+	r.b -= r.n * uint32(out)
+	r.n--
+}
+
+func (r *rollsum) digest() uint32 {
+	return (r.a & 0xffff) | (r.b&0xffff)<<16
+}
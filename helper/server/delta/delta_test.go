@@ -0,0 +1,165 @@
+package delta
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+)
+
+type testCase struct {
+	name    string
+	base    []byte
+	changed []byte
+
+	maxLiteral int64
+}
+
+func TestRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	base := make([]byte, 1024*1024)
+	random.Read(base)
+
+	inserted := append(append(append([]byte{}, base[:300000]...), []byte("some inserted bytes")...), base[300000:]...)
+	modified := append([]byte{}, base...)
+	copy(modified[500000:], []byte("modified bytes"))
+	truncated := append([]byte{}, base[:700001]...)
+
+	testCases := []testCase{
+		{
+			name:       "Unchanged",
+			base:       base,
+			changed:    base,
+			maxLiteral: 0,
+		},
+		{
+			name:       "Inserted",
+			base:       base,
+			changed:    inserted,
+			maxLiteral: 2 * BlockSize(int64(len(base))),
+		},
+		{
+			name:       "Modified",
+			base:       base,
+			changed:    modified,
+			maxLiteral: 2 * BlockSize(int64(len(base))),
+		},
+		{
+			name:       "Truncated",
+			base:       base,
+			changed:    truncated,
+			maxLiteral: BlockSize(int64(len(base))),
+		},
+		{
+			name:       "Empty",
+			base:       base,
+			changed:    []byte{},
+			maxLiteral: 0,
+		},
+		{
+			name:       "From Empty",
+			base:       []byte{},
+			changed:    base[:10000],
+			maxLiteral: 10000,
+		},
+	}
+
+	for _, testCase := range testCases {
+		baseDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		changedDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(baseDir, "file"), testCase.base, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join(changedDir, "file"), testCase.changed, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature, err := Signature(filepath.Join(baseDir, "file"), "/file")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var patched *PatchedFile
+		patcher := NewPatcher(baseDir)
+		literal, err := Send(filepath.Join(changedDir, "file"), signature, func(chunk *remote.DeltaChunk) error {
+			file, err := patcher.Apply(chunk)
+			if file != nil {
+				patched = file
+			}
+
+			return err
+		})
+		if err != nil {
+			t.Fatalf("Error in test case %s: %v", testCase.name, err)
+		}
+		if patched == nil {
+			t.Fatalf("Error in test case %s: file was not completed", testCase.name)
+		} else if patched.Err != nil {
+			t.Fatalf("Error in test case %s: %v", testCase.name, patched.Err)
+		} else if literal > testCase.maxLiteral {
+			t.Fatalf("Error in test case %s: expected at most %d literal bytes, got %d", testCase.name, testCase.maxLiteral, literal)
+		}
+
+		err = patched.Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, err := ioutil.ReadFile(filepath.Join(baseDir, "file"))
+		if err != nil {
+			t.Fatal(err)
+		} else if bytes.Equal(out, testCase.changed) == false {
+			t.Fatalf("Error in test case %s: reconstructed file is not equal", testCase.name)
+		}
+
+		os.RemoveAll(baseDir)
+		os.RemoveAll(changedDir)
+	}
+}
+
+func TestChecksumMismatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "file"), []byte("base content"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patcher := NewPatcher(dir)
+	file, err := patcher.Apply(&remote.DeltaChunk{
+		Path:       "/file",
+		BlockSize:  minBlockSize,
+		Operations: []*remote.DeltaOperation{{BlockIndex: 0}},
+		EOF:        true,
+		Checksum:   "invalid",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if file == nil || file.Err == nil {
+		t.Fatal("Expected checksum mismatch")
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) != 1 {
+		t.Fatalf("Expected temporary file to be removed, but found %d files", len(files))
+	}
+}
@@ -0,0 +1,181 @@
+package delta
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/pkg/errors"
+)
+
+// PatchedFile is a file that was reconstructed by the patcher
+type PatchedFile struct {
+	// Header is the first chunk of the file that contains the file information
+	Header *remote.DeltaChunk
+
+	// TempPath is the path to the reconstructed file
+	TempPath string
+
+	// TargetPath is the path where the reconstructed file should be moved to
+	TargetPath string
+
+	// Err is set if the file couldn't be reconstructed. In this case TempPath
+	// was already removed
+	Err error
+}
+
+// Commit moves the reconstructed file to the target path
+func (p *PatchedFile) Commit() error {
+	return os.Rename(p.TempPath, p.TargetPath)
+}
+
+// Discard removes the reconstructed file
+func (p *PatchedFile) Discard() {
+	_ = os.Remove(p.TempPath)
+}
+
+// Patcher reconstructs files from a stream of delta chunks by using the existing
+// files below the base path as base
+type Patcher struct {
+	basePath string
+
+	current *PatchedFile
+	base    *os.File
+	temp    *os.File
+	hash    hash.Hash
+	buffer  []byte
+}
+
+// NewPatcher creates a new patcher for the given base path
+func NewPatcher(basePath string) *Patcher {
+	return &Patcher{
+		basePath: basePath,
+	}
+}
+
+// Apply applies the given chunk. If the chunk completes a file, the reconstructed file is returned and the caller
+// is responsible to either commit or discard it. If the file couldn't be reconstructed the returned file has Err set.
+func (p *Patcher) Apply(chunk *remote.DeltaChunk) (*PatchedFile, error) {
+	if chunk.Path != "" {
+		if p.current != nil {
+			p.close()
+			return nil, errors.Errorf("received new file %s before %s was completed", chunk.Path, p.current.Header.Path)
+		}
+
+		p.open(chunk)
+	} else if p.current == nil {
+		return nil, errors.New("received delta chunk without file header")
+	}
+
+	if p.current.Err == nil {
+		for _, op := range chunk.Operations {
+			err := p.applyOperation(op)
+			if err != nil {
+				p.current.Err = err
+				break
+			}
+		}
+	}
+
+	if chunk.EOF == false {
+		return nil, nil
+	}
+
+	file := p.current
+	p.close()
+	if file.Err == nil && hex.EncodeToString(p.hash.Sum(nil)) != chunk.Checksum {
+		file.Err = errors.Errorf("checksum mismatch for %s", file.Header.Path)
+	}
+	if file.Err != nil {
+		file.Discard()
+	}
+
+	return file, nil
+}
+
+// Close closes all open files and removes a partially reconstructed file
+func (p *Patcher) Close() {
+	if p.current != nil {
+		file := p.current
+		p.close()
+		file.Discard()
+	}
+}
+
+func (p *Patcher) open(header *remote.DeltaChunk) {
+	targetPath := filepath.Join(p.basePath, filepath.FromSlash(header.Path))
+	p.current = &PatchedFile{
+		Header:     header,
+		TargetPath: targetPath,
+	}
+	p.hash = sha256.New()
+	if header.BlockSize <= 0 {
+		p.current.Err = errors.Errorf("invalid block size %d", header.BlockSize)
+		return
+	}
+
+	base, err := os.Open(targetPath)
+	if err != nil {
+		p.current.Err = err
+		return
+	}
+
+	// the temp file is created next to the target so that we can rename it afterwards
+	temp, err := ioutil.TempFile(filepath.Dir(targetPath), "."+filepath.Base(targetPath)+".delta")
+	if err != nil {
+		base.Close()
+		p.current.Err = err
+		return
+	}
+
+	p.base = base
+	p.temp = temp
+	p.current.TempPath = temp.Name()
+	if int64(len(p.buffer)) != header.BlockSize {
+		p.buffer = make([]byte, header.BlockSize)
+	}
+}
+
+func (p *Patcher) close() {
+	if p.base != nil {
+		p.base.Close()
+		p.base = nil
+	}
+	if p.temp != nil {
+		err := p.temp.Close()
+		if err != nil && p.current.Err == nil {
+			p.current.Err = err
+		}
+
+		p.temp = nil
+	}
+
+	p.current = nil
+}
+
+func (p *Patcher) applyOperation(op *remote.DeltaOperation) error {
+	data := op.Data
+	if len(data) == 0 {
+		n, err := p.base.ReadAt(p.buffer, op.BlockIndex*int64(len(p.buffer)))
+		if n == 0 && err != nil {
+			return errors.Wrapf(err, "read block %d", op.BlockIndex)
+		} else if err != nil && err != io.EOF {
+			return errors.Wrapf(err, "read block %d", op.BlockIndex)
+		}
+
+		data = p.buffer[:n]
+	}
+
+	_, err := p.temp.Write(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.hash.Write(data)
+	return err
+}
@@ -2,10 +2,9 @@ package server
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"context"
+	"github.com/loft-sh/devspace/helper/server/compression"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
-	"github.com/syncthing/notify"
 	"io"
 	"io/ioutil"
 	"log"
@@ -28,10 +27,17 @@ const rescanPeriod = time.Minute * 15
 type DownstreamOptions struct {
 	RemotePath   string
 	ExcludePaths []string
-	ExitOnClose  bool
-	Throttle     int64
+
+	UseGitignore    bool
+	UseDockerignore bool
+	ExitOnClose     bool
+	Throttle        int64
 
 	Polling bool
+
+	// Symlinks is the symlink mode of the sync, preserved symlinks are reported with their target
+	// and followed symlinks are reported as the files and directories they point to
+	Symlinks string
 }
 
 // StartDownstreamServer starts a new downstream server with the given reader and writer
@@ -41,18 +47,28 @@ func StartDownstreamServer(reader io.Reader, writer io.Writer, options *Downstre
 	done := make(chan error)
 
 	// Compile ignore paths
-	ignoreMatcher, err := ignoreparser.CompilePaths(options.ExcludePaths)
+	excludePaths, err := ignoreparser.MergeIgnoreFiles(options.RemotePath, options.ExcludePaths, options.UseGitignore, options.UseDockerignore)
+	if err != nil {
+		return errors.Wrap(err, "load ignore files")
+	}
+
+	ignoreMatcher, err := ignoreparser.CompilePaths(excludePaths)
 	if err != nil {
 		return errors.Wrap(err, "compile paths")
 	}
 
 	go func() {
 		s := grpc.NewServer()
+		go func() {
+			// stop the server if the connection is closed, e.g. if it runs within a multiplexed session
+			<-pipe.Closed()
+			s.Stop()
+		}()
 		downStream := &Downstream{
 			options:       options,
 			ignoreMatcher: ignoreMatcher,
-			events:        make(chan notify.EventInfo, 1000),
 			changes:       map[string]bool{},
+			polling:       options.Polling,
 		}
 
 		remote.RegisterDownstreamServer(s, downStream)
@@ -62,16 +78,16 @@ func StartDownstreamServer(reader io.Reader, writer io.Writer, options *Downstre
 		watchStop := make(chan struct{})
 		if options.Polling == false {
 			go func() {
-				// set up a watchpoint listening for events within a directory tree rooted at specified directory
-				err := notify.Watch(options.RemotePath+"/...", downStream.events, notify.All)
+				watcher, err := startWatcher(options.RemotePath, ignoreMatcher, followSymlinks(options.Symlinks))
 				if err != nil {
-					log.Fatalf("error watching path %s: %v", options.RemotePath, err)
+					log.Printf("Error watching path %s, falling back to polling: %v", options.RemotePath, err)
+					downStream.fallbackToPolling()
 					return
 				}
-				defer notify.Stop(downStream.events)
+				defer watcher.Close()
 
 				// start the watch loop
-				downStream.watch(watchStop)
+				downStream.watch(watcher, watchStop)
 			}()
 		}
 
@@ -93,10 +109,7 @@ type Downstream struct {
 	// watchedFiles is a memory map of the previous state of the changes function
 	watchedFiles map[string]*remote.Change
 
-	// events is the event stream if we watch for changes
-	events chan notify.EventInfo
-
-	// changesMutex is used to protect changes
+	// changesMutex is used to protect changes, lastRescan and polling
 	changesMutex sync.Mutex
 
 	// changes is a map of changed paths
@@ -104,6 +117,18 @@ type Downstream struct {
 
 	// lastRescan is used to rescan the complete path from time to time
 	lastRescan *time.Time
+
+	// polling is true if changes are detected by walking the complete path, either because
+	// it was configured or because the watcher failed
+	polling bool
+
+	// negotiation is the compression of the downloaded archives
+	negotiation negotiation
+}
+
+// Handshake negotiates the compression of the downloaded archives
+func (d *Downstream) Handshake(ctx context.Context, request *remote.HandshakeRequest) (*remote.HandshakeResponse, error) {
+	return d.negotiation.handshake(request), nil
 }
 
 // Download sends the file at the temp download location to the client
@@ -163,16 +188,20 @@ func (d *Downstream) compress(writer io.WriteCloser, files []string) error {
 	defer writer.Close()
 
 	// Use compression
-	gw := gzip.NewWriter(writer)
-	defer gw.Close()
+	codec, level := d.negotiation.compression()
+	compressor, err := compression.NewWriter(writer, codec, level)
+	if err != nil {
+		return err
+	}
+	defer compressor.Close()
 
-	tarWriter := tar.NewWriter(gw)
+	tarWriter := tar.NewWriter(compressor)
 	defer tarWriter.Close()
 
 	writtenFiles := make(map[string]bool)
 	for _, path := range files {
 		if _, ok := writtenFiles[path]; ok == false {
-			err := recursiveTar(d.options.RemotePath, path, writtenFiles, tarWriter, true)
+			err := recursiveTar(d.options.RemotePath, path, writtenFiles, tarWriter, compressor, true)
 			if err != nil {
 				return errors.Wrapf(err, "compress %s", path)
 			}
@@ -182,6 +211,67 @@ func (d *Downstream) compress(writer io.WriteCloser, files []string) error {
 	return nil
 }
 
+// Checksums computes the content checksums of the requested files and streams them back to the client.
+// Paths that do not exist anymore, are directories or are excluded are silently skipped.
+func (d *Downstream) Checksums(stream remote.Downstream_ChecksumsServer) error {
+	paths := make([]string, 0, 128)
+	for {
+		chunk, err := stream.Recv()
+		if chunk != nil {
+			paths = append(paths, chunk.Paths...)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	throttle := time.Duration(d.options.Throttle) * time.Millisecond
+	checksums := make([]*remote.Checksum, 0, 64)
+	for idx, path := range paths {
+		if throttle != 0 && idx > 0 && idx%100 == 0 {
+			time.Sleep(throttle)
+		}
+
+		absolutePath := filepath.Join(d.options.RemotePath, path)
+		stat, err := os.Stat(absolutePath)
+		if err != nil || stat.IsDir() {
+			continue
+		} else if d.ignoreMatcher != nil && d.ignoreMatcher.Matches(path, false) {
+			continue
+		}
+
+		checksum, err := util.FileChecksum(absolutePath)
+		if err != nil {
+			continue
+		}
+
+		checksums = append(checksums, &remote.Checksum{
+			Path:     path,
+			Checksum: checksum,
+		})
+		if len(checksums) >= 64 {
+			err = stream.Send(&remote.ChecksumChunk{Checksums: checksums})
+			if err != nil {
+				return errors.Wrap(err, "send checksums")
+			}
+
+			checksums = make([]*remote.Checksum, 0, 64)
+		}
+	}
+
+	if len(checksums) > 0 {
+		err := stream.Send(&remote.ChecksumChunk{Checksums: checksums})
+		if err != nil {
+			return errors.Wrap(err, "send checksums")
+		}
+	}
+
+	return nil
+}
+
 // Ping returns empty
 func (d *Downstream) Ping(context.Context, *remote.Empty) (*remote.Empty, error) {
 	return &remote.Empty{}, nil
@@ -193,12 +283,13 @@ func (d *Downstream) ChangesCount(context.Context, *remote.Empty) (*remote.Chang
 	throttle := time.Duration(d.options.Throttle) * time.Millisecond
 
 	// Walk through the dir
-	if d.options.Polling {
-		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, throttle)
+	polling := d.isPolling()
+	if polling {
+		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, d.options.Symlinks, newState, throttle)
 	}
 
 	changeAmount := int64(0)
-	if d.options.Polling {
+	if polling {
 		var err error
 		changeAmount, err = streamChanges(d.options.RemotePath, d.watchedFiles, newState, nil, throttle)
 		if err != nil {
@@ -221,97 +312,129 @@ func (d *Downstream) ChangesCount(context.Context, *remote.Empty) (*remote.Chang
 	}, nil
 }
 
-func (d *Downstream) getWatchState() map[string]*remote.Change {
-	d.changesMutex.Lock()
-	defer d.changesMutex.Unlock()
+// Changes retrieves all changes from the watch path
+func (d *Downstream) Changes(empty *remote.Empty, stream remote.Downstream_ChangesServer) error {
+	if d.isPolling() == false {
+		return d.streamWatchedChanges(stream)
+	}
 
+	// Walk through the dir
+	throttle := time.Duration(d.options.Throttle) * time.Millisecond
+	newState := make(map[string]*remote.Change)
+	walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, d.options.Symlinks, newState, throttle)
+
+	_, err := streamChanges(d.options.RemotePath, d.watchedFiles, newState, stream, throttle)
+	if err != nil {
+		return errors.Wrap(err, "stream changes")
+	}
+
+	d.watchedFiles = newState
+	return nil
+}
+
+// streamWatchedChanges streams the changes of the paths that were reported by the watcher since the last
+// call. Only these paths are compared with the previous state, the complete path is rescanned if there were
+// too many changes, events were lost or the last rescan is too long ago
+func (d *Downstream) streamWatchedChanges(stream remote.Downstream_ChangesServer) error {
+	d.changesMutex.Lock()
 	var (
 		now          = time.Now()
-		shouldRescan = d.watchedFiles == nil || d.lastRescan == nil || d.lastRescan.Add(rescanPeriod).Before(now)
-		changeAmount = len(d.changes)
+		shouldRescan = d.watchedFiles == nil || d.lastRescan == nil || d.lastRescan.Add(rescanPeriod).Before(now) || len(d.changes) > 100
+		changes      = d.changes
 	)
 
-	if changeAmount > 100 || shouldRescan {
-		// we rescan so reset all changes
-		d.changes = map[string]bool{}
+	// we rescan so reset all changes
+	d.changes = map[string]bool{}
+	if shouldRescan {
 		d.lastRescan = &now
+	}
+	d.changesMutex.Unlock()
 
-		newState := make(map[string]*remote.Change)
-		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, 0)
-		return newState
-	} else if changeAmount == 0 {
+	oldState := d.watchedFiles
+	newState := make(map[string]*remote.Change)
+	if shouldRescan {
+		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, d.options.Symlinks, newState, 0)
+	} else if len(changes) == 0 {
 		return nil
+	} else {
+		oldState = make(map[string]*remote.Change)
+		for path := range changes {
+			d.collectPath(path, oldState, newState)
+		}
 	}
 
-	// copy state from old
-	newState := copyState(d.watchedFiles)
+	_, err := streamChanges(d.options.RemotePath, oldState, newState, stream, 0)
+	if err != nil {
+		// the changes were not received, so we have to rescan the next time
+		d.changesMutex.Lock()
+		d.lastRescan = nil
+		d.changesMutex.Unlock()
+		return errors.Wrap(err, "stream changes")
+	}
 
-	// copy changes
-	changes := []string{}
-	for k := range d.changes {
-		changes = append(changes, k)
+	if shouldRescan {
+		d.watchedFiles = newState
+		return nil
 	}
-	d.changes = map[string]bool{}
 
-	// apply changes
-	for _, change := range changes {
-		d.applyChange(newState, change)
+	for path := range oldState {
+		delete(d.watchedFiles, path)
+	}
+	for path, change := range newState {
+		d.watchedFiles[path] = change
 	}
 
-	return newState
+	return nil
 }
 
-// Changes retrieves all changes from the watch path
-func (d *Downstream) Changes(empty *remote.Empty, stream remote.Downstream_ChangesServer) error {
-	newState := make(map[string]*remote.Change)
-	throttle := time.Duration(d.options.Throttle) * time.Millisecond
+func (d *Downstream) isPolling() bool {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
 
-	// Walk through the dir
-	if d.options.Polling == false {
-		newState = d.getWatchState()
-	} else {
-		walkDir(d.options.RemotePath, d.options.RemotePath, d.ignoreMatcher, newState, throttle)
-	}
+	return d.polling
+}
 
-	if newState != nil {
-		_, err := streamChanges(d.options.RemotePath, d.watchedFiles, newState, stream, throttle)
-		if err != nil {
-			return errors.Wrap(err, "stream changes")
-		}
+// fallbackToPolling switches to walking the complete path, e.g. if the inotify limits are exhausted
+func (d *Downstream) fallbackToPolling() {
+	d.changesMutex.Lock()
+	defer d.changesMutex.Unlock()
 
-		d.watchedFiles = newState
-	}
-	return nil
+	d.polling = true
+	d.changes = map[string]bool{}
 }
 
-func (d *Downstream) watch(stopChan chan struct{}) {
+func (d *Downstream) watch(watcher pathWatcher, stopChan chan struct{}) {
+	// changes that happened before the watcher was started are not reported, so we rescan once
+	d.changesMutex.Lock()
+	d.lastRescan = nil
+	d.changesMutex.Unlock()
+
 	for {
 		select {
 		case <-stopChan:
 			return
-		case event, ok := <-d.events:
-			if ok == false {
-				return
-			}
-
+		case err := <-watcher.Errors():
+			log.Printf("Error watching path %s, falling back to polling: %v", d.options.RemotePath, err)
+			d.fallbackToPolling()
+			return
+		case path := <-watcher.Events():
 			d.changesMutex.Lock()
 			// re-sync if overflow
-			if len(d.events) >= 999 {
+			if path == "" || len(watcher.Events()) >= 999 {
 				d.lastRescan = nil
 			} else {
 				// check if parent folder might be already in changes then skip
 				// this saves us a lot of folder crawling later on
-				parts := strings.Split(filepath.ToSlash(event.Path()), "/")
+				parts := strings.Split(filepath.ToSlash(path), "/")
 				found := false
 				for i := len(parts) - 1; i > 0; i-- {
-					path := strings.Join(parts[:i], "/")
-					if d.changes[path] {
+					if d.changes[strings.Join(parts[:i], "/")] {
 						found = true
 						break
 					}
 				}
 				if !found {
-					d.changes[event.Path()] = true
+					d.changes[path] = true
 				}
 			}
 			d.changesMutex.Unlock()
@@ -319,25 +442,29 @@ func (d *Downstream) watch(stopChan chan struct{}) {
 	}
 }
 
-func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath string) {
+// collectPath collects the previous and the current state of the path and everything below it
+func (d *Downstream) collectPath(fullPath string, oldState, newState map[string]*remote.Change) {
 	if strings.HasSuffix(fullPath, "/") {
 		fullPath = fullPath[:len(fullPath)-1]
 	}
 
 	relativePath := fullPath[len(d.options.RemotePath):]
 
-	// in any case we mark this part of the tree as dirty and delete it
+	// in any case we mark this part of the tree as dirty and compare it
 	if old, ok := d.watchedFiles[fullPath]; ok {
+		oldState[fullPath] = old
 		if old.IsDir {
-			deletePathFromState(newState, fullPath)
-		} else {
-			delete(newState, fullPath)
+			for path, change := range d.watchedFiles {
+				if strings.HasPrefix(path, fullPath+"/") {
+					oldState[path] = change
+				}
+			}
 		}
 	}
 
 	// check if the path still exists
-	stat, err := os.Stat(fullPath)
-	if err != nil {
+	stat, linkTarget, err := statPath(fullPath, d.options.Symlinks)
+	if err != nil || isUploadTempFile(fullPath) {
 		return
 	} else if d.ignoreMatcher != nil && d.ignoreMatcher.RequireFullScan() == false && d.ignoreMatcher.Matches(relativePath, stat.IsDir()) {
 		return
@@ -351,7 +478,7 @@ func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath st
 			}
 		}
 
-		walkDir(d.options.RemotePath, fullPath, d.ignoreMatcher, newState, time.Duration(d.options.Throttle)*time.Millisecond)
+		walkDir(d.options.RemotePath, fullPath, d.ignoreMatcher, d.options.Symlinks, newState, time.Duration(d.options.Throttle)*time.Millisecond)
 	} else {
 		if d.ignoreMatcher == nil || d.ignoreMatcher.RequireFullScan() == false || d.ignoreMatcher.Matches(relativePath, false) == false {
 			newState[fullPath] = &remote.Change{
@@ -360,35 +487,12 @@ func (d *Downstream) applyChange(newState map[string]*remote.Change, fullPath st
 				MtimeUnix:     stat.ModTime().Unix(),
 				MtimeUnixNano: stat.ModTime().UnixNano(),
 				IsDir:         false,
+				LinkTarget:    linkTarget,
 			}
 		}
 	}
 }
 
-func deletePathFromState(state map[string]*remote.Change, path string) {
-	for k := range state {
-		if strings.HasPrefix(k, path+"/") || k == path {
-			delete(state, k)
-		}
-	}
-}
-
-func copyState(state map[string]*remote.Change) map[string]*remote.Change {
-	newState := make(map[string]*remote.Change, len(state))
-	for k, v := range state {
-		newState[k] = &remote.Change{
-			ChangeType:    v.ChangeType,
-			Path:          v.Path,
-			MtimeUnix:     v.MtimeUnix,
-			MtimeUnixNano: v.MtimeUnixNano,
-			Size:          v.Size,
-			IsDir:         v.IsDir,
-		}
-	}
-
-	return newState
-}
-
 func streamChanges(basePath string, oldState map[string]*remote.Change, newState map[string]*remote.Change, stream remote.Downstream_ChangesServer, throttle time.Duration) (int64, error) {
 	changeAmount := int64(0)
 	if oldState == nil {
@@ -405,7 +509,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 		}
 
 		if oldFile, ok := oldState[newFile.Path]; ok {
-			if oldFile.IsDir != newFile.IsDir || oldFile.Size != newFile.Size || oldFile.MtimeUnix != newFile.MtimeUnix || oldFile.MtimeUnixNano != newFile.MtimeUnixNano {
+			if oldFile.IsDir != newFile.IsDir || oldFile.Size != newFile.Size || oldFile.MtimeUnix != newFile.MtimeUnix || oldFile.MtimeUnixNano != newFile.MtimeUnixNano || oldFile.LinkTarget != newFile.LinkTarget {
 				if stream != nil {
 					changes = append(changes, &remote.Change{
 						ChangeType:    remote.ChangeType_CHANGE,
@@ -414,6 +518,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 						MtimeUnixNano: newFile.MtimeUnixNano,
 						Size:          newFile.Size,
 						IsDir:         newFile.IsDir,
+						LinkTarget:    newFile.LinkTarget,
 					})
 				}
 
@@ -428,6 +533,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 					MtimeUnixNano: newFile.MtimeUnixNano,
 					Size:          newFile.Size,
 					IsDir:         newFile.IsDir,
+					LinkTarget:    newFile.LinkTarget,
 				})
 			}
 
@@ -460,6 +566,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 					MtimeUnixNano: oldFile.MtimeUnixNano,
 					Size:          oldFile.Size,
 					IsDir:         oldFile.IsDir,
+					LinkTarget:    oldFile.LinkTarget,
 				})
 			}
 
@@ -487,7 +594,7 @@ func streamChanges(basePath string, oldState map[string]*remote.Change, newState
 	return changeAmount, nil
 }
 
-func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnoreParser, state map[string]*remote.Change, throttle time.Duration) {
+func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnoreParser, symlinks string, state map[string]*remote.Change, throttle time.Duration) {
 	files, err := ioutil.ReadDir(path)
 	if err != nil {
 		// We ignore errors here
@@ -499,10 +606,12 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 
 		// Stat is necessary here, because readdir does not follow symlinks and
 		// IsDir() returns false for symlinked folders
-		stat, err := os.Stat(absolutePath)
+		stat, linkTarget, err := statPath(absolutePath, symlinks)
 		if err != nil {
 			// Woops file is not here anymore -> ignore error
 			continue
+		} else if isUploadTempFile(absolutePath) {
+			continue
 		}
 
 		// Check if ignored
@@ -525,7 +634,7 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 				}
 			}
 
-			walkDir(basePath, absolutePath, ignoreMatcher, state, throttle)
+			walkDir(basePath, absolutePath, ignoreMatcher, symlinks, state, throttle)
 		} else {
 			// Check if not ignored
 			if ignoreMatcher == nil || ignoreMatcher.RequireFullScan() == false || ignoreMatcher.Matches(absolutePath[len(basePath):], false) == false {
@@ -535,6 +644,7 @@ func walkDir(basePath string, path string, ignoreMatcher ignoreparser.IgnorePars
 					MtimeUnix:     stat.ModTime().Unix(),
 					MtimeUnixNano: stat.ModTime().UnixNano(),
 					IsDir:         false,
+					LinkTarget:    linkTarget,
 				}
 			}
 		}
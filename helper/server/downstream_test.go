@@ -5,13 +5,18 @@ package server
 import (
 	"context"
 	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
 	"github.com/loft-sh/devspace/helper/util"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
 )
 
 func TestDownstreamServer(t *testing.T) {
@@ -133,9 +138,9 @@ func TestDownstreamServer(t *testing.T) {
 	w.Close()
 	log.Println("Downloaded complete file")
 
-	err = untarAll(r, &UpstreamOptions{UploadPath: toDir})
-	if err != nil {
-		t.Fatal(err)
+	result := untarAll(r, compression.Legacy, &UpstreamOptions{UploadPath: toDir}, nil)
+	if result.Incomplete || len(result.Failed) > 0 {
+		t.Fatalf("Untar failed: %v", result)
 	}
 
 	log.Println("Untared the downloaded file")
@@ -188,6 +193,120 @@ func TestDownstreamServer(t *testing.T) {
 	}
 }
 
+func TestDownstreamServerWatch(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("inotify is only supported on linux")
+	}
+
+	fromDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fromDir)
+
+	err = createFiles(fromDir, fileStructure)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	go func() {
+		_ = StartDownstreamServer(serverReader, clientWriter, &DownstreamOptions{
+			RemotePath:   fromDir,
+			ExcludePaths: []string{"emptydir"},
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first call returns the complete state
+	client := remote.NewDownstreamClient(conn)
+	changes, err := waitForChanges(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) == 0 {
+		t.Fatal("Expected initial changes")
+	}
+
+	// changes in excluded directories are not reported
+	err = os.MkdirAll(filepath.Join(fromDir, "emptydir"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(fromDir, "emptydir", "excluded.txt"), []byte("excluded"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(fromDir, "test.txt"), []byte("overidden"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Mkdir(filepath.Join(fromDir, "newdir"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(fromDir, "newdir", "new.txt"), []byte("new"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = waitForChanges(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := map[string]bool{}
+	for _, change := range changes {
+		paths[change.Path] = true
+	}
+	if len(paths) != 3 || paths["/test.txt"] == false || paths["/newdir"] == false || paths["/newdir/new.txt"] == false {
+		t.Fatalf("Unexpected changes %v", paths)
+	}
+
+	// files in new directories are watched as well
+	err = os.Remove(filepath.Join(fromDir, "newdir", "new.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes, err = waitForChanges(client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "/newdir/new.txt" || changes[0].ChangeType != remote.ChangeType_DELETE {
+		t.Fatalf("Unexpected changes %v", changes)
+	}
+}
+
+// waitForChanges waits until the watcher reported changes and retrieves them
+func waitForChanges(client remote.DownstreamClient) ([]*remote.Change, error) {
+	for i := 0; i < 50; i++ {
+		amount, err := client.ChangesCount(context.Background(), &remote.Empty{})
+		if err != nil {
+			return nil, err
+		} else if amount.Amount == 0 {
+			time.Sleep(time.Millisecond * 100)
+			continue
+		}
+
+		// wait for the remaining events of the change
+		time.Sleep(time.Millisecond * 200)
+		changesClient, err := client.Changes(context.Background(), &remote.Empty{})
+		if err != nil {
+			return nil, err
+		}
+
+		return getAllChanges(changesClient)
+	}
+
+	return nil, errors.New("timed out waiting for changes")
+}
+
 func getAllChanges(changesClient remote.Downstream_ChangesClient) ([]*remote.Change, error) {
 	changes := make([]*remote.Change, 0, 32)
 	for {
@@ -207,3 +326,55 @@ func getAllChanges(changesClient remote.Downstream_ChangesClient) ([]*remote.Cha
 
 	return changes, nil
 }
+
+func TestWalkDirSymlinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = os.MkdirAll(filepath.Join(dir, "target"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "target", "file"), []byte("test"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for link, target := range map[string]string{"dirlink": "target", "filelink": "target/file", "broken": "missing"} {
+		err = os.Symlink(target, filepath.Join(dir, link))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// follow reports the targets and skips broken symlinks
+	state := map[string]*remote.Change{}
+	walkDir(dir, dir, nil, util.SymlinksFollow, state, 0)
+	if state[filepath.Join(dir, "dirlink", "file")] == nil || state[filepath.Join(dir, "filelink")] == nil || state[filepath.Join(dir, "filelink")].LinkTarget != "" {
+		t.Fatalf("Expected followed symlinks, got %v", state)
+	} else if state[filepath.Join(dir, "broken")] != nil {
+		t.Fatal("Expected broken symlink to be skipped")
+	}
+
+	// preserve reports the symlinks with their targets and doesn't walk symlinked directories
+	state = map[string]*remote.Change{}
+	walkDir(dir, dir, nil, util.SymlinksPreserve, state, 0)
+	for link, target := range map[string]string{"dirlink": "target", "filelink": "target/file", "broken": "missing"} {
+		change := state[filepath.Join(dir, link)]
+		if change == nil || change.LinkTarget != target || change.IsDir {
+			t.Fatalf("Expected preserved symlink %s -> %s, got %v", link, target, change)
+		}
+	}
+	if state[filepath.Join(dir, "dirlink", "file")] != nil {
+		t.Fatal("Expected symlinked directory not to be walked")
+	}
+
+	// ignore skips all symlinks
+	state = map[string]*remote.Change{}
+	walkDir(dir, dir, nil, util.SymlinksIgnore, state, 0)
+	if len(state) != 2 {
+		t.Fatalf("Expected only target and target/file, got %v", state)
+	}
+}
@@ -0,0 +1,34 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
+)
+
+// negotiation holds the compression that was negotiated with the client. Clients that do not
+// send a handshake use the legacy compression
+type negotiation struct {
+	mutex sync.Mutex
+	codec compression.Codec
+	level int
+}
+
+func (n *negotiation) handshake(request *remote.HandshakeRequest) *remote.HandshakeResponse {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.codec = compression.Negotiate(request.Compression)
+	n.level = int(request.CompressionLevel)
+	return &remote.HandshakeResponse{
+		Compression: string(n.codec),
+	}
+}
+
+func (n *negotiation) compression() (compression.Codec, int) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.codec, n.level
+}
@@ -0,0 +1,156 @@
+package ignoreparser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// GitIgnoreFile is the name of the files that are loaded in every directory if gitignore files are used
+	GitIgnoreFile = ".gitignore"
+
+	// DockerIgnoreFile is the name of the file that is loaded in the root directory if dockerignore files are used
+	DockerIgnoreFile = ".dockerignore"
+)
+
+// LoadIgnoreFiles reads the .dockerignore in the given root and all nested .gitignore files below the given
+// root and returns their patterns relative to the root, so that they can be merged with other exclude paths.
+// Patterns of nested .gitignore files only apply to the directory of the file, directories that are
+// ignored are not searched for further .gitignore files
+func LoadIgnoreFiles(root string, useGitignore, useDockerignore bool) ([]string, error) {
+	patterns := []string{}
+	if useDockerignore {
+		lines, err := readIgnoreFile(filepath.Join(root, DockerIgnoreFile))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, line := range lines {
+			patterns = append(patterns, scopeDockerignorePattern(line))
+		}
+	}
+	if useGitignore == false {
+		return patterns, nil
+	}
+
+	ignoreMatcher, err := CompilePaths(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() == false {
+			return nil
+		}
+
+		relativePath, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		} else if relativePath == "." {
+			relativePath = ""
+		} else {
+			relativePath = "/" + filepath.ToSlash(relativePath)
+			if info.Name() == ".git" || (ignoreMatcher != nil && ignoreMatcher.Matches(relativePath, true)) {
+				return filepath.SkipDir
+			}
+		}
+
+		lines, err := readIgnoreFile(filepath.Join(path, GitIgnoreFile))
+		if err != nil {
+			return err
+		} else if len(lines) == 0 {
+			return nil
+		}
+
+		for _, line := range lines {
+			patterns = append(patterns, scopeGitignorePattern(relativePath, line))
+		}
+
+		ignoreMatcher, err = CompilePaths(patterns)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return patterns, nil
+}
+
+// MergeIgnoreFiles loads the ignore files below the given root and returns their patterns followed
+// by the given exclude paths, so that the exclude paths take precedence
+func MergeIgnoreFiles(root string, excludePaths []string, useGitignore, useDockerignore bool) ([]string, error) {
+	if useGitignore == false && useDockerignore == false {
+		return excludePaths, nil
+	}
+
+	patterns, err := LoadIgnoreFiles(root, useGitignore, useDockerignore)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(patterns, excludePaths...), nil
+}
+
+// readIgnoreFile returns the pattern lines of the given ignore file without comments and empty lines
+func readIgnoreFile(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, errors.Wrapf(err, "read %s", path)
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimRight(line, "\r ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+// scopeGitignorePattern rewrites a pattern of the .gitignore in the given directory, so that it only
+// matches paths within that directory. Patterns without a slash match at any depth below the
+// directory, all other patterns are relative to the directory
+func scopeGitignorePattern(dir, pattern string) string {
+	prefix := ""
+	if strings.HasPrefix(pattern, "!") {
+		prefix = "!"
+		pattern = pattern[1:]
+	}
+
+	suffix := ""
+	if strings.HasSuffix(pattern, "/") {
+		suffix = "/"
+		pattern = strings.TrimRight(pattern, "/")
+	}
+
+	if strings.Contains(pattern, "/") {
+		return prefix + dir + "/" + strings.TrimPrefix(pattern, "/") + suffix
+	} else if dir == "" {
+		return prefix + pattern + suffix
+	}
+
+	return prefix + dir + "/**/" + pattern + suffix
+}
+
+// scopeDockerignorePattern rewrites a .dockerignore pattern, which is always relative to the root
+func scopeDockerignorePattern(pattern string) string {
+	prefix := ""
+	if strings.HasPrefix(pattern, "!") {
+		prefix = "!"
+		pattern = pattern[1:]
+	}
+
+	return prefix + "/" + strings.TrimPrefix(pattern, "/")
+}
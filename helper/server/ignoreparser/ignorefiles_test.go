@@ -0,0 +1,70 @@
+package ignoreparser
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadIgnoreFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	files := map[string]string{
+		".gitignore":                  "# comment\n*.log\n/build\nnode_modules/\n",
+		".dockerignore":               "docs\n",
+		"sub/.gitignore":              "tmp\n/only-here\n!keep.log\n",
+		"node_modules/pkg/.gitignore": "!*.log\n",
+		"sub/nested/.gitignore":       "data/\n",
+	}
+	for name, content := range files {
+		err = os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(root, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	patterns, err := LoadIgnoreFiles(root, true, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ignoreMatcher, err := CompilePaths(patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		"/test.log":               true,
+		"/sub/a/test.log":         true,
+		"/sub/keep.log":           false,
+		"/sub/a/keep.log":         false,
+		"/other/keep.log":         true,
+		"/build":                  true,
+		"/sub/build":              false,
+		"/sub/tmp":                true,
+		"/sub/a/b/tmp":            true,
+		"/tmp":                    false,
+		"/sub/only-here":          true,
+		"/sub/a/only-here":        false,
+		"/sub/nested/data/file":   true,
+		"/sub/data/file":          false,
+		"/node_modules/pkg/a.log": true,
+		"/docs/index.md":          true,
+		"/sub/docs/index.md":      false,
+	}
+	for path, ignored := range expected {
+		if ignoreMatcher.Matches(path, false) != ignored {
+			t.Fatalf("Expected %s to be ignored: %v, patterns: %v", path, ignored, patterns)
+		}
+	}
+}
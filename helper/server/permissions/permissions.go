@@ -0,0 +1,232 @@
+package permissions
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/pkg/errors"
+)
+
+// Rule maps the permissions and the ownership of the synced files and directories that match
+// the path. A rule without a path matches all files and directories
+type Rule struct {
+	Path string `json:"path,omitempty"`
+
+	FileMode string `json:"fileMode,omitempty"`
+	DirMode  string `json:"dirMode,omitempty"`
+	Owner    string `json:"owner,omitempty"`
+	Group    string `json:"group,omitempty"`
+}
+
+type compiledRule struct {
+	Rule
+
+	matcher  ignoreparser.IgnoreParser
+	fileMode *os.FileMode
+	dirMode  *os.FileMode
+}
+
+// Mapper resolves the permissions and ownership of synced paths. Rules are evaluated in order
+// and a later matching rule overrides the values of earlier ones. A nil mapper maps nothing
+type Mapper struct {
+	rules []*compiledRule
+
+	ids      map[string]int
+	idsMutex sync.Mutex
+}
+
+// NewMapper compiles the given rules into a mapper, if no rules are given nil is returned
+func NewMapper(rules []Rule) (*Mapper, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	mapper := &Mapper{
+		rules: make([]*compiledRule, 0, len(rules)),
+		ids:   map[string]int{},
+	}
+	for _, rule := range rules {
+		compiled := &compiledRule{Rule: rule}
+		if rule.Path != "" {
+			matcher, err := ignoreparser.CompilePaths([]string{rule.Path})
+			if err != nil {
+				return nil, errors.Wrapf(err, "compile path %s", rule.Path)
+			}
+
+			compiled.matcher = matcher
+		}
+
+		if rule.FileMode != "" {
+			mode, err := ParseMode(rule.FileMode)
+			if err != nil {
+				return nil, err
+			}
+
+			compiled.fileMode = &mode
+		}
+		if rule.DirMode != "" {
+			mode, err := ParseMode(rule.DirMode)
+			if err != nil {
+				return nil, err
+			}
+
+			compiled.dirMode = &mode
+		}
+
+		mapper.rules = append(mapper.rules, compiled)
+	}
+
+	return mapper, nil
+}
+
+// ParseMode parses an octal permission string such as 0644
+func ParseMode(mode string) (os.FileMode, error) {
+	parsed, err := strconv.ParseUint(mode, 8, 32)
+	if err != nil || parsed > 07777 {
+		return 0, errors.Errorf("invalid mode '%s', expected an octal permission such as 0644", mode)
+	}
+
+	return os.FileMode(parsed), nil
+}
+
+// Mode returns the mapped permissions for the given path relative to the sync root. If no rule
+// defines a mode for the path, false is returned
+func (m *Mapper) Mode(relativePath string, isDir bool) (os.FileMode, bool) {
+	if m == nil {
+		return 0, false
+	}
+
+	var mode *os.FileMode
+	for _, rule := range m.rules {
+		if rule.matches(relativePath, isDir) == false {
+			continue
+		}
+
+		if isDir && rule.dirMode != nil {
+			mode = rule.dirMode
+		} else if isDir == false && rule.fileMode != nil {
+			mode = rule.fileMode
+		}
+	}
+	if mode == nil {
+		return 0, false
+	}
+
+	return *mode, true
+}
+
+// Owner returns the mapped user and group id for the given path relative to the sync root.
+// If no rule defines an owner or group, -1 is returned for the id
+func (m *Mapper) Owner(relativePath string, isDir bool) (int, int, error) {
+	if m == nil {
+		return -1, -1, nil
+	}
+
+	owner, group := "", ""
+	for _, rule := range m.rules {
+		if rule.matches(relativePath, isDir) == false {
+			continue
+		}
+
+		if rule.Owner != "" {
+			owner = rule.Owner
+		}
+		if rule.Group != "" {
+			group = rule.Group
+		}
+	}
+
+	uid, err := m.lookupID(owner, false)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	gid, err := m.lookupID(group, true)
+	if err != nil {
+		return -1, -1, err
+	}
+
+	return uid, gid, nil
+}
+
+// Apply sets the mapped permissions and ownership on the given file
+func (m *Mapper) Apply(absolutePath, relativePath string, isDir bool) error {
+	if m == nil {
+		return nil
+	}
+
+	if mode, ok := m.Mode(relativePath, isDir); ok {
+		err := os.Chmod(absolutePath, mode)
+		if err != nil {
+			return errors.Wrapf(err, "chmod %s", absolutePath)
+		}
+	}
+
+	uid, gid, err := m.Owner(relativePath, isDir)
+	if err != nil {
+		return err
+	} else if uid != -1 || gid != -1 {
+		err = os.Chown(absolutePath, uid, gid)
+		if err != nil {
+			return errors.Wrapf(err, "chown %s", absolutePath)
+		}
+	}
+
+	return nil
+}
+
+func (r *compiledRule) matches(relativePath string, isDir bool) bool {
+	return r.matcher == nil || r.matcher.Matches(relativePath, isDir)
+}
+
+// lookupID resolves a numeric id or a user or group name to its id
+func (m *Mapper) lookupID(name string, isGroup bool) (int, error) {
+	if name == "" {
+		return -1, nil
+	} else if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	key := "user:" + name
+	if isGroup {
+		key = "group:" + name
+	}
+
+	m.idsMutex.Lock()
+	defer m.idsMutex.Unlock()
+	if id, ok := m.ids[key]; ok {
+		return id, nil
+	}
+
+	var (
+		id  string
+		err error
+	)
+	if isGroup {
+		var g *user.Group
+		g, err = user.LookupGroup(name)
+		if err == nil {
+			id = g.Gid
+		}
+	} else {
+		var u *user.User
+		u, err = user.Lookup(name)
+		if err == nil {
+			id = u.Uid
+		}
+	}
+	if err != nil {
+		return -1, errors.Wrapf(err, "lookup %s", name)
+	}
+
+	parsed, err := strconv.Atoi(id)
+	if err != nil {
+		return -1, errors.Errorf("%s has a non numeric id %s", name, id)
+	}
+
+	m.ids[key] = parsed
+	return parsed, nil
+}
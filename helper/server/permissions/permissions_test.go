@@ -0,0 +1,127 @@
+package permissions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+type modeTestCase struct {
+	path  string
+	isDir bool
+
+	expectedMode os.FileMode
+	expectedOk   bool
+}
+
+func TestMapperMode(t *testing.T) {
+	mapper, err := NewMapper([]Rule{
+		{
+			FileMode: "0644",
+			DirMode:  "0755",
+		},
+		{
+			Path:     "/bin/",
+			FileMode: "0755",
+		},
+		{
+			Path:     "*.key",
+			FileMode: "600",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []modeTestCase{
+		{path: "/file", expectedMode: 0644, expectedOk: true},
+		{path: "/dir", isDir: true, expectedMode: 0755, expectedOk: true},
+		{path: "/bin/run", expectedMode: 0755, expectedOk: true},
+		{path: "/bin/secret.key", expectedMode: 0600, expectedOk: true},
+		{path: "/other/secret.key", expectedMode: 0600, expectedOk: true},
+	}
+	for _, testCase := range testCases {
+		mode, ok := mapper.Mode(testCase.path, testCase.isDir)
+		if ok != testCase.expectedOk || mode != testCase.expectedMode {
+			t.Fatalf("Unexpected mode for %s: expected %v (%v), got %v (%v)", testCase.path, testCase.expectedMode, testCase.expectedOk, mode, ok)
+		}
+	}
+
+	var nilMapper *Mapper
+	if _, ok := nilMapper.Mode("/file", false); ok {
+		t.Fatal("Expected nil mapper to map nothing")
+	}
+}
+
+func TestMapperOwner(t *testing.T) {
+	mapper, err := NewMapper([]Rule{
+		{
+			Owner: "1000",
+		},
+		{
+			Path:  "/data/",
+			Group: "2000",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uid, gid, err := mapper.Owner("/file", false)
+	if err != nil {
+		t.Fatal(err)
+	} else if uid != 1000 || gid != -1 {
+		t.Fatalf("Expected uid 1000 and gid -1, got %d and %d", uid, gid)
+	}
+
+	uid, gid, err = mapper.Owner("/data/file", false)
+	if err != nil {
+		t.Fatal(err)
+	} else if uid != 1000 || gid != 2000 {
+		t.Fatalf("Expected uid 1000 and gid 2000, got %d and %d", uid, gid)
+	}
+}
+
+func TestMapperApply(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not supported on windows")
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "file")
+	err = ioutil.WriteFile(file, []byte("test"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mapper, err := NewMapper([]Rule{{FileMode: "0600"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mapper.Apply(file, "/file", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stat, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	} else if stat.Mode().Perm() != 0600 {
+		t.Fatalf("Expected mode 0600, got %v", stat.Mode().Perm())
+	}
+}
+
+func TestNewMapperInvalidMode(t *testing.T) {
+	_, err := NewMapper([]Rule{{FileMode: "rwx"}})
+	if err == nil {
+		t.Fatal("Expected an error for an invalid mode")
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"os"
+
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+)
+
+// errSymlinkIgnored is returned by statPath for symlinks if they are ignored
+var errSymlinkIgnored = errors.New("symlink is ignored")
+
+// followSymlinks returns true if symlinks are followed in the given symlink mode, which is the default
+func followSymlinks(symlinks string) bool {
+	return symlinks != util.SymlinksPreserve && symlinks != util.SymlinksIgnore
+}
+
+// statPath stats the path with respect to the symlink mode. Followed symlinks return the stat of their
+// target, preserved symlinks return their own stat and the link target. Broken symlinks are skipped if
+// they are followed, but preserved like any other symlink
+func statPath(absolutePath string, symlinks string) (os.FileInfo, string, error) {
+	if followSymlinks(symlinks) {
+		stat, err := os.Stat(absolutePath)
+		return stat, "", err
+	}
+
+	stat, err := os.Lstat(absolutePath)
+	if err != nil {
+		return nil, "", err
+	} else if stat.Mode()&os.ModeSymlink == 0 {
+		return stat, "", nil
+	} else if symlinks == util.SymlinksIgnore {
+		return nil, "", errSymlinkIgnored
+	}
+
+	linkTarget, err := os.Readlink(absolutePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return stat, linkTarget, nil
+}
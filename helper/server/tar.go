@@ -2,9 +2,11 @@ package server
 
 import (
 	"archive/tar"
-	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"os/exec"
 	"path"
@@ -12,6 +14,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"github.com/loft-sh/devspace/helper/server/uploadrules"
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 )
 
@@ -21,22 +27,69 @@ type fileInformation struct {
 	Mtime time.Time
 }
 
-func untarAll(reader io.ReadCloser, options *UpstreamOptions) error {
+// uploadTempMarker is part of the names of the temporary files an upload is written to
+const uploadTempMarker = ".devspace-upload-"
+
+// uploadTempExpiry is the time after which a temporary file of an upload that was not written to anymore
+// is removed. These are left behind if the helper is killed during an upload
+const uploadTempExpiry = time.Hour
+
+// isUploadTempFile checks if the file is the temporary or partial file of an upload, these are
+// not reported as changes
+func isUploadTempFile(name string) bool {
+	base := path.Base(name)
+	return strings.HasPrefix(base, ".") && (strings.Contains(base, uploadTempMarker) || strings.Contains(base, partialUploadMarker) || strings.Contains(base, util.SymlinkTempMarker))
+}
+
+// checksumMismatchError is returned by untarNext if the received file content doesn't match
+// the checksum sent by the client. The file is not written in this case
+type checksumMismatchError struct {
+	path string
+}
+
+func (c *checksumMismatchError) Error() string {
+	return "checksum mismatch for " + c.path
+}
+
+// untarAll extracts the tar stream into the upload path and returns the relative paths of the files
+// that failed the checksum verification or couldn't be written. If the archive breaks off, the rest
+// of the stream is discarded and the result contains the files that were received before
+func untarAll(reader io.ReadCloser, codec compression.Codec, options *UpstreamOptions, batch *uploadrules.Batch) *remote.UploadResult {
 	defer reader.Close()
 
-	gzr, err := gzip.NewReader(reader)
+	result := &remote.UploadResult{Failed: []string{}}
+	decompressor, err := compression.NewReader(reader, codec)
 	if err != nil {
-		return errors.Errorf("error decompressing: %v", err)
+		log.Printf("Error decompressing upload: %v", err)
+		result.Incomplete = true
+		_, _ = io.Copy(ioutil.Discard, reader)
+		return result
 	}
-	defer gzr.Close()
+	defer decompressor.Close()
 
-	tarReader := tar.NewReader(gzr)
+	received := []string{}
+	tarReader := tar.NewReader(decompressor)
 	for {
-		shouldContinue, err := untarNext(tarReader, options)
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return result
+		} else if err != nil {
+			log.Printf("Upload broke off: %v", err)
+			result.Incomplete = true
+			result.Received = received
+			_, _ = io.Copy(ioutil.Discard, reader)
+			return result
+		}
+
+		relativePath := getRelativeFromFullPath("/"+header.Name, "")
+		received = append(received, relativePath)
+		err = untarNext(tarReader, header, options, batch)
 		if err != nil {
-			return errors.Wrap(err, "decompress")
-		} else if shouldContinue == false {
-			return nil
+			if _, ok := err.(*checksumMismatchError); !ok {
+				log.Printf("Error applying %s: %v", relativePath, err)
+			}
+
+			result.Failed = append(result.Failed, relativePath)
 		}
 	}
 }
@@ -51,6 +104,17 @@ func createAllFolders(name string, perm os.FileMode, options *UpstreamOptions) e
 	pathParts := strings.Split(slashPath, "/")
 	for i := 1; i < len(pathParts); i++ {
 		dirToCreate := strings.Join(pathParts[:i+1], "/")
+
+		// A preserved or ignored symlink within the upload path is replaced by the folder,
+		// otherwise the files of the folder would be written to the symlink target
+		if followSymlinks(options.Symlinks) == false && strings.HasPrefix(dirToCreate, options.UploadPath+"/") {
+			if lstat, err := os.Lstat(dirToCreate); err == nil && lstat.Mode()&os.ModeSymlink != 0 {
+				if err := os.Remove(dirToCreate); err != nil && os.IsNotExist(err) == false {
+					return errors.Errorf("error removing symlink %s: %v", dirToCreate, err)
+				}
+			}
+		}
+
 		err := os.Mkdir(dirToCreate, perm)
 		if err != nil {
 			if os.IsExist(err) {
@@ -60,6 +124,14 @@ func createAllFolders(name string, perm os.FileMode, options *UpstreamOptions) e
 			return errors.Errorf("error creating %s: %v", dirToCreate, err)
 		}
 
+		// Apply the configured permissions to directories within the upload path
+		if strings.HasPrefix(dirToCreate, options.UploadPath+"/") {
+			err = options.permissionMapper.Apply(dirToCreate, dirToCreate[len(options.UploadPath):], true)
+			if err != nil {
+				log.Printf("Error applying permissions to %s: %v", dirToCreate, err)
+			}
+		}
+
 		if options.DirCreateCmd != "" {
 			cmdArgs := make([]string, 0, len(options.DirCreateArgs))
 			for _, arg := range options.DirCreateArgs {
@@ -80,16 +152,8 @@ func createAllFolders(name string, perm os.FileMode, options *UpstreamOptions) e
 	return nil
 }
 
-func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
-	header, err := tarReader.Next()
-	if err != nil {
-		if err != io.EOF {
-			return false, errors.Wrap(err, "tar reader next")
-		}
-
-		return false, nil
-	}
-
+// untarNext writes the file of the given tar header. The file content is read from the tar reader
+func untarNext(tarReader *tar.Reader, header *tar.Header, options *UpstreamOptions, batch *uploadrules.Batch) error {
 	relativePath := getRelativeFromFullPath("/"+header.Name, "")
 	outFileName := path.Join(options.UploadPath, relativePath)
 	baseName := path.Dir(outFileName)
@@ -98,42 +162,93 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 	stat, _ := os.Stat(outFileName)
 
 	if err := createAllFolders(baseName, 0755, options); err != nil {
-		return false, err
+		return err
 	}
 
 	if header.FileInfo().IsDir() {
 		if err := createAllFolders(outFileName, 0755, options); err != nil {
-			return false, err
+			return err
+		}
+
+		batch.Add(relativePath, true)
+		return nil
+	}
+
+	// Preserved symlinks are created as they are, regardless if their target exists
+	if header.Typeflag == tar.TypeSymlink {
+		if stat != nil && stat.IsDir() {
+			if lstat, err := os.Lstat(outFileName); err == nil && lstat.Mode()&os.ModeSymlink == 0 {
+				log.Printf("Skip symlink %s, because a directory exists at its path", outFileName)
+				return nil
+			}
 		}
 
-		return true, nil
+		err := util.CreateSymlink(header.Linkname, outFileName)
+		if err != nil {
+			return err
+		}
+
+		batch.Add(relativePath, false)
+		return nil
 	}
 
-	// Create / Override file
-	outFile, err := os.Create(outFileName)
+	// Write to a temporary file first, so that a truncated or corrupted stream
+	// never leaves a partially written file behind
+	outFile, err := ioutil.TempFile(baseName, "."+path.Base(outFileName)+uploadTempMarker)
 	if err != nil {
 		// Try again after 5 seconds
 		time.Sleep(time.Second * 5)
-		outFile, err = os.Create(outFileName)
+		outFile, err = ioutil.TempFile(baseName, "."+path.Base(outFileName)+uploadTempMarker)
 		if err != nil {
-			return false, errors.Wrapf(err, "create %s", outFileName)
+			return errors.Wrapf(err, "create %s", outFileName)
 		}
 	}
 
+	tempFileName := outFile.Name()
+	defer os.Remove(tempFileName)
 	defer outFile.Close()
 
-	if _, err := io.Copy(outFile, tarReader); err != nil {
-		return false, errors.Wrapf(err, "io copy tar reader %s", outFileName)
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(outFile, hash), tarReader); err != nil {
+		return errors.Wrapf(err, "io copy tar reader %s", outFileName)
 	}
 	if err := outFile.Close(); err != nil {
-		return false, errors.Wrapf(err, "out file close %s", outFileName)
+		return errors.Wrapf(err, "out file close %s", outFileName)
+	}
+
+	// Older clients don't send a checksum
+	if checksum := header.PAXRecords[util.ChecksumPAXRecord]; checksum != "" && checksum != hex.EncodeToString(hash.Sum(nil)) {
+		return &checksumMismatchError{path: relativePath}
+	}
+
+	// Set permissions before the file is visible, the configured permissions
+	// are applied with the final path below
+	_ = os.Chmod(tempFileName, header.FileInfo().Mode())
+	if err := os.Rename(tempFileName, outFileName); err != nil {
+		return errors.Wrapf(err, "rename %s", outFileName)
+	}
+
+	// Set permissions, owner and group and mod time from tar header
+	applyFileMetadata(outFileName, stat, header.FileInfo().Mode(), header.FileInfo().ModTime(), options)
+
+	// Execute command if defined
+	err = executeFileChangeCmd(outFileName, options)
+	if err != nil {
+		return err
 	}
 
+	batch.Add(relativePath, false)
+	return nil
+}
+
+// applyFileMetadata sets the permissions, owner, group and mod time of a written file. If the file existed before,
+// stat is the stat of the old file
+func applyFileMetadata(outFileName string, stat os.FileInfo, mode os.FileMode, mtime time.Time, options *UpstreamOptions) {
 	// Set old permissions and owner and group
 	if stat != nil {
 		if options.OverridePermission {
 			// Set permissions
-			_ = os.Chmod(outFileName, header.FileInfo().Mode())
+			_ = os.Chmod(outFileName, mode)
 		} else {
 			// Set old permissions correctly
 			_ = os.Chmod(outFileName, stat.Mode())
@@ -143,13 +258,20 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 		_ = Chown(outFileName, stat)
 	} else {
 		// Set permissions
-		_ = os.Chmod(outFileName, header.FileInfo().Mode())
+		_ = os.Chmod(outFileName, mode)
 	}
 
-	// Set mod time from tar header
-	_ = os.Chtimes(outFileName, time.Now(), header.FileInfo().ModTime())
+	// Apply the configured permissions, owner and group
+	err := options.permissionMapper.Apply(outFileName, strings.TrimPrefix(outFileName, options.UploadPath), false)
+	if err != nil {
+		log.Printf("Error applying permissions to %s: %v", outFileName, err)
+	}
 
-	// Execute command if defined
+	// Set mod time
+	_ = os.Chtimes(outFileName, time.Now(), mtime)
+}
+
+func executeFileChangeCmd(outFileName string, options *UpstreamOptions) error {
 	if options.FileChangeCmd != "" {
 		cmdArgs := make([]string, 0, len(options.FileChangeArgs))
 		for _, arg := range options.FileChangeArgs {
@@ -162,14 +284,14 @@ func untarNext(tarReader *tar.Reader, options *UpstreamOptions) (bool, error) {
 
 		out, err := exec.Command(options.FileChangeCmd, cmdArgs...).CombinedOutput()
 		if err != nil {
-			return false, errors.Errorf("error executing command '%s %s': %s => %v", options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
+			return errors.Errorf("error executing command '%s %s': %s => %v", options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
 		}
 	}
 
-	return true, nil
+	return nil
 }
 
-func recursiveTar(basePath, relativePath string, writtenFiles map[string]bool, tw *tar.Writer, skipFolderContents bool) error {
+func recursiveTar(basePath, relativePath string, writtenFiles map[string]bool, tw *tar.Writer, compressor *compression.Writer, skipFolderContents bool) error {
 	absFilepath := path.Join(basePath, relativePath)
 	if _, ok := writtenFiles[relativePath]; ok {
 		return nil
@@ -185,13 +307,13 @@ func recursiveTar(basePath, relativePath string, writtenFiles map[string]bool, t
 	fileInformation := createFileInformationFromStat(relativePath, stat)
 	if stat.IsDir() {
 		// Recursively tar folder
-		return tarFolder(basePath, fileInformation, writtenFiles, stat, tw, skipFolderContents)
+		return tarFolder(basePath, fileInformation, writtenFiles, stat, tw, compressor, skipFolderContents)
 	}
 
-	return tarFile(basePath, fileInformation, writtenFiles, stat, tw)
+	return tarFile(basePath, fileInformation, writtenFiles, stat, tw, compressor)
 }
 
-func tarFolder(basePath string, fileInformation *fileInformation, writtenFiles map[string]bool, stat os.FileInfo, tw *tar.Writer, skipContents bool) error {
+func tarFolder(basePath string, fileInformation *fileInformation, writtenFiles map[string]bool, stat os.FileInfo, tw *tar.Writer, compressor *compression.Writer, skipContents bool) error {
 	filepath := path.Join(basePath, fileInformation.Name)
 	files, err := ioutil.ReadDir(filepath)
 	if err != nil {
@@ -213,7 +335,7 @@ func tarFolder(basePath string, fileInformation *fileInformation, writtenFiles m
 
 	if skipContents == false {
 		for _, f := range files {
-			if err := recursiveTar(basePath, path.Join(fileInformation.Name, f.Name()), writtenFiles, tw, skipContents); err != nil {
+			if err := recursiveTar(basePath, path.Join(fileInformation.Name, f.Name()), writtenFiles, tw, compressor, skipContents); err != nil {
 				return errors.Wrap(err, "recursive tar")
 			}
 		}
@@ -222,7 +344,7 @@ func tarFolder(basePath string, fileInformation *fileInformation, writtenFiles m
 	return nil
 }
 
-func tarFile(basePath string, fileInformation *fileInformation, writtenFiles map[string]bool, stat os.FileInfo, tw *tar.Writer) error {
+func tarFile(basePath string, fileInformation *fileInformation, writtenFiles map[string]bool, stat os.FileInfo, tw *tar.Writer, compressor *compression.Writer) error {
 	var err error
 	filepath := path.Join(basePath, fileInformation.Name)
 	if stat.Mode()&os.ModeSymlink == os.ModeSymlink {
@@ -259,6 +381,12 @@ func tarFile(basePath string, fileInformation *fileInformation, writtenFiles map
 		return nil
 	}
 
+	// there is no need to compress files that are compressed already
+	err = compressor.SkipCompression(compression.IsCompressed(fileInformation.Name))
+	if err != nil {
+		return errors.Wrap(err, "skip compression")
+	}
+
 	if copied, err := io.CopyN(tw, f, stat.Size()); err != nil {
 		return errors.Wrapf(err, "io copy %s", filepath)
 	} else if copied != stat.Size() {
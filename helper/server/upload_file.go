@@ -0,0 +1,199 @@
+package server
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/pkg/errors"
+)
+
+// partialUploadMarker is part of the names of the partially written files of UploadFile. These are
+// kept if an upload is interrupted, so that the next upload of the same file can resume from them
+const partialUploadMarker = ".devspace-partial-"
+
+// partialUploadPath returns the path of the partial file for the given file version. A different
+// size or mod time results in a different path, so a partial file is never resumed with other content
+func partialUploadPath(outFileName string, size int64, mtimeUnix int64) string {
+	return path.Join(path.Dir(outFileName), "."+path.Base(outFileName)+partialUploadMarker+strconv.FormatInt(size, 36)+"-"+strconv.FormatInt(mtimeUnix, 36))
+}
+
+// removeStalePartialUploads removes the partial files of other versions of the given file
+func removeStalePartialUploads(outFileName string, keep string) {
+	files, err := ioutil.ReadDir(path.Dir(outFileName))
+	if err != nil {
+		return
+	}
+
+	prefix := "." + path.Base(outFileName) + partialUploadMarker
+	for _, f := range files {
+		name := path.Join(path.Dir(outFileName), f.Name())
+		if strings.HasPrefix(f.Name(), prefix) && name != keep {
+			_ = os.Remove(name)
+		}
+	}
+}
+
+// partialUploadExpiry is the time after which a partial file that was not written to anymore is removed
+const partialUploadExpiry = 24 * time.Hour
+
+// removeExpiredUploadFiles removes the expired partial and temporary upload files within the upload path.
+// Partial files are left behind if a file is changed or removed locally before its interrupted upload was
+// resumed, temporary files if the helper was killed during an upload
+func removeExpiredUploadFiles(uploadPath string, ignoreMatcher ignoreparser.IgnoreParser) {
+	_ = filepath.Walk(uploadPath, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		} else if info.IsDir() {
+			// Excluded folders don't contain uploaded files
+			if name != uploadPath && ignoreMatcher != nil && ignoreMatcher.RequireFullScan() == false && ignoreMatcher.Matches(name[len(uploadPath):], true) {
+				return filepath.SkipDir
+			}
+
+			return nil
+		} else if strings.HasPrefix(info.Name(), ".") == false {
+			return nil
+		}
+
+		expiry := time.Duration(0)
+		if strings.Contains(info.Name(), partialUploadMarker) {
+			expiry = partialUploadExpiry
+		} else if strings.Contains(info.Name(), uploadTempMarker) || strings.Contains(info.Name(), util.SymlinkTempMarker) {
+			expiry = uploadTempExpiry
+		}
+		if expiry > 0 && time.Since(info.ModTime()) > expiry {
+			_ = os.Remove(name)
+		}
+
+		return nil
+	})
+}
+
+// UploadFile implements the server interface and receives a single large file in chunks that are written
+// to a partial file directly. The server answers the header with the size of an existing partial file, from
+// which the client resumes the upload, and acknowledges every written chunk. The partial file replaces the
+// target after the complete file was received and its checksum matches
+func (u *Upstream) UploadFile(stream remote.Upstream_UploadFileServer) error {
+	header, err := stream.Recv()
+	if err != nil {
+		return errors.Wrap(err, "receive header")
+	}
+
+	relativePath := getRelativeFromFullPath("/"+header.Path, "")
+	outFileName := path.Join(u.options.UploadPath, relativePath)
+	if err := createAllFolders(path.Dir(outFileName), 0755, u.options); err != nil {
+		return err
+	}
+
+	partialFileName := partialUploadPath(outFileName, header.Size, header.MtimeUnix)
+	removeStalePartialUploads(outFileName, partialFileName)
+
+	offset, err := u.receiveFile(stream, header, partialFileName)
+	if err != nil {
+		return err
+	} else if offset != header.Size {
+		// the client ended the upload, because the file changed in the meantime, so the partial
+		// file can't be resumed
+		_ = os.Remove(partialFileName)
+		return errors.Errorf("upload of %s incomplete, received %d of %d bytes", outFileName, offset, header.Size)
+	}
+
+	checksum, err := util.FileChecksum(partialFileName)
+	if err != nil {
+		return errors.Wrapf(err, "checksum %s", outFileName)
+	} else if header.Checksum != checksum {
+		_ = os.Remove(partialFileName)
+		return stream.Send(&remote.FileOffset{Offset: offset, ChecksumMismatch: true})
+	}
+
+	// Check if the file exists already to keep its permissions
+	stat, _ := os.Stat(outFileName)
+
+	_ = os.Chmod(partialFileName, os.FileMode(header.Mode))
+	if err := os.Rename(partialFileName, outFileName); err != nil {
+		return errors.Wrapf(err, "rename %s", outFileName)
+	}
+
+	applyFileMetadata(outFileName, stat, os.FileMode(header.Mode), time.Unix(header.MtimeUnix, 0), u.options)
+	err = executeFileChangeCmd(outFileName, u.options)
+	if err != nil {
+		return err
+	}
+
+	// the batch commands are executed after the client completed the batch
+	batch := u.options.uploadRuleMatcher.NewBatch()
+	batch.Add(relativePath, false)
+	u.addToBatch(batch)
+	return stream.Send(&remote.FileOffset{Offset: offset})
+}
+
+// receiveFile appends the received chunks to the partial file and returns the offset after the last
+// written chunk. The received checksum is stored in the header
+func (u *Upstream) receiveFile(stream remote.Upstream_UploadFileServer, header *remote.FileChunk, partialFileName string) (int64, error) {
+	partialFile, err := os.OpenFile(partialFileName, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, errors.Wrapf(err, "open %s", partialFileName)
+	}
+	defer partialFile.Close()
+
+	stat, err := partialFile.Stat()
+	if err != nil {
+		return 0, errors.Wrapf(err, "stat %s", partialFileName)
+	}
+
+	// A partial file that is larger than the file itself cannot be resumed
+	offset := stat.Size()
+	if offset > header.Size {
+		offset = 0
+	}
+	if err := partialFile.Truncate(offset); err != nil {
+		return 0, errors.Wrapf(err, "truncate %s", partialFileName)
+	}
+	if _, err := partialFile.Seek(offset, io.SeekStart); err != nil {
+		return 0, errors.Wrapf(err, "seek %s", partialFileName)
+	}
+
+	err = stream.Send(&remote.FileOffset{Offset: offset})
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return 0, err
+		}
+
+		if chunk.Checksum != "" {
+			header.Checksum = chunk.Checksum
+		}
+		if len(chunk.Content) == 0 {
+			continue
+		} else if chunk.Offset != offset {
+			return 0, errors.Errorf("unexpected chunk offset %d of %s, expected %d", chunk.Offset, partialFileName, offset)
+		}
+
+		n, err := partialFile.Write(chunk.Content)
+		offset += int64(n)
+		if err != nil {
+			return 0, errors.Wrapf(err, "write %s", partialFileName)
+		}
+
+		err = stream.Send(&remote.FileOffset{Offset: offset})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return offset, partialFile.Close()
+}
@@ -0,0 +1,139 @@
+package uploadrules
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/pkg/errors"
+)
+
+// Rule runs the command once per batch of uploaded changes, if one of the changed paths matches the path.
+// A rule without a command runs nothing, but still claims the matching paths from the following rules
+type Rule struct {
+	Path    string   `json:"path"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+type compiledRule struct {
+	Rule
+
+	matcher ignoreparser.IgnoreParser
+}
+
+// Matcher matches uploaded paths against the rules. Each path is claimed by the first rule that
+// matches it. A nil matcher matches nothing
+type Matcher struct {
+	rules []*compiledRule
+}
+
+// NewMatcher compiles the given rules into a matcher, if no rules are given nil is returned
+func NewMatcher(rules []Rule) (*Matcher, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	matcher := &Matcher{
+		rules: make([]*compiledRule, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		if rule.Path == "" {
+			return nil, errors.New("upload rule without path")
+		}
+
+		compiled, err := ignoreparser.CompilePaths([]string{rule.Path})
+		if err != nil {
+			return nil, errors.Wrapf(err, "compile path %s", rule.Path)
+		}
+
+		matcher.rules = append(matcher.rules, &compiledRule{
+			Rule:    rule,
+			matcher: compiled,
+		})
+	}
+
+	return matcher, nil
+}
+
+// NewBatch starts collecting the changed paths of a new batch
+func (m *Matcher) NewBatch() *Batch {
+	if m == nil {
+		return nil
+	}
+
+	return &Batch{
+		matcher: m,
+		matched: make([]bool, len(m.rules)),
+	}
+}
+
+// Batch collects the rules that matched the changed paths of a batch. A nil batch collects nothing
+type Batch struct {
+	matcher *Matcher
+	matched []bool
+}
+
+// Add matches the changed path relative to the sync root against the rules
+func (b *Batch) Add(relativePath string, isDir bool) {
+	if b == nil {
+		return
+	}
+
+	for i, rule := range b.matcher.rules {
+		if rule.matcher.Matches(relativePath, isDir) {
+			b.matched[i] = true
+			return
+		}
+	}
+}
+
+// Merge adds the matched rules of another batch of the same matcher to the batch
+func (b *Batch) Merge(other *Batch) {
+	if b == nil || other == nil {
+		return
+	}
+
+	for i, matched := range other.matched {
+		if matched {
+			b.matched[i] = true
+		}
+	}
+}
+
+// Commands returns the matched rules that have a command in the order of the rules
+func (b *Batch) Commands() []Rule {
+	if b == nil {
+		return nil
+	}
+
+	commands := []Rule{}
+	for i, rule := range b.matcher.rules {
+		if b.matched[i] && rule.Command != "" {
+			commands = append(commands, rule.Rule)
+		}
+	}
+
+	return commands
+}
+
+// Execute runs the commands of the matched rules within the given directory and resets the batch
+func (b *Batch) Execute(dir string) error {
+	if b == nil {
+		return nil
+	}
+
+	commands := b.Commands()
+	b.matched = make([]bool, len(b.matcher.rules))
+	for _, rule := range commands {
+		cmd := exec.Command(rule.Command, rule.Args...)
+		cmd.Dir = dir
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			return errors.Errorf("Error executing command '%s %s' for changes in '%s': %s => %v", rule.Command, strings.Join(rule.Args, " "), rule.Path, string(out), err)
+		}
+	}
+
+	return nil
+}
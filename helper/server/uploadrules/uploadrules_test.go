@@ -0,0 +1,127 @@
+package uploadrules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+type batchTestCase struct {
+	name  string
+	paths []string
+
+	expectedCommands []string
+}
+
+func TestBatchCommands(t *testing.T) {
+	matcher, err := NewMatcher([]Rule{
+		{
+			Path: "/src/**",
+		},
+		{
+			Path:    "package.json",
+			Command: "npm",
+			Args:    []string{"install"},
+		},
+		{
+			Path:    "*.proto",
+			Command: "make",
+			Args:    []string{"generate"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []batchTestCase{
+		{name: "no match", paths: []string{"/README.md"}},
+		{name: "hot reloaded sources", paths: []string{"/src/index.js", "/src/package.json"}},
+		{name: "single match", paths: []string{"/package.json", "/README.md"}, expectedCommands: []string{"npm"}},
+		{name: "once per batch", paths: []string{"/api/a.proto", "/api/b.proto", "/package.json"}, expectedCommands: []string{"npm", "make"}},
+	}
+	for _, testCase := range testCases {
+		batch := matcher.NewBatch()
+		for _, path := range testCase.paths {
+			batch.Add(path, false)
+		}
+
+		commands := batch.Commands()
+		if len(commands) != len(testCase.expectedCommands) {
+			t.Fatalf("Unexpected commands in test case %s: expected %v, got %v", testCase.name, testCase.expectedCommands, commands)
+		}
+		for i, command := range commands {
+			if command.Command != testCase.expectedCommands[i] {
+				t.Fatalf("Unexpected command in test case %s: expected %s, got %s", testCase.name, testCase.expectedCommands[i], command.Command)
+			}
+		}
+	}
+
+	// the batches of several uploads are merged into the batch of the client
+	merged := matcher.NewBatch()
+	for _, path := range []string{"/package.json", "/api/a.proto"} {
+		batch := matcher.NewBatch()
+		batch.Add(path, false)
+		merged.Merge(batch)
+	}
+	if commands := merged.Commands(); len(commands) != 2 || commands[0].Command != "npm" || commands[1].Command != "make" {
+		t.Fatalf("Expected merged batch to run npm and make, got %v", commands)
+	}
+
+	var nilMatcher *Matcher
+	batch := nilMatcher.NewBatch()
+	batch.Add("/package.json", false)
+	if len(batch.Commands()) > 0 {
+		t.Fatal("Expected nil matcher to match nothing")
+	}
+
+	_, err = NewMatcher([]Rule{{Command: "npm"}})
+	if err == nil {
+		t.Fatal("Expected error for rule without path")
+	}
+}
+
+func TestBatchExecute(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Commands are executed in linux containers")
+	}
+
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	matcher, err := NewMatcher([]Rule{
+		{
+			Path:    "package.json",
+			Command: "sh",
+			Args:    []string{"-c", "echo x >> executed"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	batch := matcher.NewBatch()
+	batch.Add("/package.json", false)
+	batch.Add("/sub/package.json", false)
+	err = batch.Execute(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the batch is reset after the execution
+	err = batch.Execute(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := ioutil.ReadFile(filepath.Join(dir, "executed"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(out) != "x\n" {
+		t.Fatalf("Expected command to run once within the directory, got %q", string(out))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"github.com/loft-sh/devspace/helper/remote"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/helper/server/permissions"
+	"github.com/loft-sh/devspace/helper/server/uploadrules"
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -14,6 +16,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // UpstreamOptions holds the upstream server options
@@ -21,6 +24,9 @@ type UpstreamOptions struct {
 	UploadPath  string
 	ExludePaths []string
 
+	UseGitignore    bool
+	UseDockerignore bool
+
 	BatchCmd  string
 	BatchArgs []string
 
@@ -32,6 +38,17 @@ type UpstreamOptions struct {
 
 	OverridePermission bool
 	ExitOnClose        bool
+
+	// Symlinks is the symlink mode of the sync, preserved symlinks are reported with their target
+	Symlinks string
+
+	// Permissions are the rules that map the permissions and ownership of the written files
+	Permissions      []permissions.Rule
+	permissionMapper *permissions.Mapper
+
+	// UploadRules are the commands that run once per batch if one of the uploaded paths matches
+	UploadRules       []uploadrules.Rule
+	uploadRuleMatcher *uploadrules.Matcher
 }
 
 // StartUpstreamServer starts a new upstream server with the given reader and writer
@@ -41,13 +58,38 @@ func StartUpstreamServer(reader io.Reader, writer io.Writer, options *UpstreamOp
 	done := make(chan error)
 
 	// Compile ignore paths
-	ignoreMatcher, err := ignoreparser.CompilePaths(options.ExludePaths)
+	excludePaths, err := ignoreparser.MergeIgnoreFiles(options.UploadPath, options.ExludePaths, options.UseGitignore, options.UseDockerignore)
+	if err != nil {
+		return errors.Wrap(err, "load ignore files")
+	}
+
+	ignoreMatcher, err := ignoreparser.CompilePaths(excludePaths)
 	if err != nil {
 		return errors.Wrap(err, "compile paths")
 	}
 
+	// Compile permission rules
+	options.permissionMapper, err = permissions.NewMapper(options.Permissions)
+	if err != nil {
+		return errors.Wrap(err, "compile permissions")
+	}
+
+	// Compile upload rules
+	options.uploadRuleMatcher, err = uploadrules.NewMatcher(options.UploadRules)
+	if err != nil {
+		return errors.Wrap(err, "compile upload rules")
+	}
+
+	// Partial files of uploads that were never resumed and temporary files of killed uploads are removed after a while
+	go removeExpiredUploadFiles(options.UploadPath, ignoreMatcher)
+
 	go func() {
 		s := grpc.NewServer()
+		go func() {
+			// stop the server if the connection is closed, e.g. if it runs within a multiplexed session
+			<-pipe.Closed()
+			s.Stop()
+		}()
 
 		remote.RegisterUpstreamServer(s, &Upstream{
 			options:       options,
@@ -68,6 +110,20 @@ type Upstream struct {
 
 	// ignore matcher is the ignore matcher which matches against excluded files and paths
 	ignoreMatcher ignoreparser.IgnoreParser
+
+	// negotiation is the compression of the uploaded archives
+	negotiation negotiation
+
+	// batch collects the changes of the current batch of the client, its commands are executed
+	// once the client completed the batch. batchChanged is true if the batch contains changes
+	batchMutex   sync.Mutex
+	batch        *uploadrules.Batch
+	batchChanged bool
+}
+
+// Handshake negotiates the compression of the uploaded archives
+func (u *Upstream) Handshake(ctx context.Context, request *remote.HandshakeRequest) (*remote.HandshakeResponse, error) {
+	return u.negotiation.handshake(request), nil
 }
 
 // Ping returns empty
@@ -85,8 +141,43 @@ func (u *Upstream) RestartContainer(context.Context, *remote.Empty) (*remote.Emp
 	return &remote.Empty{}, nil
 }
 
+// BatchDone is called by the client after it uploaded all changes of a batch and executes the batch
+// commands once, if the batch contained changes
+func (u *Upstream) BatchDone(context.Context, *remote.Empty) (*remote.Empty, error) {
+	u.batchMutex.Lock()
+	batch, changed := u.batch, u.batchChanged
+	u.batch, u.batchChanged = nil, false
+	u.batchMutex.Unlock()
+
+	if changed {
+		err := u.executeBatchCommand(batch)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &remote.Empty{}, nil
+}
+
+// addToBatch adds the changes of an upload to the current batch of the client
+func (u *Upstream) addToBatch(batch *uploadrules.Batch) {
+	u.batchMutex.Lock()
+	defer u.batchMutex.Unlock()
+
+	if u.batchChanged == false {
+		u.batch = u.options.uploadRuleMatcher.NewBatch()
+		u.batchChanged = true
+	}
+
+	u.batch.Merge(batch)
+}
+
 // Remove implements the server
 func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
+	// the batch commands are executed after the client completed the batch
+	batch := u.options.uploadRuleMatcher.NewBatch()
+	defer u.addToBatch(batch)
+
 	// Receive file
 	for {
 		paths, err := stream.Recv()
@@ -95,8 +186,8 @@ func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
 				// Just remove everything inside and ignore any errors
 				absolutePath := filepath.Join(u.options.UploadPath, path)
 
-				// Stat the path
-				stat, err := os.Stat(absolutePath)
+				// Stat the path, symlinks are removed without touching their target
+				stat, err := os.Lstat(absolutePath)
 				if err != nil {
 					continue
 				}
@@ -105,17 +196,14 @@ func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
 					_ = u.removeRecursive(absolutePath)
 				} else {
 					_ = os.Remove(absolutePath)
+					removeStalePartialUploads(absolutePath, "")
 				}
+
+				batch.Add(path, stat.IsDir())
 			}
 		}
 
 		if err == io.EOF {
-			// execute a batch command if needed
-			err = u.executeBatchCommand()
-			if err != nil {
-				return err
-			}
-
 			return stream.SendAndClose(&remote.Empty{})
 		}
 		if err != nil {
@@ -124,6 +212,60 @@ func (u *Upstream) Remove(stream remote.Upstream_RemoveServer) error {
 	}
 }
 
+// Stats returns the current state of the requested paths, which is used by the client to detect
+// conflicting changes before it uploads files. Paths that do not exist are returned as delete changes
+func (u *Upstream) Stats(stream remote.Upstream_StatsServer) error {
+	paths := make([]string, 0, 16)
+	for {
+		chunk, err := stream.Recv()
+		if chunk != nil {
+			paths = append(paths, chunk.Paths...)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	changes := make([]*remote.Change, 0, len(paths))
+	for _, path := range paths {
+		stat, linkTarget, err := statPath(filepath.Join(u.options.UploadPath, path), u.options.Symlinks)
+		if err != nil {
+			changes = append(changes, &remote.Change{
+				ChangeType: remote.ChangeType_DELETE,
+				Path:       path,
+			})
+		} else {
+			changes = append(changes, &remote.Change{
+				ChangeType:    remote.ChangeType_CHANGE,
+				Path:          path,
+				MtimeUnix:     stat.ModTime().Unix(),
+				MtimeUnixNano: stat.ModTime().UnixNano(),
+				Size:          stat.Size(),
+				IsDir:         stat.IsDir(),
+				LinkTarget:    linkTarget,
+			})
+		}
+
+		if len(changes) >= 64 {
+			err = stream.Send(&remote.ChangeChunk{Changes: changes})
+			if err != nil {
+				return err
+			}
+
+			changes = make([]*remote.Change, 0, 64)
+		}
+	}
+
+	if len(changes) > 0 {
+		return stream.Send(&remote.ChangeChunk{Changes: changes})
+	}
+
+	return nil
+}
+
 func (u *Upstream) removeRecursive(absolutePath string) error {
 	files, err := ioutil.ReadDir(absolutePath)
 	if err != nil {
@@ -159,8 +301,9 @@ func (u *Upstream) removeRecursive(absolutePath string) error {
 	return nil
 }
 
-// Upload implements the server upload interface and writes all the data received to a
-// temporary file
+// Upload implements the server upload interface and extracts the received tar stream. Each file is
+// written to a temporary file that replaces the target only if its checksum matches, the paths of
+// files that were not applied are returned
 func (u *Upstream) Upload(stream remote.Upstream_UploadServer) error {
 	reader, writer := io.Pipe()
 
@@ -172,23 +315,19 @@ func (u *Upstream) Upload(stream remote.Upstream_UploadServer) error {
 		writerErrChan <- u.writeTar(writer, stream)
 	}()
 
-	err := untarAll(reader, u.options)
-	if err != nil {
-		return errors.Wrap(err, "untar all")
-	}
+	// the batch commands are executed after the client completed the batch
+	codec, _ := u.negotiation.compression()
+	batch := u.options.uploadRuleMatcher.NewBatch()
+	defer u.addToBatch(batch)
 
-	err = <-writerErrChan
+	result := untarAll(reader, codec, u.options, batch)
+	err := <-writerErrChan
 	if err != nil {
 		return errors.Wrap(err, "write tar")
 	}
 
-	// execute a batch command if needed
-	err = u.executeBatchCommand()
-	if err != nil {
-		return err
-	}
-
-	return stream.SendAndClose(&remote.Empty{})
+	// files that were not applied are uploaded again by the client
+	return stream.SendAndClose(result)
 }
 
 func (u *Upstream) writeTar(writer io.WriteCloser, stream remote.Upstream_UploadServer) error {
@@ -219,7 +358,9 @@ func (u *Upstream) writeTar(writer io.WriteCloser, stream remote.Upstream_Upload
 	}
 }
 
-func (u *Upstream) executeBatchCommand() error {
+// executeBatchCommand executes the batch command and the commands of the upload rules that matched
+// a changed path of the batch
+func (u *Upstream) executeBatchCommand(batch *uploadrules.Batch) error {
 	if u.options.BatchCmd != "" {
 		out, err := exec.Command(u.options.BatchCmd, u.options.BatchArgs...).CombinedOutput()
 		if err != nil {
@@ -227,5 +368,5 @@ func (u *Upstream) executeBatchCommand() error {
 		}
 	}
 
-	return nil
+	return batch.Execute(u.options.UploadPath)
 }
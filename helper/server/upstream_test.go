@@ -6,6 +6,8 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"io/ioutil"
 	"log"
@@ -13,8 +15,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"github.com/loft-sh/devspace/helper/server/uploadrules"
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 )
@@ -175,7 +180,7 @@ func TestUpstreamServer(t *testing.T) {
 	tarWriter := tar.NewWriter(gw)
 
 	writtenFiles := make(map[string]bool)
-	err = recursiveTar(fromDir, "", writtenFiles, tarWriter, false)
+	err = recursiveTar(fromDir, "", writtenFiles, tarWriter, nil, false)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -272,3 +277,414 @@ func TestUpstreamServer(t *testing.T) {
 		t.Fatalf("Expected empty toDir, but still has %d entries", len(files))
 	}
 }
+
+func TestUntarChecksum(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+
+	err = ioutil.WriteFile(filepath.Join(toDir, "corrupt.txt"), []byte("old"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gw := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gw)
+	for name, checksum := range map[string]string{
+		"valid.txt":   "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+		"corrupt.txt": "0000000000000000000000000000000000000000000000000000000000000000",
+	} {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name:       "/" + name,
+			Mode:       0644,
+			Size:       4,
+			Format:     tar.FormatPAX,
+			PAXRecords: map[string]string{util.ChecksumPAXRecord: checksum},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tarWriter.Write([]byte("test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tarWriter.Close()
+	gw.Close()
+	w.Close()
+
+	result := untarAll(r, compression.Legacy, &UpstreamOptions{UploadPath: toDir}, nil)
+	if result.Incomplete || len(result.Failed) != 1 || result.Failed[0] != "/corrupt.txt" {
+		t.Fatalf("Expected /corrupt.txt to fail, got %v", result)
+	}
+
+	for name, expected := range map[string]string{"valid.txt": "test", "corrupt.txt": "old"} {
+		data, err := ioutil.ReadFile(filepath.Join(toDir, name))
+		if err != nil {
+			t.Fatal(err)
+		} else if string(data) != expected {
+			t.Fatalf("Expected %s to contain %s, got %s", name, expected, string(data))
+		}
+	}
+
+	// no temporary files are left behind
+	files, err := ioutil.ReadDir(toDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected 2 files in toDir, got %d", len(files))
+	}
+}
+
+func TestUntarTruncated(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the archive breaks off within the second file
+	gw := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gw)
+	for _, file := range []struct{ name, content string }{{"complete.txt", "test"}, {"truncated.txt", "te"}} {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name: "/" + file.name,
+			Mode: 0644,
+			Size: 4,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tarWriter.Write([]byte(file.content))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	gw.Flush()
+	w.Close()
+
+	result := untarAll(r, compression.Legacy, &UpstreamOptions{UploadPath: toDir}, nil)
+	if !result.Incomplete {
+		t.Fatal("Expected the upload to be incomplete")
+	}
+	if len(result.Failed) != 1 || result.Failed[0] != "/truncated.txt" {
+		t.Fatalf("Expected /truncated.txt to fail, got %v", result.Failed)
+	}
+	if len(result.Received) != 2 || result.Received[0] != "/complete.txt" || result.Received[1] != "/truncated.txt" {
+		t.Fatalf("Expected both files to be received, got %v", result.Received)
+	}
+
+	// the truncated file is not written
+	files, err := ioutil.ReadDir(toDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "complete.txt" {
+		t.Fatalf("Expected only complete.txt in toDir, got %d files", len(files))
+	}
+}
+
+func TestUntarSymlink(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+
+	// an existing file is replaced by the symlink
+	err = ioutil.WriteFile(filepath.Join(toDir, "link"), []byte("old"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gw := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gw)
+	for name, target := range map[string]string{"link": "missing/target", "dir/link": "../link"} {
+		err = tarWriter.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     "/" + name,
+			Linkname: target,
+			Mode:     0777,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tarWriter.Close()
+	gw.Close()
+	w.Close()
+
+	result := untarAll(r, compression.Legacy, &UpstreamOptions{UploadPath: toDir}, nil)
+	if result.Incomplete || len(result.Failed) > 0 {
+		t.Fatalf("Untar failed: %v", result)
+	}
+
+	for name, expected := range map[string]string{"link": "missing/target", "dir/link": "../link"} {
+		target, err := os.Readlink(filepath.Join(toDir, name))
+		if err != nil {
+			t.Fatal(err)
+		} else if target != expected {
+			t.Fatalf("Expected %s to point to %s, got %s", name, expected, target)
+		}
+	}
+}
+
+func TestUntarReplacesSymlinks(t *testing.T) {
+	for _, symlinks := range []string{util.SymlinksPreserve, util.SymlinksIgnore} {
+		toDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(toDir)
+
+		outsideDir, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(outsideDir)
+
+		// a symlinked folder within the upload path points outside of it
+		err = os.Symlink(outsideDir, filepath.Join(toDir, "lib"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gw := gzip.NewWriter(w)
+		tarWriter := tar.NewWriter(gw)
+		err = tarWriter.WriteHeader(&tar.Header{
+			Name: "/lib/x",
+			Mode: 0644,
+			Size: 4,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = tarWriter.Write([]byte("test"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tarWriter.Close()
+		gw.Close()
+		w.Close()
+
+		result := untarAll(r, compression.Legacy, &UpstreamOptions{UploadPath: toDir, Symlinks: symlinks}, nil)
+		if result.Incomplete || len(result.Failed) > 0 {
+			t.Fatalf("Untar failed with symlinks %s: %v", symlinks, result)
+		}
+
+		// the symlink is replaced by a folder and the symlink target is not touched
+		stat, err := os.Lstat(filepath.Join(toDir, "lib"))
+		if err != nil {
+			t.Fatal(err)
+		} else if stat.IsDir() == false {
+			t.Fatalf("Expected lib to be replaced by a folder with symlinks %s", symlinks)
+		}
+		_, err = os.Stat(filepath.Join(toDir, "lib", "x"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = os.Stat(filepath.Join(outsideDir, "x"))
+		if err == nil {
+			t.Fatalf("File was written through the symlink with symlinks %s", symlinks)
+		}
+	}
+}
+
+func uploadTestFile(client remote.UpstreamClient, name string, content []byte, size int64) error {
+	uploadClient, err := client.UploadFile(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = uploadClient.Send(&remote.FileChunk{Path: name, MtimeUnix: 1, Size: size, Mode: 0644})
+	if err != nil {
+		return err
+	}
+	_, err = uploadClient.Recv()
+	if err != nil {
+		return err
+	}
+
+	err = uploadClient.Send(&remote.FileChunk{Content: content})
+	if err != nil {
+		return err
+	}
+	if int64(len(content)) == size {
+		checksum := sha256.Sum256(content)
+		err = uploadClient.Send(&remote.FileChunk{Checksum: hex.EncodeToString(checksum[:])})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = uploadClient.CloseSend()
+	if err != nil {
+		return err
+	}
+	for {
+		_, err := uploadClient.Recv()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+func TestBatchDone(t *testing.T) {
+	toDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(toDir)
+	counterDir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(counterDir)
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	defer clientWriter.Close()
+	defer serverWriter.Close()
+
+	counter := filepath.Join(counterDir, "counter")
+	ruleCounter := filepath.Join(counterDir, "rule")
+	go func() {
+		_ = StartUpstreamServer(serverReader, clientWriter, &UpstreamOptions{
+			UploadPath: toDir,
+			BatchCmd:   "sh",
+			BatchArgs:  []string{"-c", "echo >> " + counter},
+			UploadRules: []uploadrules.Rule{
+				{
+					Path:    "/a",
+					Command: "sh",
+					Args:    []string{"-c", "echo >> " + ruleCounter},
+				},
+			},
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := remote.NewUpstreamClient(conn)
+
+	// the partial file of an upload that ended early is discarded
+	err = uploadTestFile(client, "/changed", []byte("test"), 10)
+	if err == nil {
+		t.Fatal("Expected incomplete upload to fail")
+	}
+	files, err := ioutil.ReadDir(toDir)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(files) > 0 {
+		t.Fatalf("Expected partial file to be removed, got %d files", len(files))
+	}
+
+	// the batch commands run once after the client completed the batch
+	for _, name := range []string{"/a", "/b"} {
+		err = uploadTestFile(client, name, []byte("test"), 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	removeClient, err := client.Remove(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = removeClient.Send(&remote.Paths{Paths: []string{"/a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = removeClient.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(counter); err == nil {
+		t.Fatal("Expected batch command not to run before the batch is done")
+	}
+	for i := 0; i < 2; i++ {
+		_, err = client.BatchDone(context.Background(), &remote.Empty{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{counter, ruleCounter} {
+		out, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(out) != "\n" {
+			t.Fatalf("Expected %s to run once, got %q", filepath.Base(name), string(out))
+		}
+	}
+}
+
+func TestRemoveExpiredUploadFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	expired := time.Now().Add(-2 * partialUploadExpiry)
+	for name, mtime := range map[string]time.Time{
+		".expired" + partialUploadMarker + "1-1": expired,
+		".recent" + partialUploadMarker + "1-1":  time.Now(),
+		".expired" + uploadTempMarker + "1":      time.Now().Add(-2 * uploadTempExpiry),
+		".recent" + uploadTempMarker + "1":       time.Now(),
+		"file":                                   expired,
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte("x"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(filepath.Join(dir, name), mtime, mtime)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	removeExpiredUploadFiles(dir, nil)
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remaining := []string{}
+	for _, f := range files {
+		remaining = append(remaining, f.Name())
+	}
+	if len(remaining) != 3 || remaining[0] != ".recent"+partialUploadMarker+"1-1" || remaining[1] != ".recent"+uploadTempMarker+"1" || remaining[2] != "file" {
+		t.Fatalf("Expected only the expired partial and temporary files to be removed, got %v", remaining)
+	}
+}
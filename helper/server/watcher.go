@@ -0,0 +1,19 @@
+package server
+
+import "github.com/pkg/errors"
+
+// errWatchLimit is returned if the kernel limits of the watcher are exhausted
+var errWatchLimit = errors.New("inotify limits exhausted, please increase fs.inotify.max_user_watches or fs.inotify.max_user_instances")
+
+// pathWatcher reports changed paths below a watched directory
+type pathWatcher interface {
+	// Events returns the absolute paths that were changed, created or removed. An empty
+	// path is sent if events were lost and the complete directory has to be rescanned
+	Events() <-chan string
+
+	// Errors returns errors after which the watcher doesn't report changes anymore
+	Errors() <-chan error
+
+	// Close stops the watcher
+	Close() error
+}
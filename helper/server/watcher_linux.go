@@ -0,0 +1,235 @@
+// +build linux
+
+package server
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/pkg/errors"
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_ATTRIB | unix.IN_CLOSE_WRITE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+// inotifyWatcher watches a directory tree with one inotify watch per directory. Excluded directories
+// are not watched, which keeps the amount of watches low for trees like node_modules
+type inotifyWatcher struct {
+	root          string
+	ignoreMatcher ignoreparser.IgnoreParser
+
+	// followSymlinks is false if symlinks are preserved or ignored, symlinked directories are not watched then
+	followSymlinks bool
+
+	fd   int
+	file *os.File
+
+	// watches maps the watch descriptors to the watched directories
+	watches      map[int]string
+	watchesMutex sync.Mutex
+
+	events chan string
+	errors chan error
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// startWatcher starts watching the given directory recursively. An error wrapping errWatchLimit is
+// returned if the kernel limits don't allow to watch all directories
+func startWatcher(root string, ignoreMatcher ignoreparser.IgnoreParser, followSymlinks bool) (pathWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		if err == unix.EMFILE || err == unix.ENFILE {
+			return nil, errors.Wrap(errWatchLimit, err.Error())
+		}
+
+		return nil, errors.Wrap(err, "init inotify")
+	}
+
+	w := &inotifyWatcher{
+		root:           root,
+		ignoreMatcher:  ignoreMatcher,
+		followSymlinks: followSymlinks,
+
+		fd: fd,
+		// a non blocking file is handled by the runtime poller, so close interrupts a pending read
+		file: os.NewFile(uintptr(fd), "inotify"),
+
+		watches: map[int]string{},
+		events:  make(chan string, 1000),
+		errors:  make(chan error, 1),
+		closed:  make(chan struct{}),
+	}
+
+	err = w.addRecursive(root)
+	if err != nil {
+		_ = w.file.Close()
+		return nil, err
+	}
+
+	go w.readEvents()
+	return w, nil
+}
+
+// Events implements the pathWatcher interface
+func (w *inotifyWatcher) Events() <-chan string {
+	return w.events
+}
+
+// Errors implements the pathWatcher interface
+func (w *inotifyWatcher) Errors() <-chan error {
+	return w.errors
+}
+
+// Close implements the pathWatcher interface
+func (w *inotifyWatcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.closed)
+		err = w.file.Close()
+	})
+
+	return err
+}
+
+func (w *inotifyWatcher) addRecursive(path string) error {
+	relativePath := path[len(w.root):]
+	if relativePath != "" && w.ignoreMatcher != nil && w.ignoreMatcher.RequireFullScan() == false && w.ignoreMatcher.Matches(relativePath, true) {
+		return nil
+	}
+
+	wd, err := unix.InotifyAddWatch(w.fd, path, inotifyMask)
+	if err != nil {
+		if err == unix.ENOSPC {
+			return errors.Wrapf(errWatchLimit, "watch %s", path)
+		}
+
+		// the directory was removed in the meantime or cannot be accessed
+		return nil
+	}
+
+	w.watchesMutex.Lock()
+	w.watches[wd] = path
+	w.watchesMutex.Unlock()
+
+	files, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil
+	}
+
+	for _, f := range files {
+		absolutePath := filepath.Join(path, f.Name())
+		if w.followSymlinks == false && f.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+
+		// Stat is necessary here, because readdir does not follow symlinks and
+		// IsDir() returns false for symlinked folders
+		stat, err := os.Stat(absolutePath)
+		if err != nil || stat.IsDir() == false {
+			continue
+		}
+
+		err = w.addRecursive(absolutePath)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeRecursive removes the watches of the directory and all directories below it
+func (w *inotifyWatcher) removeRecursive(path string) {
+	w.watchesMutex.Lock()
+	defer w.watchesMutex.Unlock()
+
+	for wd, watchedPath := range w.watches {
+		if watchedPath == path || strings.HasPrefix(watchedPath, path+"/") {
+			_, _ = unix.InotifyRmWatch(w.fd, uint32(wd))
+			delete(w.watches, wd)
+		}
+	}
+}
+
+func (w *inotifyWatcher) readEvents() {
+	buf := make([]byte, unix.SizeofInotifyEvent*4096)
+	for {
+		n, err := w.file.Read(buf)
+		if err != nil {
+			select {
+			case <-w.closed:
+			default:
+				w.errors <- errors.Wrap(err, "read inotify events")
+			}
+
+			return
+		}
+
+		for offset := 0; offset+unix.SizeofInotifyEvent <= n; {
+			event := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + unix.SizeofInotifyEvent
+			nameEnd := nameStart + int(event.Len)
+			if nameEnd > n {
+				break
+			}
+
+			err = w.handleEvent(event, strings.TrimRight(string(buf[nameStart:nameEnd]), "\x00"))
+			if err != nil {
+				w.errors <- err
+				return
+			}
+
+			offset = nameEnd
+		}
+	}
+}
+
+func (w *inotifyWatcher) handleEvent(event *unix.InotifyEvent, name string) error {
+	if event.Mask&unix.IN_Q_OVERFLOW != 0 {
+		w.send("")
+		return nil
+	}
+
+	w.watchesMutex.Lock()
+	dir, ok := w.watches[int(event.Wd)]
+	if event.Mask&unix.IN_IGNORED != 0 {
+		delete(w.watches, int(event.Wd))
+	}
+	w.watchesMutex.Unlock()
+
+	// events on the watched directory itself are reported by its parent as well
+	if ok == false || name == "" {
+		return nil
+	}
+
+	path := filepath.Join(dir, name)
+	if event.Mask&unix.IN_ISDIR != 0 {
+		// moved directories are watched again at their new location
+		if event.Mask&(unix.IN_MOVED_FROM|unix.IN_DELETE) != 0 {
+			w.removeRecursive(path)
+		}
+		if event.Mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0 {
+			err := w.addRecursive(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	w.send(path)
+	return nil
+}
+
+func (w *inotifyWatcher) send(path string) {
+	select {
+	case w.events <- path:
+	case <-w.closed:
+	}
+}
@@ -0,0 +1,13 @@
+// +build !linux
+
+package server
+
+import (
+	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/pkg/errors"
+)
+
+// startWatcher is only supported on linux, on other platforms the downstream falls back to polling
+func startWatcher(root string, ignoreMatcher ignoreparser.IgnoreParser, followSymlinks bool) (pathWatcher, error) {
+	return nil, errors.New("inotify is not supported on this platform")
+}
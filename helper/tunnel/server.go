@@ -12,6 +12,7 @@ import (
 	"net"
 	"os"
 	"strings"
+	"sync"
 )
 
 type tunnelServer struct{}
@@ -31,6 +32,11 @@ func StartTunnelServer(reader io.Reader, writer io.Writer, exitOnClose bool) err
 
 	go func() {
 		s := grpc.NewServer()
+		go func() {
+			// stop the server if the connection is closed, e.g. if it runs within a multiplexed session
+			<-pipe.Closed()
+			s.Stop()
+		}()
 
 		remote.RegisterTunnelServer(s, NewServer())
 		reflection.Register(s)
@@ -154,6 +160,10 @@ func (t *tunnelServer) InitTunnel(stream remote.Tunnel_InitTunnelServer) error {
 		return errors.New("missing port")
 	}
 
+	if request.GetScheme() == remote.TunnelScheme_UDP {
+		return serveDatagrams(stream, request)
+	}
+
 	ln, err := net.Listen(strings.ToLower(request.GetScheme().String()), fmt.Sprintf(":%d", port))
 	if err != nil {
 		_ = stream.Send(&remote.SocketDataResponse{
@@ -186,3 +196,59 @@ func (t *tunnelServer) InitTunnel(stream remote.Tunnel_InitTunnelServer) error {
 		go readConn(session, sessions)
 	}
 }
+
+// serveDatagrams tunnels the datagrams of a udp port. If the request is a forward request, the client listens
+// and every session is dialed to the port in the container, otherwise the helper listens on the port
+func serveDatagrams(stream remote.Tunnel_InitTunnelServer, request *remote.SocketDataRequest) error {
+	sendMutex := sync.Mutex{}
+	send := func(id uuid.UUID, data []byte, shouldClose bool) error {
+		sendMutex.Lock()
+		defer sendMutex.Unlock()
+
+		return stream.Send(&remote.SocketDataResponse{
+			RequestId:   id.String(),
+			Data:        data,
+			ShouldClose: shouldClose,
+		})
+	}
+	receive := func() (string, []byte, bool, error) {
+		message, err := stream.Recv()
+		if err != nil {
+			return "", nil, false, err
+		}
+
+		return message.GetRequestId(), message.GetData(), message.GetShouldClose(), nil
+	}
+
+	if request.GetForward() {
+		address := fmt.Sprintf("localhost:%d", request.GetPort())
+		return ReceiveDatagrams(receive, func(id uuid.UUID) (*Session, error) {
+			session, err := DialDatagrams(id, address, send)
+			if err != nil {
+				logErrorf("%s; failed dialing %s: %v", id, address, err)
+			}
+
+			return session, err
+		})
+	}
+
+	listener, err := net.ListenPacket("udp", fmt.Sprintf(":%d", request.GetPort()))
+	if err != nil {
+		_ = stream.Send(&remote.SocketDataResponse{
+			HasErr: true,
+			LogMessage: &remote.LogMessage{
+				LogLevel: remote.LogLevel_ERROR,
+				Message:  fmt.Sprintf("failed opening listener type %s on port %d: %v", request.GetScheme(), request.GetPort(), err),
+			},
+		})
+		return fmt.Errorf("failed listening on port %d: %v", request.GetPort(), err)
+	}
+	defer listener.Close()
+
+	go func() {
+		_ = ReceiveDatagrams(receive, nil)
+		_ = listener.Close()
+	}()
+
+	return ListenDatagrams(listener, send)
+}
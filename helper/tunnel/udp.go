@@ -0,0 +1,172 @@
+package tunnel
+
+import (
+	"errors"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UDPIdleTimeout is the time after which a dialed udp session without any datagrams is closed
+var UDPIdleTimeout = time.Minute * 2
+
+// maxDatagramSize is the maximum size of a udp datagram
+const maxDatagramSize = 64 * 1024
+
+// DatagramSender sends a datagram of the session through the tunnel, shouldClose is set if the session was closed
+type DatagramSender func(id uuid.UUID, data []byte, shouldClose bool) error
+
+// DatagramReceiver receives the next datagram of a session from the tunnel
+type DatagramReceiver func() (requestID string, data []byte, shouldClose bool, err error)
+
+// DatagramDialer opens the session with the given id for a datagram that was received for an unknown session
+type DatagramDialer func(id uuid.UUID) (*Session, error)
+
+// peerConn is the connection of a session on a listening udp socket, written datagrams are sent to the peer
+type peerConn struct {
+	net.PacketConn
+
+	addr net.Addr
+}
+
+func (p *peerConn) Read(b []byte) (int, error) {
+	return 0, errors.New("read on udp peer connection")
+}
+
+func (p *peerConn) Write(b []byte) (int, error) {
+	return p.PacketConn.WriteTo(b, p.addr)
+}
+
+func (p *peerConn) RemoteAddr() net.Addr {
+	return p.addr
+}
+
+// Close doesn't close the listener, because it is shared by all sessions
+func (p *peerConn) Close() error {
+	return nil
+}
+
+// ListenDatagrams reads the datagrams of the listener and sends every datagram on its own. Every peer
+// address is a separate session, which lasts until the other side of the tunnel closes it
+func ListenDatagrams(listener net.PacketConn, send DatagramSender) error {
+	peers := map[string]*Session{}
+	defer func() {
+		for _, session := range peers {
+			_, _ = CloseSession(session.Id)
+		}
+	}()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := listener.ReadFrom(buf)
+		if err != nil {
+			return err
+		}
+
+		session, ok := peers[addr.String()]
+		if ok {
+			_, ok = GetSession(session.Id)
+		}
+		if ok == false {
+			// forget the peers whose sessions were closed
+			for key, peer := range peers {
+				if _, exists := GetSession(peer.Id); exists == false {
+					delete(peers, key)
+				}
+			}
+
+			session = NewSession(&peerConn{PacketConn: listener, addr: addr})
+			peers[addr.String()] = session
+		}
+
+		err = send(session.Id, append([]byte{}, buf[:n]...), false)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// dialedConn is the connection of a dialed udp session, it tracks when datagrams were written last
+type dialedConn struct {
+	net.Conn
+
+	lastWrite int64
+}
+
+func (d *dialedConn) Write(b []byte) (int, error) {
+	atomic.StoreInt64(&d.lastWrite, time.Now().UnixNano())
+	return d.Conn.Write(b)
+}
+
+// DialDatagrams opens the udp session with the given id to the address and sends the datagrams received on it.
+// The session is closed if no datagrams were received or written for UDPIdleTimeout
+func DialDatagrams(id uuid.UUID, address string, send DatagramSender) (*Session, error) {
+	conn, err := net.Dial("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	idleTimeout := UDPIdleTimeout
+	dialed := &dialedConn{Conn: conn, lastWrite: time.Now().UnixNano()}
+	session := NewSessionFromStream(id, dialed)
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			_ = conn.SetReadDeadline(time.Now().Add(idleTimeout))
+			n, err := conn.Read(buf)
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() && time.Since(time.Unix(0, atomic.LoadInt64(&dialed.lastWrite))) < idleTimeout {
+				continue
+			} else if err != nil {
+				_, _ = CloseSession(id)
+				_ = send(id, nil, true)
+				return
+			}
+
+			err = send(id, append([]byte{}, buf[:n]...), false)
+			if err != nil {
+				_, _ = CloseSession(id)
+				return
+			}
+		}
+	}()
+
+	return session, nil
+}
+
+// ReceiveDatagrams writes the received datagrams to their sessions until receiving fails. Datagrams of unknown
+// sessions are dropped if dial is nil. Like udp itself, datagrams that cannot be written are dropped
+func ReceiveDatagrams(receive DatagramReceiver, dial DatagramDialer) error {
+	for {
+		requestID, data, shouldClose, err := receive()
+		if err != nil {
+			return err
+		}
+
+		id, err := uuid.Parse(requestID)
+		if err != nil {
+			continue
+		}
+
+		session, ok := GetSession(id)
+		if shouldClose {
+			if ok {
+				_, _ = CloseSession(id)
+			}
+
+			continue
+		} else if ok == false {
+			if dial == nil {
+				continue
+			}
+
+			session, err = dial(id)
+			if err != nil {
+				continue
+			}
+		}
+
+		_, _ = session.Conn.Write(data)
+	}
+}
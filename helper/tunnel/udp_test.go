@@ -0,0 +1,123 @@
+package tunnel
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type datagram struct {
+	id          uuid.UUID
+	data        []byte
+	shouldClose bool
+}
+
+func sendTo(datagrams chan datagram) DatagramSender {
+	return func(id uuid.UUID, data []byte, shouldClose bool) error {
+		datagrams <- datagram{id: id, data: data, shouldClose: shouldClose}
+		return nil
+	}
+}
+
+func receiveFrom(datagrams chan datagram) DatagramReceiver {
+	return func() (string, []byte, bool, error) {
+		d := <-datagrams
+		return d.id.String(), d.data, d.shouldClose, nil
+	}
+}
+
+func TestDatagramTunnel(t *testing.T) {
+	// the target echoes every datagram
+	target, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+	go func() {
+		buf := make([]byte, maxDatagramSize)
+		for {
+			n, addr, err := target.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+
+			_, _ = target.WriteTo(buf[:n], addr)
+		}
+	}()
+
+	listener, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	// connect the listening and the dialing side of the tunnel
+	toDialer := make(chan datagram, 10)
+	toListener := make(chan datagram, 10)
+	go func() {
+		_ = ListenDatagrams(listener, sendTo(toDialer))
+	}()
+	go func() {
+		_ = ReceiveDatagrams(receiveFrom(toListener), nil)
+	}()
+	go func() {
+		_ = ReceiveDatagrams(receiveFrom(toDialer), func(id uuid.UUID) (*Session, error) {
+			return DialDatagrams(id, target.LocalAddr().String(), sendTo(toListener))
+		})
+	}()
+
+	client, err := net.Dial("udp", listener.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	// datagram boundaries are kept
+	for _, message := range []string{"first", "second datagram"} {
+		_, err = client.Write([]byte(message))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		buf := make([]byte, maxDatagramSize)
+		_ = client.SetReadDeadline(time.Now().Add(time.Second * 5))
+		n, err := client.Read(buf)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(buf[:n]) != message {
+			t.Fatalf("Expected %s, got %s", message, string(buf[:n]))
+		}
+	}
+}
+
+func TestDialDatagramsIdleTimeout(t *testing.T) {
+	defer func(timeout time.Duration) {
+		UDPIdleTimeout = timeout
+	}(UDPIdleTimeout)
+	UDPIdleTimeout = time.Millisecond * 100
+
+	target, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.Close()
+
+	datagrams := make(chan datagram, 1)
+	session, err := DialDatagrams(uuid.New(), target.LocalAddr().String(), sendTo(datagrams))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case d := <-datagrams:
+		if d.id != session.Id || d.shouldClose == false {
+			t.Fatalf("Expected close of session %s, got %v", session.Id, d)
+		} else if _, ok := GetSession(session.Id); ok {
+			t.Fatal("Expected idle session to be removed")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("Timed out waiting for idle session to close")
+	}
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+)
+
+// ChecksumPAXRecord is the tar PAX record that carries the FileChecksum of an uploaded file
+const ChecksumPAXRecord = "DEVSPACE.sha256"
+
+// FileChecksum returns the hex encoded sha256 checksum of the file contents at the given path
+func FileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"sync"
 	"time"
 )
 
@@ -35,6 +36,8 @@ type StdStreamJoint struct {
 	remote *StdinAddr
 
 	exitOnClose bool
+	closed      chan struct{}
+	closeOnce   sync.Once
 }
 
 // NewStdStreamJoint is used to implement the connection interface so we can connect to the rpc server
@@ -45,6 +48,7 @@ func NewStdStreamJoint(in io.Reader, out io.Writer, exitOnClose bool) *StdStream
 		in:          in,
 		out:         out,
 		exitOnClose: exitOnClose,
+		closed:      make(chan struct{}),
 	}
 }
 
@@ -76,9 +80,17 @@ func (s *StdStreamJoint) Close() error {
 		os.Exit(1)
 	}
 
+	s.closeOnce.Do(func() {
+		close(s.closed)
+	})
 	return nil
 }
 
+// Closed returns a channel that is closed after the connection was closed
+func (s *StdStreamJoint) Closed() <-chan struct{} {
+	return s.closed
+}
+
 // SetDeadline implements interface
 func (s *StdStreamJoint) SetDeadline(t time.Time) error {
 	return nil
@@ -2,18 +2,24 @@ package util
 
 import (
 	"net"
+	"sync"
+
+	"github.com/pkg/errors"
 )
 
 // NewStdinListener creates a new stdin listener
 func NewStdinListener() *StdinListener {
 	return &StdinListener{
 		connChan: make(chan net.Conn),
+		closed:   make(chan struct{}),
 	}
 }
 
 // StdinListener implements the listener interface
 type StdinListener struct {
-	connChan chan net.Conn
+	connChan  chan net.Conn
+	closed    chan struct{}
+	closeOnce sync.Once
 }
 
 // Ready implements interface
@@ -23,11 +29,19 @@ func (lis *StdinListener) Ready(conn net.Conn) {
 
 // Accept implements interface
 func (lis *StdinListener) Accept() (net.Conn, error) {
-	return <-lis.connChan, nil
+	select {
+	case conn := <-lis.connChan:
+		return conn, nil
+	case <-lis.closed:
+		return nil, errors.New("listener closed")
+	}
 }
 
 // Close implements interface
 func (lis *StdinListener) Close() error {
+	lis.closeOnce.Do(func() {
+		close(lis.closed)
+	})
 	return nil
 }
 
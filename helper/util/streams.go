@@ -0,0 +1,16 @@
+package util
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// Streams returns the input and output of the command and if the process should exit if they are
+// closed. Commands that run within a multiplexed session use a stream of the session instead
+// of the standard streams and must not exit the process
+func Streams(cmd *cobra.Command) (io.Reader, io.Writer, bool) {
+	in := cmd.InOrStdin()
+	return in, cmd.OutOrStdout(), in == os.Stdin
+}
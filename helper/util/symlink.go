@@ -0,0 +1,38 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// List of the modes how the sync handles symlinks
+const (
+	SymlinksFollow   = "follow"
+	SymlinksPreserve = "preserve"
+	SymlinksIgnore   = "ignore"
+)
+
+// SymlinkTempMarker is part of the names of the temporary symlinks created by CreateSymlink
+const SymlinkTempMarker = ".devspace-link-"
+
+// CreateSymlink creates a symlink at path that points to target, the target does not need to exist.
+// An existing file or symlink at path is replaced atomically, an existing directory is not replaced
+func CreateSymlink(target, path string) error {
+	tempPath := filepath.Join(filepath.Dir(path), "."+filepath.Base(path)+SymlinkTempMarker+strconv.FormatInt(time.Now().UnixNano(), 36))
+	err := os.Symlink(target, tempPath)
+	if err != nil {
+		return errors.Wrapf(err, "create symlink %s", path)
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		_ = os.Remove(tempPath)
+		return errors.Wrapf(err, "rename symlink %s", path)
+	}
+
+	return nil
+}
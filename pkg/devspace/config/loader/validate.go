@@ -3,6 +3,7 @@ package loader
 import (
 	"fmt"
 	jsonyaml "github.com/ghodss/yaml"
+	"github.com/loft-sh/devspace/helper/server/permissions"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/helm/merge"
 	"github.com/loft-sh/devspace/pkg/util/log"
@@ -10,6 +11,7 @@ import (
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 	k8sv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"path/filepath"
 	"strings"
 )
@@ -25,6 +27,61 @@ func ValidInitialSyncStrategy(strategy latest.InitialSyncStrategy) bool {
 		strategy == latest.InitialSyncStrategyPreferNewest
 }
 
+// ValidInitialSyncCompareBy checks if compare by is valid
+func ValidInitialSyncCompareBy(compareBy latest.InitialSyncCompareBy) bool {
+	return compareBy == "" ||
+		compareBy == latest.InitialSyncCompareByMTime ||
+		compareBy == latest.InitialSyncCompareBySize ||
+		compareBy == latest.InitialSyncCompareByChecksum
+}
+
+// ValidConflictStrategy checks if the conflict strategy is valid
+func ValidConflictStrategy(strategy latest.ConflictStrategy) bool {
+	return strategy == "" ||
+		strategy == latest.ConflictStrategyPreferLocal ||
+		strategy == latest.ConflictStrategyPreferRemote ||
+		strategy == latest.ConflictStrategyKeepBoth
+}
+
+// ValidSyncSymlinks checks if the symlinks option is valid
+func ValidSyncSymlinks(symlinks latest.SyncSymlinks) bool {
+	return symlinks == "" ||
+		symlinks == latest.SyncSymlinksFollow ||
+		symlinks == latest.SyncSymlinksPreserve ||
+		symlinks == latest.SyncSymlinksIgnore
+}
+
+// ValidPortMappingProtocol checks if the protocol of a port mapping is valid
+func ValidPortMappingProtocol(protocol latest.PortMappingProtocol) bool {
+	return protocol == "" ||
+		protocol == latest.PortMappingProtocolTCP ||
+		protocol == latest.PortMappingProtocolUDP
+}
+
+// ValidSyncCompression checks if the compression and its level are valid
+func ValidSyncCompression(compression latest.SyncCompression, level int) bool {
+	switch compression {
+	case "", latest.SyncCompressionGzip:
+		return level >= 0 && level <= 9
+	case latest.SyncCompressionZstd:
+		return level >= 0 && level <= 22
+	case latest.SyncCompressionNone:
+		return level == 0
+	}
+
+	return false
+}
+
+// ValidMode checks if the mode is empty or an octal permission
+func ValidMode(mode string) bool {
+	if mode == "" {
+		return true
+	}
+
+	_, err := permissions.ParseMode(mode)
+	return err == nil
+}
+
 // ValidContainerArch checks if the target container arch is valid
 func ValidContainerArch(arch latest.ContainerArchitecture) bool {
 	return arch == "" ||
@@ -370,8 +427,14 @@ func validateDev(config *latest.Config) error {
 
 	if config.Dev.Ports != nil {
 		for index, port := range config.Dev.Ports {
-			// Validate imageName and label selector
-			if port.ImageName == "" && len(port.LabelSelector) == 0 && port.ImageSelector == "" {
+			// Validate service, imageName and label selector
+			if port.Service != "" {
+				if port.ImageName != "" || len(port.LabelSelector) > 0 || port.ImageSelector != "" {
+					return errors.Errorf("Error in config: service cannot be used together with imageName, imageSelector or labelSelector in ports config at index %d", index)
+				} else if len(port.PortMappingsReverse) > 0 {
+					return errors.Errorf("Error in config: service cannot be used together with reverseForward in ports config at index %d", index)
+				}
+			} else if port.ImageName == "" && len(port.LabelSelector) == 0 && port.ImageSelector == "" {
 				return errors.Errorf("Error in config: image selector and label selector are nil in ports config at index %d", index)
 			} else if port.ImageName != "" && findImageName(config, port.ImageName) == false {
 				return errors.Errorf("Error in config: dev.ports[%d].imageName '%s' couldn't be found. Please make sure the image name exists under 'images'", index, port.ImageName)
@@ -380,16 +443,45 @@ func validateDev(config *latest.Config) error {
 			if len(port.PortMappings) == 0 && len(port.PortMappingsReverse) == 0 {
 				return errors.Errorf("Error in config: portMappings is empty in port config at index %d", index)
 			}
+			if port.ReverseForwardService != "" {
+				if len(port.PortMappingsReverse) == 0 {
+					return errors.Errorf("Error in config: ports.reverseForwardService needs reverseForward port mappings at index %d", index)
+				} else if errs := validation.IsDNS1035Label(port.ReverseForwardService); len(errs) > 0 {
+					return errors.Errorf("Error in config: ports.reverseForwardService '%s' is not a valid service name at index %d: %s", port.ReverseForwardService, index, strings.Join(errs, ", "))
+				}
+			}
 			if ValidContainerArch(port.Arch) == false {
 				return errors.Errorf("Error in config: ports.arch is not valid '%s' at index %d", port.Arch, index)
 			}
+			for _, portMapping := range port.PortMappings {
+				if portMapping != nil && ValidPortMappingProtocol(portMapping.Protocol) == false {
+					return errors.Errorf("Error in config: ports.forward.protocol is not valid '%s' at index %d", portMapping.Protocol, index)
+				} else if portMapping != nil && port.Service != "" && portMapping.Protocol == latest.PortMappingProtocolUDP {
+					return errors.Errorf("Error in config: service cannot be used together with udp port mappings in ports config at index %d", index)
+				} else if portMapping != nil && portMapping.AllowAlternativePort && portMapping.Protocol == latest.PortMappingProtocolUDP {
+					return errors.Errorf("Error in config: ports.forward.allowAlternativePort cannot be used with udp port mappings at index %d", index)
+				}
+			}
+			for _, portMapping := range port.PortMappingsReverse {
+				if portMapping != nil && ValidPortMappingProtocol(portMapping.Protocol) == false {
+					return errors.Errorf("Error in config: ports.reverseForward.protocol is not valid '%s' at index %d", portMapping.Protocol, index)
+				} else if portMapping != nil && portMapping.AllowAlternativePort {
+					return errors.Errorf("Error in config: ports.reverseForward.allowAlternativePort is not supported at index %d", index)
+				}
+			}
 		}
 	}
 
 	if config.Dev.Sync != nil {
 		for index, sync := range config.Dev.Sync {
 			// Validate imageName and label selector
-			if sync.ImageName == "" && len(sync.LabelSelector) == 0 && sync.ImageSelector == "" {
+			if sync.PersistentVolumeClaim != nil {
+				if sync.PersistentVolumeClaim.Name == "" {
+					return errors.Errorf("Error in config: sync.persistentVolumeClaim.name is required at index %d", index)
+				} else if sync.ImageName != "" || len(sync.LabelSelector) > 0 || sync.ImageSelector != "" || sync.AllContainers {
+					return errors.Errorf("Error in config: sync.persistentVolumeClaim cannot be used together with imageName, imageSelector, labelSelector or allContainers at index %d", index)
+				}
+			} else if sync.ImageName == "" && len(sync.LabelSelector) == 0 && sync.ImageSelector == "" {
 				return errors.Errorf("Error in config: image selector and label selector are nil in sync config at index %d", index)
 			} else if sync.ImageName != "" && findImageName(config, sync.ImageName) == false {
 				return errors.Errorf("Error in config: dev.sync[%d].imageName '%s' couldn't be found. Please make sure the image name exists under 'images'", index, sync.ImageName)
@@ -399,9 +491,48 @@ func validateDev(config *latest.Config) error {
 			if ValidInitialSyncStrategy(sync.InitialSync) == false {
 				return errors.Errorf("Error in config: sync.initialSync is not valid '%s' at index %d", sync.InitialSync, index)
 			}
+			if ValidInitialSyncCompareBy(sync.InitialSyncCompareBy) == false {
+				return errors.Errorf("Error in config: sync.initialSyncCompareBy is not valid '%s' at index %d", sync.InitialSyncCompareBy, index)
+			}
+			if ValidConflictStrategy(sync.ConflictStrategy) == false {
+				return errors.Errorf("Error in config: sync.conflictStrategy is not valid '%s' at index %d", sync.ConflictStrategy, index)
+			}
+			if ValidSyncSymlinks(sync.Symlinks) == false {
+				return errors.Errorf("Error in config: sync.symlinks is not valid '%s' at index %d", sync.Symlinks, index)
+			}
 			if ValidContainerArch(sync.Arch) == false {
 				return errors.Errorf("Error in config: sync.arch is not valid '%s' at index %d", sync.Arch, index)
 			}
+			if ValidSyncCompression(sync.Compression, sync.CompressionLevel) == false {
+				return errors.Errorf("Error in config: sync.compression is not valid '%s' with level %d at index %d", sync.Compression, sync.CompressionLevel, index)
+			}
+			if ValidMode(sync.FileMode) == false {
+				return errors.Errorf("Error in config: sync.fileMode is not valid '%s' at index %d", sync.FileMode, index)
+			}
+			if ValidMode(sync.DirMode) == false {
+				return errors.Errorf("Error in config: sync.dirMode is not valid '%s' at index %d", sync.DirMode, index)
+			}
+			for _, permission := range sync.Permissions {
+				if permission == nil || permission.Path == "" {
+					return errors.Errorf("Error in config: sync.permissions.path is required at index %d", index)
+				}
+				if ValidMode(permission.FileMode) == false {
+					return errors.Errorf("Error in config: sync.permissions.fileMode is not valid '%s' at index %d", permission.FileMode, index)
+				}
+				if ValidMode(permission.DirMode) == false {
+					return errors.Errorf("Error in config: sync.permissions.dirMode is not valid '%s' at index %d", permission.DirMode, index)
+				}
+			}
+			if sync.OnUpload != nil {
+				for _, rule := range sync.OnUpload.Rules {
+					if rule == nil || rule.Path == "" {
+						return errors.Errorf("Error in config: sync.onUpload.rules.path is required at index %d", index)
+					}
+				}
+			}
+			if len(sync.PrimaryLabelSelector) > 0 && sync.AllContainers == false {
+				return errors.Errorf("Error in config: sync.primaryLabelSelector can only be used together with sync.allContainers at index %d", index)
+			}
 		}
 	}
 
@@ -32,6 +32,11 @@ type PredefinedVariableOptions struct {
 	Profile string
 }
 
+// LocalPortVariablePrefix is the prefix of the variables that hold the local port that was used for a forwarded
+// port during the previous run, e.g. DEVSPACE_PORT_8080 holds the alternative port that was used because port 8080
+// was in use
+const LocalPortVariablePrefix = "DEVSPACE_PORT_"
+
 // PredefinedVariableFunction is the definition of a predefined variable
 type PredefinedVariableFunction func(options *PredefinedVariableOptions) (interface{}, error)
 
@@ -149,6 +154,16 @@ func NewPredefinedVariable(name string, cache map[string]string, options *Predef
 			return NewCachedValueVariable(name), nil
 		}
 
+		// Load the local port of a forwarded port, which is the configured port if it was never forwarded
+		// on an alternative port
+		if strings.HasPrefix(name, LocalPortVariablePrefix) {
+			if val, ok := cache[name]; ok {
+				return NewCachedValueVariable(val), nil
+			}
+
+			return NewCachedValueVariable(strings.TrimPrefix(name, LocalPortVariablePrefix)), nil
+		}
+
 		return nil, errors.New("predefined variable " + name + " not found")
 	}
 
@@ -710,11 +710,22 @@ type PortForwardingConfig struct {
 	ContainerName string            `yaml:"containerName,omitempty" json:"containerName,omitempty"`
 	Namespace     string            `yaml:"namespace,omitempty" json:"namespace,omitempty"`
 
+	// Service forwards the ports to the ready endpoints of the service with this name instead of a single pod.
+	// New local connections are distributed across the endpoints round-robin
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+
 	// Target Container architecture to use for the devspacehelper (currently amd64 or arm64). Defaults to amd64
 	Arch ContainerArchitecture `yaml:"arch,omitempty" json:"arch,omitempty"`
 
+	// Multiplex runs the reverse port forwarding tunnel within the multiplexed helper session of the container
+	Multiplex bool `yaml:"multiplex,omitempty" json:"multiplex,omitempty"`
+
 	PortMappings        []*PortMapping `yaml:"forward,omitempty" json:"forward,omitempty"`
 	PortMappingsReverse []*PortMapping `yaml:"reverseForward,omitempty" json:"reverseForward,omitempty"`
+
+	// ReverseForwardService creates a service with this name that exposes the reverse forwarded ports of the
+	// selected pod to the other pods in its namespace. The service is deleted when DevSpace exits
+	ReverseForwardService string `yaml:"reverseForwardService,omitempty" json:"reverseForwardService,omitempty"`
 }
 
 // PortMapping defines the ports for a PortMapping
@@ -722,8 +733,25 @@ type PortMapping struct {
 	LocalPort   *int   `yaml:"port" json:"port"`
 	RemotePort  *int   `yaml:"remotePort,omitempty" json:"remotePort,omitempty"`
 	BindAddress string `yaml:"bindAddress,omitempty" json:"bindAddress,omitempty"`
+
+	// Protocol of the forwarded port, defaults to tcp. Udp datagrams are tunneled through the devspacehelper
+	// in the container in both directions
+	Protocol PortMappingProtocol `yaml:"protocol,omitempty" json:"protocol,omitempty"`
+
+	// AllowAlternativePort forwards on the next free local port if the configured port is already in use. The
+	// used port is available as variable DEVSPACE_PORT_<port> when the config is loaded the next time
+	AllowAlternativePort bool `yaml:"allowAlternativePort,omitempty" json:"allowAlternativePort,omitempty"`
 }
 
+// PortMappingProtocol is the protocol of a forwarded port
+type PortMappingProtocol string
+
+// List of port mapping protocols
+const (
+	PortMappingProtocolTCP PortMappingProtocol = "tcp"
+	PortMappingProtocolUDP PortMappingProtocol = "udp"
+)
+
 // OpenConfig defines what to open after services have been started
 type OpenConfig struct {
 	URL string `yaml:"url,omitempty" json:"url,omitempty"`
@@ -744,14 +772,55 @@ type SyncConfig struct {
 	InitialSync          InitialSyncStrategy  `yaml:"initialSync,omitempty" json:"initialSync,omitempty"`
 	InitialSyncCompareBy InitialSyncCompareBy `yaml:"initialSyncCompareBy,omitempty" json:"initialSyncCompareBy,omitempty"`
 
+	// AllContainers starts the sync for all containers that match the selector instead of a single one. Local changes
+	// are uploaded to every container, while changes are only downloaded from the primary container. Containers that are
+	// started later on are picked up automatically. PrimaryLabelSelector restricts which pods can become the primary,
+	// by default the newest matching container is used
+	AllContainers        bool              `yaml:"allContainers,omitempty" json:"allContainers,omitempty"`
+	PrimaryLabelSelector map[string]string `yaml:"primaryLabelSelector,omitempty" json:"primaryLabelSelector,omitempty"`
+
+	// PersistentVolumeClaim syncs into a persistent volume claim instead of a container. DevSpace starts a helper pod
+	// that mounts the claim and deletes it again when the sync is stopped
+	PersistentVolumeClaim *SyncPersistentVolumeClaim `yaml:"persistentVolumeClaim,omitempty" json:"persistentVolumeClaim,omitempty"`
+
+	// ConflictStrategy defines how a file is resolved that was changed locally and in the container at the
+	// same time after the initial sync. By default the file with the newer modification time is kept
+	ConflictStrategy ConflictStrategy `yaml:"conflictStrategy,omitempty" json:"conflictStrategy,omitempty"`
+
+	// Symlinks defines how symlinks are synced. By default local symlinks are followed and their targets are synced
+	// as regular files and directories, preserve syncs the symlinks themselves and ignore skips them on both sides
+	Symlinks SyncSymlinks `yaml:"symlinks,omitempty" json:"symlinks,omitempty"`
+
+	// UseGitignore excludes the paths that are matched by the .gitignore files within the synced path, UseDockerignore
+	// excludes the paths that are matched by the .dockerignore file in the root of the synced path
+	UseGitignore    bool `yaml:"useGitignore,omitempty" json:"useGitignore,omitempty"`
+	UseDockerignore bool `yaml:"useDockerignore,omitempty" json:"useDockerignore,omitempty"`
+
+	// FileMode, DirMode, Owner and Group are applied to the files and directories that are written by the sync.
+	// Owner and group are only applied inside the container. Permissions override these values for specific paths
+	FileMode    string             `yaml:"fileMode,omitempty" json:"fileMode,omitempty"`
+	DirMode     string             `yaml:"dirMode,omitempty" json:"dirMode,omitempty"`
+	Owner       string             `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Group       string             `yaml:"group,omitempty" json:"group,omitempty"`
+	Permissions []*SyncPermissions `yaml:"permissions,omitempty" json:"permissions,omitempty"`
+
 	DisableDownload *bool `yaml:"disableDownload,omitempty" json:"disableDownload,omitempty"`
 	DisableUpload   *bool `yaml:"disableUpload,omitempty" json:"disableUpload,omitempty"`
 
 	Polling bool `yaml:"polling,omitempty" json:"polling,omitempty"`
 
+	// Multiplex runs the sync within a single helper process per container that serves all multiplexed sync paths,
+	// tunnels and restart requests over a single stream instead of separate exec sessions
+	Multiplex bool `yaml:"multiplex,omitempty" json:"multiplex,omitempty"`
+
 	WaitInitialSync *bool            `yaml:"waitInitialSync,omitempty" json:"waitInitialSync,omitempty"`
 	BandwidthLimits *BandwidthLimits `yaml:"bandwidthLimits,omitempty" json:"bandwidthLimits,omitempty"`
 
+	// Compression of the transferred archives, files that are compressed already are always transferred as they are.
+	// CompressionLevel is specific to the compression, gzip supports 1 - 9 and zstd supports 1 - 22
+	Compression      SyncCompression `yaml:"compression,omitempty" json:"compression,omitempty"`
+	CompressionLevel int             `yaml:"compressionLevel,omitempty" json:"compressionLevel,omitempty"`
+
 	// Target Container architecture to use for the devspacehelper (currently amd64 or arm64). Defaults to amd64
 	Arch ContainerArchitecture `yaml:"arch,omitempty" json:"arch,omitempty"`
 
@@ -762,6 +831,23 @@ type SyncConfig struct {
 	OnDownload *SyncOnDownload `yaml:"onDownload,omitempty" json:"onDownload,omitempty"`
 }
 
+// SyncCompression is the compression of the sync archives
+type SyncCompression string
+
+const (
+	SyncCompressionNone SyncCompression = "none"
+	SyncCompressionGzip SyncCompression = "gzip"
+	SyncCompressionZstd SyncCompression = "zstd"
+)
+
+// SyncPersistentVolumeClaim defines the persistent volume claim a sync should target
+type SyncPersistentVolumeClaim struct {
+	Name string `yaml:"name" json:"name"`
+
+	// Image is the image of the helper pod that mounts the claim, it needs to contain sh and tar. Defaults to alpine
+	Image string `yaml:"image,omitempty" json:"image,omitempty"`
+}
+
 type ContainerArchitecture string
 
 const (
@@ -779,6 +865,18 @@ type SyncOnUpload struct {
 	// Defines what commands should be executed on the container side if a change is uploaded and applied in the target
 	// container
 	ExecRemote *SyncExecCommand `yaml:"execRemote,omitempty" json:"execRemote,omitempty"`
+
+	// Rules run a command in the container once per batch of uploaded changes if one of the changed paths matches
+	// the path of the rule. Each changed path is only matched by the first matching rule
+	Rules []*SyncOnUploadRule `yaml:"rules,omitempty" json:"rules,omitempty"`
+}
+
+// SyncOnUploadRule defines a command that is executed within the container path after a batch of changes that contains
+// a path matching the pattern. A rule without a command runs nothing, but excludes the matching paths from later rules
+type SyncOnUploadRule struct {
+	Path    string   `yaml:"path" json:"path"`
+	Command string   `yaml:"command,omitempty" json:"command,omitempty"`
+	Args    []string `yaml:"args,omitempty" json:"args,omitempty"`
 }
 
 // SyncOnDownload defines the struct for the command that should be executed when files / folders are downloaded
@@ -829,10 +927,40 @@ type InitialSyncCompareBy string
 
 // List of values that compare by can take
 const (
-	InitialSyncCompareByMTime InitialSyncCompareBy = "mtime"
-	InitialSyncCompareBySize  InitialSyncCompareBy = "size"
+	InitialSyncCompareByMTime    InitialSyncCompareBy = "mtime"
+	InitialSyncCompareBySize     InitialSyncCompareBy = "size"
+	InitialSyncCompareByChecksum InitialSyncCompareBy = "checksum"
+)
+
+// ConflictStrategy is the type of how a conflicting change should be resolved
+type ConflictStrategy string
+
+// List of values that conflict strategy can take
+const (
+	ConflictStrategyPreferLocal  ConflictStrategy = "preferLocal"
+	ConflictStrategyPreferRemote ConflictStrategy = "preferRemote"
+	ConflictStrategyKeepBoth     ConflictStrategy = "keepBoth"
 )
 
+// SyncSymlinks is the type of how symlinks are synced
+type SyncSymlinks string
+
+// List of values that symlinks can take
+const (
+	SyncSymlinksFollow   SyncSymlinks = "follow"
+	SyncSymlinksPreserve SyncSymlinks = "preserve"
+	SyncSymlinksIgnore   SyncSymlinks = "ignore"
+)
+
+// SyncPermissions defines the permissions and ownership of the synced files and directories that match the path
+type SyncPermissions struct {
+	Path     string `yaml:"path" json:"path"`
+	FileMode string `yaml:"fileMode,omitempty" json:"fileMode,omitempty"`
+	DirMode  string `yaml:"dirMode,omitempty" json:"dirMode,omitempty"`
+	Owner    string `yaml:"owner,omitempty" json:"owner,omitempty"`
+	Group    string `yaml:"group,omitempty" json:"group,omitempty"`
+}
+
 // BandwidthLimits defines the struct for specifying the sync bandwidth limits
 type BandwidthLimits struct {
 	Download *int64 `yaml:"download,omitempty" json:"download,omitempty"`
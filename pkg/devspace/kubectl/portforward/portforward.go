@@ -27,6 +27,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/httpstream"
@@ -54,6 +55,11 @@ type PortForwarder struct {
 	requestID     int
 	out           io.Writer
 	errOut        io.Writer
+
+	// failedConnections holds the remote addresses of the local connections that couldn't be forwarded
+	// to the pod and when they failed
+	failedConnectionsLock sync.Mutex
+	failedConnections     map[string]time.Time
 }
 
 // ForwardedPort contains a Local:Remote port pairing.
@@ -186,9 +192,61 @@ func NewOnAddresses(dialer httpstream.Dialer, addresses []string, ports []string
 	}, nil
 }
 
+// ErrLostConnection is raised if the connection to the pod was closed
+var ErrLostConnection = errors.New("lost connection to pod")
+
+// streamError is raised if a stream for a forwarded connection couldn't be created
+type streamError struct {
+	error
+}
+
+// IsConnectionError returns true if the raised error means that the connection to the pod
+// is broken and no connection can be forwarded anymore
+func IsConnectionError(err error) bool {
+	_, ok := err.(*streamError)
+	return ok || err == ErrLostConnection
+}
+
+// failedConnectionExpiry is the time a failed connection is remembered for ConnectionFailed
+const failedConnectionExpiry = time.Minute
+
+// connectionFailed remembers that the local connection couldn't be forwarded to the pod
+func (pf *PortForwarder) connectionFailed(conn net.Conn) {
+	pf.failedConnectionsLock.Lock()
+	defer pf.failedConnectionsLock.Unlock()
+
+	if pf.failedConnections == nil {
+		pf.failedConnections = map[string]time.Time{}
+	}
+	for addr, failed := range pf.failedConnections {
+		if time.Since(failed) > failedConnectionExpiry {
+			delete(pf.failedConnections, addr)
+		}
+	}
+
+	pf.failedConnections[conn.RemoteAddr().String()] = time.Now()
+}
+
+// ConnectionFailed returns true if the local connection from the given address couldn't be forwarded to the pod,
+// because the streams to the pod couldn't be created or the pod reported an error, e.g. because nothing listens on
+// the remote port. The failure is recorded before the connection is closed
+func (pf *PortForwarder) ConnectionFailed(localAddr string) bool {
+	pf.failedConnectionsLock.Lock()
+	defer pf.failedConnectionsLock.Unlock()
+
+	_, ok := pf.failedConnections[localAddr]
+	delete(pf.failedConnections, localAddr)
+	return ok
+}
+
+// raiseError sends the error to the error channel. Errors are dropped after stopChan
+// was closed, because nobody may be receiving them anymore
 func (pf *PortForwarder) raiseError(err error) {
 	if pf.errChan != nil {
-		pf.errChan <- err
+		select {
+		case pf.errChan <- err:
+		case <-pf.stopChan:
+		}
 	}
 }
 
@@ -239,7 +297,7 @@ func (pf *PortForwarder) forward() error {
 	select {
 	case <-pf.stopChan:
 	case <-pf.streamConn.CloseChan():
-		pf.raiseError(errors.New("lost connection to pod"))
+		pf.raiseError(ErrLostConnection)
 	}
 
 	return nil
@@ -346,7 +404,8 @@ func (pf *PortForwarder) handleConnection(conn net.Conn, port ForwardedPort) {
 	headers.Set(v1.PortForwardRequestIDHeader, strconv.Itoa(requestID))
 	errorStream, err := pf.streamConn.CreateStream(headers)
 	if err != nil {
-		pf.raiseError(fmt.Errorf("error creating error stream for port %d -> %d: %v", port.Local, port.Remote, err))
+		pf.connectionFailed(conn)
+		pf.raiseError(&streamError{fmt.Errorf("error creating error stream for port %d -> %d: %v", port.Local, port.Remote, err)})
 		// runtime.HandleError(fmt.Errorf("error creating error stream for port %d -> %d: %v", port.Local, port.Remote, err))
 		return
 	}
@@ -369,7 +428,8 @@ func (pf *PortForwarder) handleConnection(conn net.Conn, port ForwardedPort) {
 	headers.Set(v1.StreamType, v1.StreamTypeData)
 	dataStream, err := pf.streamConn.CreateStream(headers)
 	if err != nil {
-		pf.raiseError(fmt.Errorf("error creating forwarding stream for port %d -> %d: %v", port.Local, port.Remote, err))
+		pf.connectionFailed(conn)
+		pf.raiseError(&streamError{fmt.Errorf("error creating forwarding stream for port %d -> %d: %v", port.Local, port.Remote, err)})
 		// runtime.HandleError(fmt.Errorf("error creating forwarding stream for port %d -> %d: %v", port.Local, port.Remote, err))
 		return
 	}
@@ -410,6 +470,7 @@ func (pf *PortForwarder) handleConnection(conn net.Conn, port ForwardedPort) {
 	// always expect something on errorChan (it may be nil)
 	err = <-errorChan
 	if err != nil {
+		pf.connectionFailed(conn)
 		pf.raiseError(err)
 		// runtime.HandleError(err)
 	}
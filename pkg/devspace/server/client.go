@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/services/synccontroller"
+	"github.com/pkg/errors"
+)
+
+// FindServers returns the addresses of the running ui servers on the given host. If no port
+// is specified, the ports that are used by default are searched
+func FindServers(host string, port int) ([]string, error) {
+	if port != 0 {
+		address := fmt.Sprintf("http://%s:%d", host, port)
+		err := checkServer(address)
+		if err != nil {
+			return nil, errors.Wrapf(err, "check ui server at %s", address)
+		}
+
+		return []string{address}, nil
+	}
+
+	addresses := []string{}
+	for i := 0; i < 20; i++ {
+		address := fmt.Sprintf("http://%s:%d", host, DefaultPort+i)
+		if checkServer(address) == nil {
+			addresses = append(addresses, address)
+		}
+	}
+
+	return addresses, nil
+}
+
+// GetSyncStatus retrieves the status of the syncs from the ui server at the given address
+func GetSyncStatus(address string) ([]synccontroller.Status, error) {
+	statuses := []synccontroller.Status{}
+	err := get(&http.Client{Timeout: time.Second * 5}, address+"/api/sync", &statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+// WaitForSync blocks until the syncs of the ui server at the given address have uploaded all
+// local changes that were observed before the call. A timeout of 0 waits indefinitely
+func WaitForSync(address string, timeout time.Duration) ([]synccontroller.Status, error) {
+	requestURL := address + "/api/sync/wait"
+	if timeout > 0 {
+		requestURL += "?timeout=" + url.QueryEscape(timeout.String())
+	}
+
+	statuses := []synccontroller.Status{}
+	err := get(&http.Client{}, requestURL, &statuses)
+	if err != nil {
+		return nil, err
+	}
+
+	return statuses, nil
+}
+
+func checkServer(address string) error {
+	serverVersion := &UIServerVersion{}
+	err := get(&http.Client{Timeout: time.Second * 5}, address+"/api/version", serverVersion)
+	if err != nil {
+		return err
+	} else if serverVersion.DevSpace == false {
+		return errors.Errorf("%s is not a devspace ui server", address)
+	}
+
+	return nil
+}
+
+func get(client *http.Client, requestURL string, out interface{}) error {
+	response, err := client.Get(requestURL)
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return errors.Wrap(err, "read response")
+	} else if response.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status code %d: %s", response.StatusCode, strings.TrimSpace(string(contents)))
+	}
+
+	return json.Unmarshal(contents, out)
+}
@@ -180,6 +180,10 @@ func newHandler(config config.Config, dependencies []types.Dependency, defaultCo
 	handler.mux.HandleFunc("/api/resize", handler.resize)
 	handler.mux.HandleFunc("/api/logs", handler.logs)
 	handler.mux.HandleFunc("/api/logs-multiple", handler.logsMultiple)
+	handler.mux.HandleFunc("/api/sync", handler.syncStatus)
+	handler.mux.HandleFunc("/api/sync/conflicts", handler.syncConflicts)
+	handler.mux.HandleFunc("/api/sync/wait", handler.syncWait)
+	handler.mux.HandleFunc("/api/sync/progress", handler.syncProgress)
 	return handler, nil
 }
 
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/services/synccontroller"
+)
+
+func (h *handler) syncStatus(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(synccontroller.Statuses())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+func (h *handler) syncConflicts(w http.ResponseWriter, r *http.Request) {
+	b, err := json.Marshal(synccontroller.Conflicts())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
+
+// syncProgress streams the progress events of long running uploads and downloads as json messages
+func (h *handler) syncProgress(w http.ResponseWriter, r *http.Request) {
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.log.Errorf("Error upgrading connection: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer ws.Close()
+
+	events, unsubscribe := synccontroller.SubscribeProgress()
+	defer unsubscribe()
+
+	// the client doesn't send anything, so a read only returns if the connection was closed
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := ws.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			err := ws.WriteJSON(event)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// syncWait blocks until all local changes that were observed before the request are uploaded
+func (h *handler) syncWait(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	timeout, ok := r.URL.Query()["timeout"]
+	if ok && len(timeout) == 1 && timeout[0] != "" {
+		duration, err := time.ParseDuration(timeout[0])
+		if err != nil {
+			http.Error(w, "timeout is invalid: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	err := synccontroller.WaitForUploads(ctx)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.syncStatus(w, r)
+}
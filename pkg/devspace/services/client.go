@@ -6,9 +6,11 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/services/podreplace"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	"io"
+	gosync "sync"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
 	"github.com/loft-sh/devspace/pkg/util/log"
 )
 
@@ -21,9 +23,11 @@ type Client interface {
 
 	StartPortForwarding(interrupt chan error) error
 	StartReversePortForwarding(interrupt chan error) error
+	LocalPorts() map[int]int
 	StartSync(interrupt chan error, printSyncLog bool, verboseSync bool) error
 
 	StartSyncFromCmd(options targetselector.Options, syncConfig *latest.SyncConfig, interrupt chan error, verbose bool) error
+	DryRunSyncFromCmd(options targetselector.Options, syncConfig *latest.SyncConfig, verbose bool) (*sync.Diff, error)
 	StartTerminal(options targetselector.Options, args []string, workDir string, interrupt chan error, wait bool) (int, error)
 
 	ReplacePods() error
@@ -36,6 +40,9 @@ type client struct {
 	podReplacer podreplace.PodReplacer
 	client      kubectl.Client
 	log         log.Logger
+
+	localPortsMutex gosync.Mutex
+	localPorts      map[int]int
 }
 
 // NewClient creates a new client object
@@ -0,0 +1,121 @@
+package inject
+
+import (
+	"io"
+	"sync"
+
+	"github.com/loft-sh/devspace/helper/mux"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+)
+
+// multiplexedSession is a helper session with the amount of streams that currently run on it
+type multiplexedSession struct {
+	*mux.Session
+
+	streams int
+	close   func()
+}
+
+// sessions holds the multiplexed helper sessions by container
+var (
+	sessions      = map[string]*multiplexedSession{}
+	sessionsMutex sync.Mutex
+)
+
+// startSessionStream starts the helper process that serves a multiplexed session
+var startSessionStream = StartStream
+
+// StartMultiplexedStream runs the helper command within the multiplexed helper session of the container
+// instead of a separate exec session. The session is started on first use and is shared by all sync paths,
+// tunnels and restart requests of the container, which reduces the amount of long-lived exec streams. The
+// session is closed after its last stream ended
+func StartMultiplexedStream(client kubectl.Client, pod *v1.Pod, container string, command []string, reader io.Reader, writer io.Writer) error {
+	if len(command) == 0 || command[0] != DevSpaceHelperContainerPath {
+		return errors.Errorf("cannot multiplex command %v, only devspace helper commands are supported", command)
+	}
+
+	key := sessionKey(pod, container)
+	session, err := acquireSession(key, client, pod, container)
+	if err != nil {
+		return err
+	}
+	defer releaseSession(key, session)
+
+	return session.Exec(command[1:], reader, writer)
+}
+
+// acquireSession returns the running session of the container or starts a new one and counts the stream
+// that will run on it
+func acquireSession(key string, client kubectl.Client, pod *v1.Pod, container string) (*multiplexedSession, error) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+
+	if session, ok := sessions[key]; ok {
+		select {
+		case <-session.Closed():
+		default:
+			session.streams++
+			return session, nil
+		}
+	}
+
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	muxSession, err := mux.NewSession(stdoutReader, stdinWriter)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &multiplexedSession{
+		Session: muxSession,
+		streams: 1,
+		close: func() {
+			// the helper exits after its stdin is closed
+			_ = stdinWriter.Close()
+			_ = stdoutReader.Close()
+		},
+	}
+
+	go func() {
+		err := startSessionStream(client, pod, container, []string{DevSpaceHelperContainerPath, "mux"}, stdinReader, stdoutWriter)
+		if err == nil {
+			err = errors.New("helper exited")
+		}
+
+		// closing the pipes closes the session and all of its streams
+		_ = stdoutWriter.CloseWithError(err)
+		_ = stdinReader.CloseWithError(err)
+
+		sessionsMutex.Lock()
+		defer sessionsMutex.Unlock()
+		if sessions[key] == session {
+			delete(sessions, key)
+		}
+	}()
+
+	sessions[key] = session
+	return session, nil
+}
+
+// releaseSession removes a stream from the session and closes the session if it was the last one
+func releaseSession(key string, session *multiplexedSession) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+
+	session.streams--
+	if session.streams > 0 {
+		return
+	}
+
+	if sessions[key] == session {
+		delete(sessions, key)
+	}
+
+	session.close()
+}
+
+func sessionKey(pod *v1.Pod, container string) string {
+	return pod.Namespace + "/" + pod.Name + "/" + string(pod.UID) + "/" + container
+}
@@ -0,0 +1,99 @@
+package inject
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/mux"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMultiplexedSessionRefCount(t *testing.T) {
+	defer func(start func(kubectl.Client, *v1.Pod, string, []string, io.Reader, io.Writer) error) {
+		startSessionStream = start
+	}(startSessionStream)
+
+	started := 0
+	exited := make(chan struct{}, 2)
+	release := make(chan struct{})
+	startSessionStream = func(client kubectl.Client, pod *v1.Pod, container string, command []string, reader io.Reader, writer io.Writer) error {
+		started++
+		defer func() {
+			exited <- struct{}{}
+		}()
+
+		return mux.Serve(reader, writer, func(args []string, stream io.ReadWriter) error {
+			if args[0] == "wait" {
+				<-release
+			}
+
+			_, err := stream.Write([]byte(args[0]))
+			return err
+		})
+	}
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test"}}
+	run := func(command string, output *bytes.Buffer) error {
+		return StartMultiplexedStream(nil, pod, "container", []string{DevSpaceHelperContainerPath, command}, strings.NewReader(""), output)
+	}
+
+	// both streams share one session
+	waitOutput := &bytes.Buffer{}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = run("wait", waitOutput)
+	}()
+
+	for {
+		sessionsMutex.Lock()
+		running := len(sessions)
+		sessionsMutex.Unlock()
+		if running > 0 {
+			break
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	output := &bytes.Buffer{}
+	err := run("echo", output)
+	if err != nil {
+		t.Fatal(err)
+	} else if output.String() != "echo" {
+		t.Fatalf("Unexpected output %s", output.String())
+	}
+
+	select {
+	case <-exited:
+		t.Fatal("Session was closed while a stream was still running")
+	default:
+	}
+
+	// the session is closed after the last stream ended
+	close(release)
+	wg.Wait()
+	if waitOutput.String() != "wait" {
+		t.Fatalf("Unexpected output %s", waitOutput.String())
+	}
+
+	select {
+	case <-exited:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Session was not closed after the last stream ended")
+	}
+
+	sessionsMutex.Lock()
+	running := len(sessions)
+	sessionsMutex.Unlock()
+	if running != 0 || started != 1 {
+		t.Fatalf("Expected one closed session, got %d started and %d running", started, running)
+	}
+}
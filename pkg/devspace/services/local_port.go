@@ -0,0 +1,106 @@
+package services
+
+import (
+	"strconv"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/loader/variable"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/port"
+)
+
+// maxAlternativePorts is the number of ports after a busy local port that are tried as alternative port
+const maxAlternativePorts = 100
+
+// checkPort is used to check if a local port is free
+var checkPort = port.Check
+
+// LocalPorts returns the local ports that are used for the port mappings with allowAlternativePort by configured port
+func (serviceClient *client) LocalPorts() map[int]int {
+	serviceClient.localPortsMutex.Lock()
+	defer serviceClient.localPortsMutex.Unlock()
+
+	localPorts := map[int]int{}
+	for configured, used := range serviceClient.localPorts {
+		localPorts[configured] = used
+	}
+
+	return localPorts
+}
+
+// localPort returns the local port the port mapping is forwarded on. If the configured port is already in use and
+// the port mapping allows an alternative port, the next free port is chosen. The chosen port is kept for reconnects
+// and recorded in the generated config, where it is available as variable DEVSPACE_PORT_<port>
+func (serviceClient *client) localPort(portMapping *latest.PortMapping, log logpkg.Logger) int {
+	configured := *portMapping.LocalPort
+	if portMapping.AllowAlternativePort == false {
+		open, _ := checkPort(configured)
+		if open == false {
+			log.Warnf("Seems like port %d is already in use. Is another application using that port?", configured)
+		}
+
+		return configured
+	}
+
+	serviceClient.localPortsMutex.Lock()
+	defer serviceClient.localPortsMutex.Unlock()
+
+	if serviceClient.localPorts == nil {
+		serviceClient.localPorts = map[int]int{}
+	} else if used, ok := serviceClient.localPorts[configured]; ok {
+		return used
+	}
+
+	used := configured
+	if open, _ := checkPort(configured); open == false {
+		used = serviceClient.alternativePort(configured)
+		if used == configured {
+			log.Warnf("Port %d is already in use and no free alternative port was found", configured)
+		} else {
+			log.Warnf("Port %d is already in use, forwarding on port %d instead", configured, used)
+		}
+	}
+
+	serviceClient.localPorts[configured] = used
+	if serviceClient.config != nil && serviceClient.config.Generated() != nil {
+		generatedConfig := serviceClient.config.Generated()
+		if generatedConfig.Vars == nil {
+			generatedConfig.Vars = map[string]string{}
+		}
+
+		generatedConfig.Vars[variable.LocalPortVariablePrefix+strconv.Itoa(configured)] = strconv.Itoa(used)
+	}
+
+	return used
+}
+
+// alternativePort returns the next free port after the configured one, that is neither configured nor
+// chosen for another port mapping. The configured port is returned if there is none
+func (serviceClient *client) alternativePort(configured int) int {
+	reserved := map[int]bool{}
+	for _, used := range serviceClient.localPorts {
+		reserved[used] = true
+	}
+	if serviceClient.config != nil && serviceClient.config.Config() != nil {
+		for _, portForwarding := range serviceClient.config.Config().Dev.Ports {
+			for _, portMapping := range portForwarding.PortMappings {
+				if portMapping.LocalPort != nil {
+					reserved[*portMapping.LocalPort] = true
+				}
+			}
+		}
+	}
+
+	for candidate := configured + 1; candidate <= configured+maxAlternativePorts && candidate <= 65535; candidate++ {
+		if reserved[candidate] {
+			continue
+		}
+
+		open, _ := checkPort(candidate)
+		if open {
+			return candidate
+		}
+	}
+
+	return configured
+}
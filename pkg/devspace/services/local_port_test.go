@@ -0,0 +1,57 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config"
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"gotest.tools/assert"
+)
+
+func TestLocalPort(t *testing.T) {
+	defer func(check func(int) (bool, error)) {
+		checkPort = check
+	}(checkPort)
+
+	busy := map[int]bool{8080: true, 8081: true, 9000: true}
+	checkPort = func(port int) (bool, error) {
+		return busy[port] == false, nil
+	}
+
+	alternative := &latest.PortMapping{LocalPort: ptr.Int(8080), AllowAlternativePort: true}
+	fixed := &latest.PortMapping{LocalPort: ptr.Int(9000)}
+	free := &latest.PortMapping{LocalPort: ptr.Int(3000), AllowAlternativePort: true}
+	generatedConfig := generated.New()
+	client := &client{
+		config: config.NewConfig(nil, &latest.Config{
+			Dev: latest.DevConfig{
+				Ports: []*latest.PortForwardingConfig{
+					{
+						PortMappings: []*latest.PortMapping{alternative, free, fixed},
+					},
+					{
+						PortMappings: []*latest.PortMapping{{LocalPort: ptr.Int(8082)}},
+					},
+				},
+			},
+		}, generatedConfig, nil),
+	}
+
+	// busy ports and ports of other port mappings are skipped
+	assert.Equal(t, 8083, client.localPort(alternative, log.Discard), "Wrong alternative port")
+	assert.Equal(t, 3000, client.localPort(free, log.Discard), "Wrong free port")
+	assert.Equal(t, 9000, client.localPort(fixed, log.Discard), "Port without allowAlternativePort changed")
+
+	// the chosen port is kept for reconnects
+	delete(busy, 8080)
+	assert.Equal(t, 8083, client.localPort(alternative, log.Discard), "Alternative port not kept")
+
+	assert.DeepEqual(t, map[int]int{8080: 8083, 3000: 3000}, client.LocalPorts())
+	assert.Equal(t, "8083", generatedConfig.Vars["DEVSPACE_PORT_8080"], "Alternative port not recorded")
+	assert.Equal(t, "3000", generatedConfig.Vars["DEVSPACE_PORT_3000"], "Free port not recorded")
+	_, ok := generatedConfig.Vars["DEVSPACE_PORT_9000"]
+	assert.Equal(t, false, ok, "Port without allowAlternativePort recorded")
+}
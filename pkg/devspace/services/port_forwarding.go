@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
 	"github.com/loft-sh/devspace/pkg/devspace/deploy/deployer/util"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl/portforward"
+	"github.com/loft-sh/devspace/pkg/devspace/tunnel"
 	"github.com/loft-sh/devspace/pkg/util/imageselector"
 	"strconv"
 	"strings"
@@ -14,8 +16,8 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
-	"github.com/loft-sh/devspace/pkg/util/port"
 	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 )
 
 // StartPortForwarding starts the port forwarding functionality
@@ -30,17 +32,45 @@ func (serviceClient *client) StartPortForwarding(interrupt chan error) error {
 			continue
 		}
 
+		// forward to the endpoints of a service
+		if portForwarding.Service != "" {
+			err := serviceClient.startServiceForwarding(cache, portForwarding, interrupt, serviceClient.log)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
 		// start port forwarding
-		err := serviceClient.startForwarding(cache, portForwarding, interrupt, serviceClient.log)
-		if err != nil {
-			return err
+		if len(tcpPortMappings(portForwarding.PortMappings)) > 0 {
+			err := serviceClient.startForwarding(cache, portForwarding, interrupt, serviceClient.log)
+			if err != nil {
+				return err
+			}
+		}
+
+		// udp ports are forwarded through the devspace helper
+		if len(udpPortMappings(portForwarding.PortMappings)) > 0 {
+			err := serviceClient.startUDPForwarding(cache, portForwarding, interrupt, serviceClient.log)
+			if err != nil {
+				return err
+			}
 		}
 	}
 
 	return nil
 }
 
+// startForwarding forwards the tcp ports of the port forwarding config and reconnects the forwarding if it breaks
 func (serviceClient *client) startForwarding(cache *generated.CacheConfig, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	return serviceClient.startSupervised("Port-Forwarding", "portforwarding", interrupt, log, func(log logpkg.Logger) (*forwarding, error) {
+		return serviceClient.startPortForwarder(portForwarding, log)
+	})
+}
+
+// startPortForwarder selects the pod of the port forwarding config and forwards the tcp ports to it
+func (serviceClient *client) startPortForwarder(portForwarding *latest.PortForwardingConfig, log logpkg.Logger) (*forwarding, error) {
 	var err error
 
 	// apply config & set image selector
@@ -49,14 +79,14 @@ func (serviceClient *client) startForwarding(cache *generated.CacheConfig, portF
 	options.ImageSelector = []imageselector.ImageSelector{}
 	imageSelector, err := imageselector.Resolve(portForwarding.ImageName, serviceClient.config, serviceClient.dependencies)
 	if err != nil {
-		return err
+		return nil, err
 	} else if imageSelector != nil {
 		options.ImageSelector = append(options.ImageSelector, *imageSelector)
 	}
 	if portForwarding.ImageSelector != "" {
 		imageSelector, err := util.ResolveImageAsImageSelector(portForwarding.ImageSelector, serviceClient.config, serviceClient.dependencies)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		options.ImageSelector = append(options.ImageSelector, *imageSelector)
@@ -69,29 +99,25 @@ func (serviceClient *client) startForwarding(cache *generated.CacheConfig, portF
 	pod, err := targetselector.NewTargetSelector(serviceClient.client).SelectSinglePod(context.TODO(), options, log)
 	log.StopWait()
 	if err != nil {
-		return errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
+		return nil, errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
 	} else if pod == nil {
-		return nil
+		return nil, nil
 	}
 
-	ports := make([]string, len(portForwarding.PortMappings))
-	addresses := make([]string, len(portForwarding.PortMappings))
-	for index, value := range portForwarding.PortMappings {
+	portMappings := tcpPortMappings(portForwarding.PortMappings)
+	ports := make([]string, len(portMappings))
+	addresses := make([]string, len(portMappings))
+	for index, value := range portMappings {
 		if value.LocalPort == nil {
-			return errors.Errorf("port is not defined in portmapping %d", index)
+			return nil, errors.Errorf("port is not defined in portmapping %d", index)
 		}
 
-		localPort := strconv.Itoa(*value.LocalPort)
-		remotePort := localPort
+		localPort := strconv.Itoa(serviceClient.localPort(value, log))
+		remotePort := strconv.Itoa(*value.LocalPort)
 		if value.RemotePort != nil {
 			remotePort = strconv.Itoa(*value.RemotePort)
 		}
 
-		open, _ := port.Check(*value.LocalPort)
-		if open == false {
-			serviceClient.log.Warnf("Seems like port %d is already in use. Is another application using that port?", *value.LocalPort)
-		}
-
 		ports[index] = localPort + ":" + remotePort
 		if value.BindAddress == "" {
 			addresses[index] = "localhost"
@@ -100,53 +126,115 @@ func (serviceClient *client) startForwarding(cache *generated.CacheConfig, portF
 		}
 	}
 
+	_, forwarding, err := serviceClient.forwardPorts(pod, ports, addresses)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Donef("Port forwarding started on %s (%s/%s)", strings.Join(ports, ", "), pod.Namespace, pod.Name)
+	return forwarding, nil
+}
+
+// forwardPorts starts a port forwarder to the pod and waits until it is ready. Errors of single connections are only
+// logged, the returned forwarding breaks if the connection to the pod is lost
+func (serviceClient *client) forwardPorts(pod *v1.Pod, ports []string, addresses []string) (*portforward.PortForwarder, *forwarding, error) {
 	readyChan := make(chan struct{})
+	stopChan := make(chan struct{})
 	errorChan := make(chan error)
 
-	pf, err := serviceClient.client.NewPortForwarder(pod, ports, addresses, make(chan struct{}), readyChan, errorChan)
+	pf, err := serviceClient.client.NewPortForwarder(pod, ports, addresses, stopChan, readyChan, errorChan)
 	if err != nil {
-		return errors.Errorf("Error starting port forwarding: %v", err)
+		return nil, nil, errors.Errorf("Error starting port forwarding: %v", err)
 	}
 
+	broken := make(chan error, 2)
 	go func() {
 		err := pf.ForwardPorts()
 		if err != nil {
-			errorChan <- err
+			broken <- err
+		}
+	}()
+
+	logFile := logpkg.GetFileLogger("portforwarding")
+	go func() {
+		for {
+			select {
+			case err := <-errorChan:
+				if portforward.IsConnectionError(err) {
+					select {
+					case broken <- err:
+					default:
+					}
+				} else {
+					logFile.Error(err)
+				}
+			case <-stopChan:
+				return
+			}
 		}
 	}()
 
 	// Wait till forwarding is ready
 	select {
 	case <-readyChan:
-		log.Donef("Port forwarding started on %s (%s/%s)", strings.Join(ports, ", "), pod.Namespace, pod.Name)
-	case err := <-errorChan:
-		return errors.Wrap(err, "forward ports")
+	case err := <-broken:
+		close(stopChan)
+		return nil, nil, errors.Wrap(err, "forward ports")
 	case <-time.After(20 * time.Second):
-		return errors.Errorf("Timeout waiting for port forwarding to start")
+		close(stopChan)
+		return nil, nil, errors.Errorf("Timeout waiting for port forwarding to start")
 	}
 
-	go func(portForwarding *latest.PortForwardingConfig, interrupt chan error) {
-		select {
-		case err := <-errorChan:
-			if err != nil {
-				pf.Close()
-				for {
-					err = serviceClient.startForwarding(cache, portForwarding, interrupt, logpkg.Discard)
-					if err != nil {
-						serviceClient.log.Errorf("Error restarting port-forwarding: %v", err)
-						serviceClient.log.Errorf("Will try again in 15 seconds")
-						time.Sleep(time.Second * 15)
-						continue
-					}
+	return pf, &forwarding{
+		pod:    pod,
+		broken: broken,
+		stop: func() {
+			close(stopChan)
+		},
+	}, nil
+}
 
-					time.Sleep(time.Second * 3)
-					break
-				}
+// startUDPForwarding forwards the udp ports of the port forwarding config through the tunnel of the devspace helper,
+// because the port forwarding of kubernetes only supports tcp
+func (serviceClient *client) startUDPForwarding(cache *generated.CacheConfig, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	return serviceClient.startSupervised("Port-Forwarding", "portforwarding", interrupt, log, func(log logpkg.Logger) (*forwarding, error) {
+		container, helperTunnel, err := serviceClient.startHelperTunnel(portForwarding, "Port-Forwarding", log)
+		if err != nil {
+			return nil, err
+		}
+
+		closeChan := make(chan error)
+		go func() {
+			err := tunnel.StartForward(helperTunnel.reader, helperTunnel.writer, udpPortMappings(portForwarding.PortMappings), closeChan, container.Pod.Namespace, container.Pod.Name, log)
+			if err != nil {
+				helperTunnel.errorChan <- err
 			}
-		case <-interrupt:
-			pf.Close()
+		}()
+
+		return helperTunnel.forwarding(container.Pod, closeChan), nil
+	})
+}
+
+// tcpPortMappings returns the port mappings that are forwarded via tcp
+func tcpPortMappings(portMappings []*latest.PortMapping) []*latest.PortMapping {
+	tcp := []*latest.PortMapping{}
+	for _, portMapping := range portMappings {
+		if portMapping.Protocol != latest.PortMappingProtocolUDP {
+			tcp = append(tcp, portMapping)
 		}
-	}(portForwarding, interrupt)
+	}
 
-	return nil
+	return tcp
+}
+
+// udpPortMappings returns the port mappings that are forwarded via udp
+func udpPortMappings(portMappings []*latest.PortMapping) []*latest.PortMapping {
+	udp := []*latest.PortMapping{}
+	for _, portMapping := range portMappings {
+		if portMapping.Protocol == latest.PortMappingProtocolUDP {
+			udp = append(udp, portMapping)
+		}
+	}
+
+	return udp
 }
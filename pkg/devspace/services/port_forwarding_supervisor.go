@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// forwardingHealthInterval is the interval in which the supervisor checks the pod of a forwarding
+var forwardingHealthInterval = time.Second * 5
+
+// forwardingMinBackoff and forwardingMaxBackoff limit the time the supervisor waits between reconnects
+var (
+	forwardingMinBackoff = time.Second
+	forwardingMaxBackoff = time.Second * 30
+)
+
+// forwarding is a started port forwarding or tunnel to a pod
+type forwarding struct {
+	pod *v1.Pod
+
+	// broken receives an error if the forwarding doesn't work anymore
+	broken <-chan error
+
+	// stop closes the forwarding and releases its local ports
+	stop func()
+}
+
+// startForwardingFn selects the target pod and starts a forwarding to it
+type startForwardingFn func(log logpkg.Logger) (*forwarding, error)
+
+// forwardingSupervisor keeps a forwarding running. If the forwarding breaks or its pod is deleted, replaced or
+// not running anymore, the forwarding is stopped and started again with exponential backoff. Starting
+// it selects the target again, so the forwarding follows restarted and replaced pods on the same local ports
+type forwardingSupervisor struct {
+	client kubectl.Client
+	name   string
+	start  startForwardingFn
+
+	log     logpkg.Logger
+	logFile logpkg.Logger
+}
+
+// supervise supervises the started forwarding until interrupt is closed and stops the current forwarding then
+func (s *forwardingSupervisor) supervise(current *forwarding, interrupt chan error) {
+	for {
+		err := s.wait(current, interrupt)
+		current.stop()
+		if err == nil {
+			return
+		}
+
+		s.logFile.Error(err)
+		s.log.Warnf("%s: %v, reconnecting...", s.name, err)
+		current = s.reconnect(interrupt)
+		if current == nil {
+			return
+		}
+
+		s.log.Donef("%s: reconnected to pod %s/%s", s.name, current.pod.Namespace, current.pod.Name)
+	}
+}
+
+// wait waits until the forwarding breaks or its pod is not healthy anymore and returns the reason. Nil is
+// returned if interrupt was closed
+func (s *forwardingSupervisor) wait(current *forwarding, interrupt chan error) error {
+	ticker := time.NewTicker(forwardingHealthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case err := <-current.broken:
+			if err != nil {
+				return err
+			}
+		case <-ticker.C:
+			err := s.checkPod(current.pod)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// checkPod returns an error if the pod was deleted, replaced by a pod with the same name or is not running anymore.
+// Errors of the kubernetes api are ignored, the forwarding itself breaks if the cluster is not reachable
+func (s *forwardingSupervisor) checkPod(pod *v1.Pod) error {
+	current, err := s.client.KubeClient().CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return errors.Errorf("pod %s/%s was deleted", pod.Namespace, pod.Name)
+		}
+
+		return nil
+	}
+
+	if current.UID != pod.UID {
+		return errors.Errorf("pod %s/%s was replaced", pod.Namespace, pod.Name)
+	} else if current.DeletionTimestamp != nil {
+		return errors.Errorf("pod %s/%s is terminating", pod.Namespace, pod.Name)
+	} else if current.Status.Phase != v1.PodRunning {
+		return errors.Errorf("pod %s/%s is not running anymore", pod.Namespace, pod.Name)
+	}
+
+	return nil
+}
+
+// reconnect starts the forwarding until it succeeds and doubles the wait time after every failed attempt.
+// Nil is returned if interrupt was closed
+func (s *forwardingSupervisor) reconnect(interrupt chan error) *forwarding {
+	backoff := forwardingMinBackoff
+	for {
+		select {
+		case <-interrupt:
+			return nil
+		case <-time.After(backoff):
+		}
+
+		restarted, err := s.start(logpkg.Discard)
+		if err == nil && restarted != nil {
+			return restarted
+		} else if err == nil {
+			err = errors.New("no pod found")
+		}
+
+		backoff *= 2
+		if backoff > forwardingMaxBackoff {
+			backoff = forwardingMaxBackoff
+		}
+
+		s.logFile.Error(err)
+		s.log.Warnf("%s: error reconnecting: %v, will try again in %s", s.name, err, backoff)
+	}
+}
+
+// startSupervised starts the forwarding and supervises it until interrupt is closed
+func (serviceClient *client) startSupervised(name string, logFile string, interrupt chan error, log logpkg.Logger, start startForwardingFn) error {
+	started, err := start(log)
+	if err != nil {
+		return err
+	} else if started == nil {
+		return nil
+	}
+
+	supervisor := &forwardingSupervisor{
+		client:  serviceClient.client,
+		name:    name,
+		start:   start,
+		log:     serviceClient.log,
+		logFile: logpkg.GetFileLogger(logFile),
+	}
+	go supervisor.supervise(started, interrupt)
+	return nil
+}
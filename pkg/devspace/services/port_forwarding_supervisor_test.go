@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func runningPod(name string, uid string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+			UID:       types.UID(uid),
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+		},
+	}
+}
+
+// fakeForwarding returns a forwarding to the pod that closes stopped when it is stopped
+func fakeForwarding(pod *v1.Pod) (*forwarding, chan error, chan struct{}) {
+	broken := make(chan error, 1)
+	stopped := make(chan struct{})
+	return &forwarding{
+		pod:    pod,
+		broken: broken,
+		stop: func() {
+			close(stopped)
+		},
+	}, broken, stopped
+}
+
+func waitClosed(t *testing.T, c chan struct{}, message string) {
+	select {
+	case <-c:
+	case <-time.After(time.Second * 5):
+		t.Fatal(message)
+	}
+}
+
+func TestForwardingSupervisor(t *testing.T) {
+	defer func(interval, minBackoff time.Duration) {
+		forwardingHealthInterval = interval
+		forwardingMinBackoff = minBackoff
+	}(forwardingHealthInterval, forwardingMinBackoff)
+	forwardingHealthInterval = time.Millisecond * 10
+	forwardingMinBackoff = time.Millisecond * 10
+
+	first := runningPod("first", "1")
+	second := runningPod("second", "2")
+	kubeClient := &kubectltesting.Client{
+		Client: fake.NewSimpleClientset(first, second),
+	}
+
+	// the first reconnect fails, the second selects the new pod
+	initial, _, initialStopped := fakeForwarding(first)
+	reconnected, reconnectedBroken, reconnectedStopped := fakeForwarding(second)
+	last, _, lastStopped := fakeForwarding(second)
+	starts := make(chan *forwarding, 3)
+	starts <- nil
+	starts <- reconnected
+	starts <- last
+
+	supervisor := &forwardingSupervisor{
+		client: kubeClient,
+		name:   "Port-Forwarding",
+		start: func(log logpkg.Logger) (*forwarding, error) {
+			next := <-starts
+			if next == nil {
+				return nil, errors.New("no running pod")
+			}
+
+			return next, nil
+		},
+		log:     logpkg.Discard,
+		logFile: logpkg.Discard,
+	}
+
+	interrupt := make(chan error)
+	done := make(chan struct{})
+	go func() {
+		supervisor.supervise(initial, interrupt)
+		close(done)
+	}()
+
+	// a deleted pod is detected by the health check
+	err := kubeClient.Client.CoreV1().Pods("test").Delete(context.TODO(), "first", metav1.DeleteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitClosed(t, initialStopped, "Expected forwarding to the deleted pod to be stopped")
+
+	// a broken forwarding is reconnected as well
+	reconnectedBroken <- errors.New("lost connection to pod")
+	waitClosed(t, reconnectedStopped, "Expected broken forwarding to be stopped")
+	for i := 0; len(starts) > 0; i++ {
+		if i == 500 {
+			t.Fatalf("Expected all forwardings to be started, %d left", len(starts))
+		}
+
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	close(interrupt)
+	waitClosed(t, lastStopped, "Expected forwarding to be stopped on interrupt")
+	waitClosed(t, done, "Expected supervisor to return on interrupt")
+}
+
+func TestForwardingSupervisorCheckPod(t *testing.T) {
+	replaced := runningPod("replaced", "new")
+	terminating := runningPod("terminating", "1")
+	terminating.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+	completed := runningPod("completed", "1")
+	completed.Status.Phase = v1.PodSucceeded
+	running := runningPod("running", "1")
+
+	supervisor := &forwardingSupervisor{
+		client: &kubectltesting.Client{
+			Client: fake.NewSimpleClientset(replaced, terminating, completed, running),
+		},
+	}
+
+	for _, name := range []string{"deleted", "replaced", "terminating", "completed"} {
+		if supervisor.checkPod(runningPod(name, "1")) == nil {
+			t.Fatalf("Expected pod %s to be unhealthy", name)
+		}
+	}
+	if err := supervisor.checkPod(running); err != nil {
+		t.Fatalf("Expected running pod to be healthy: %v", err)
+	}
+}
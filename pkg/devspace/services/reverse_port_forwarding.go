@@ -9,13 +9,16 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/tunnel"
 	"github.com/loft-sh/devspace/pkg/util/imageselector"
 	"io"
+	"strings"
 	"time"
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/loft-sh/devspace/pkg/util/message"
 	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
 )
 
 // StartReversePortForwarding starts the reverse port forwarding functionality
@@ -41,6 +44,50 @@ func (serviceClient *client) StartReversePortForwarding(interrupt chan error) er
 }
 
 func (serviceClient *client) startReversePortForwarding(cache *generated.CacheConfig, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	return serviceClient.startSupervised("Reverse-Port-Forwarding", "reverse-portforwarding", interrupt, log, func(log logpkg.Logger) (*forwarding, error) {
+		container, helperTunnel, err := serviceClient.startHelperTunnel(portForwarding, "Reverse-Port-Forwarding", log)
+		if err != nil {
+			return nil, err
+		}
+
+		closeChan := make(chan error)
+		go func() {
+			err := tunnel.StartReverseForward(helperTunnel.reader, helperTunnel.writer, portForwarding.PortMappingsReverse, closeChan, container.Pod.Namespace, container.Pod.Name, log)
+			if err != nil {
+				helperTunnel.errorChan <- err
+			}
+		}()
+
+		forwarding := helperTunnel.forwarding(container.Pod, closeChan)
+		if portForwarding.ReverseForwardService != "" {
+			err = serviceClient.exposeReverseForwarding(portForwarding, container.Pod)
+			if err != nil {
+				forwarding.stop()
+				return nil, err
+			}
+
+			log.Donef("Reverse port forwarding exposed as service %s/%s", container.Pod.Namespace, portForwarding.ReverseForwardService)
+		}
+
+		return forwarding, nil
+	})
+}
+
+// helperTunnel is the connection to the tunnel server of the devspace helper in a container
+type helperTunnel struct {
+	reader io.ReadCloser
+	writer io.WriteCloser
+
+	stdinWriter  *io.PipeWriter
+	stdoutWriter *io.PipeWriter
+
+	// errorChan receives an error if the connection to the container is lost
+	errorChan chan error
+}
+
+// startHelperTunnel selects the container of the port forwarding config, injects the devspace helper
+// and starts its tunnel server
+func (serviceClient *client) startHelperTunnel(portForwarding *latest.PortForwardingConfig, prefix string, log logpkg.Logger) (*kubectl.SelectedPodContainer, *helperTunnel, error) {
 	var err error
 
 	// apply config & set image selector
@@ -49,14 +96,14 @@ func (serviceClient *client) startReversePortForwarding(cache *generated.CacheCo
 	options.ImageSelector = []imageselector.ImageSelector{}
 	imageSelector, err := imageselector.Resolve(portForwarding.ImageName, serviceClient.config, serviceClient.dependencies)
 	if err != nil {
-		return err
+		return nil, nil, err
 	} else if imageSelector != nil {
 		options.ImageSelector = append(options.ImageSelector, *imageSelector)
 	}
 	if portForwarding.ImageSelector != "" {
 		imageSelector, err := util.ResolveImageAsImageSelector(portForwarding.ImageSelector, serviceClient.config, serviceClient.dependencies)
 		if err != nil {
-			return err
+			return nil, nil, err
 		}
 
 		options.ImageSelector = append(options.ImageSelector, *imageSelector)
@@ -64,68 +111,54 @@ func (serviceClient *client) startReversePortForwarding(cache *generated.CacheCo
 	options.WaitingStrategy = targetselector.NewUntilNewestRunningWaitingStrategy(time.Second * 2)
 	options.SkipInitContainers = true
 
-	log.StartWait("Reverse-Port-Forwarding: Waiting for containers to start...")
+	log.StartWait(prefix + ": Waiting for containers to start...")
 	container, err := targetselector.NewTargetSelector(serviceClient.client).SelectSingleContainer(context.TODO(), options, log)
 	log.StopWait()
 	if err != nil {
-		return errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
+		return nil, nil, errors.Errorf("%s: %s", message.SelectorErrorPod, err.Error())
 	}
 
 	// make sure the devspace helper binary is injected
-	log.StartWait("Reverse-Port-Forwarding: Upload devspace helper...")
+	log.StartWait(prefix + ": Upload devspace helper...")
 	err = inject.InjectDevSpaceHelper(serviceClient.client, container.Pod, container.Container.Name, string(portForwarding.Arch), serviceClient.log)
 	log.StopWait()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	errorChan := make(chan error, 2)
-	closeChan := make(chan error)
+	startStream := inject.StartStream
+	if portForwarding.Multiplex {
+		startStream = inject.StartMultiplexedStream
+	}
 
 	stdinReader, stdinWriter := io.Pipe()
 	stdoutReader, stdoutWriter := io.Pipe()
+	helperTunnel := &helperTunnel{
+		reader:       stdoutReader,
+		writer:       stdinWriter,
+		stdinWriter:  stdinWriter,
+		stdoutWriter: stdoutWriter,
+		errorChan:    make(chan error, 2),
+	}
 	go func() {
-		err := inject.StartStream(serviceClient.client, container.Pod, container.Container.Name, []string{inject.DevSpaceHelperContainerPath, "tunnel"}, stdinReader, stdoutWriter)
+		err := startStream(serviceClient.client, container.Pod, container.Container.Name, []string{inject.DevSpaceHelperContainerPath, "tunnel"}, stdinReader, stdoutWriter)
 		if err != nil {
-			errorChan <- errors.Errorf("Reverse Port Forwarding - connection lost to pod %s/%s: %v", container.Pod.Namespace, container.Pod.Name, err)
+			helperTunnel.errorChan <- errors.Errorf("%s - connection lost to pod %s/%s: %v", strings.Replace(prefix, "-", " ", -1), container.Pod.Namespace, container.Pod.Name, err)
 		}
 	}()
 
-	go func() {
-		err := tunnel.StartReverseForward(stdoutReader, stdinWriter, portForwarding.PortMappingsReverse, closeChan, container.Pod.Namespace, container.Pod.Name, log)
-		if err != nil {
-			errorChan <- err
-		}
-	}()
+	return container, helperTunnel, nil
+}
 
-	logFile := logpkg.GetFileLogger("reverse-portforwarding")
-	go func(portForwarding *latest.PortForwardingConfig, interrupt chan error) {
-		select {
-		case err := <-errorChan:
-			if err != nil {
-				close(closeChan)
-				stdinWriter.Close()
-				stdoutWriter.Close()
-				logFile.Error(err)
-				for {
-					err = serviceClient.startReversePortForwarding(cache, portForwarding, interrupt, logpkg.Discard)
-					if err != nil {
-						serviceClient.log.Errorf("Error restarting reverse port-forwarding: %v", err)
-						serviceClient.log.Errorf("Will try again in 15 seconds")
-						time.Sleep(time.Second * 15)
-						continue
-					}
-
-					time.Sleep(time.Second * 5)
-					break
-				}
-			}
-		case <-interrupt:
+// forwarding returns the tunnel as forwarding to the pod. Stopping it closes closeChan and the connection to the container
+func (h *helperTunnel) forwarding(pod *v1.Pod, closeChan chan error) *forwarding {
+	return &forwarding{
+		pod:    pod,
+		broken: h.errorChan,
+		stop: func() {
 			close(closeChan)
-			stdinWriter.Close()
-			stdoutWriter.Close()
-		}
-	}(portForwarding, interrupt)
-
-	return nil
+			h.stdinWriter.Close()
+			h.stdoutWriter.Close()
+		},
+	}
 }
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/exit"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// reverseServiceLabel is set on the services that expose reverse forwarded ports, other services are never changed
+const reverseServiceLabel = "devspace.sh/reverse-forward"
+
+// getReverseServicePorts returns the service ports for the reverse port mappings, every remote port is exposed as is
+func getReverseServicePorts(portMappings []*latest.PortMapping) []v1.ServicePort {
+	ports := []v1.ServicePort{}
+	for _, portMapping := range portMappings {
+		if portMapping.LocalPort == nil {
+			continue
+		}
+
+		remotePort := *portMapping.LocalPort
+		if portMapping.RemotePort != nil {
+			remotePort = *portMapping.RemotePort
+		}
+
+		protocol := v1.ProtocolTCP
+		if portMapping.Protocol == latest.PortMappingProtocolUDP {
+			protocol = v1.ProtocolUDP
+		}
+
+		ports = append(ports, v1.ServicePort{
+			Name:       strings.ToLower(string(protocol)) + "-" + strconv.Itoa(remotePort),
+			Protocol:   protocol,
+			Port:       int32(remotePort),
+			TargetPort: intstr.FromInt(remotePort),
+		})
+	}
+
+	return ports
+}
+
+// exposeReverseForwarding creates or updates the service of the port forwarding config, so that the reverse forwarded
+// ports can be reached from other pods. The service has no selector, its only endpoint is the pod running the tunnel
+// of the devspace helper. The service is deleted when DevSpace exits
+func (serviceClient *client) exposeReverseForwarding(portForwarding *latest.PortForwardingConfig, pod *v1.Pod) error {
+	kubeClient := serviceClient.client.KubeClient()
+	name := portForwarding.ReverseForwardService
+	if pod.Status.PodIP == "" {
+		current, err := kubeClient.CoreV1().Pods(pod.Namespace).Get(context.TODO(), pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "get pod %s/%s", pod.Namespace, pod.Name)
+		}
+
+		pod = current
+	}
+
+	ports := getReverseServicePorts(portForwarding.PortMappingsReverse)
+	service, err := kubeClient.CoreV1().Services(pod.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) == false {
+			return errors.Wrapf(err, "get service %s/%s", pod.Namespace, name)
+		}
+
+		service, err = kubeClient.CoreV1().Services(pod.Namespace).Create(context.TODO(), &v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: pod.Namespace,
+				Labels: map[string]string{
+					reverseServiceLabel: "true",
+				},
+			},
+			Spec: v1.ServiceSpec{
+				Ports: ports,
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "create service %s/%s", pod.Namespace, name)
+		}
+	} else if service.Labels[reverseServiceLabel] != "true" {
+		return errors.Errorf("service %s/%s already exists and was not created by DevSpace", pod.Namespace, name)
+	} else {
+		service.Spec.Ports = ports
+		service, err = kubeClient.CoreV1().Services(pod.Namespace).Update(context.TODO(), service, metav1.UpdateOptions{})
+		if err != nil {
+			return errors.Wrapf(err, "update service %s/%s", pod.Namespace, name)
+		}
+	}
+
+	exit.RegisterCleanup("reverse-service-"+pod.Namespace+"/"+name, func() {
+		_ = serviceClient.deleteReverseService(pod.Namespace, name)
+	})
+
+	endpointPorts := make([]v1.EndpointPort, 0, len(ports))
+	for _, port := range ports {
+		endpointPorts = append(endpointPorts, v1.EndpointPort{
+			Name:     port.Name,
+			Port:     port.Port,
+			Protocol: port.Protocol,
+		})
+	}
+
+	endpoints := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				reverseServiceLabel: "true",
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "v1",
+					Kind:       "Service",
+					Name:       service.Name,
+					UID:        service.UID,
+				},
+			},
+		},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					{
+						IP: pod.Status.PodIP,
+						TargetRef: &v1.ObjectReference{
+							Kind:      "Pod",
+							Namespace: pod.Namespace,
+							Name:      pod.Name,
+							UID:       pod.UID,
+						},
+					},
+				},
+				Ports: endpointPorts,
+			},
+		},
+	}
+
+	existing, err := kubeClient.CoreV1().Endpoints(pod.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) == false {
+			return errors.Wrapf(err, "get endpoints %s/%s", pod.Namespace, name)
+		}
+
+		_, err = kubeClient.CoreV1().Endpoints(pod.Namespace).Create(context.TODO(), endpoints, metav1.CreateOptions{})
+	} else {
+		endpoints.ResourceVersion = existing.ResourceVersion
+		_, err = kubeClient.CoreV1().Endpoints(pod.Namespace).Update(context.TODO(), endpoints, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		return errors.Wrapf(err, "point service %s/%s to pod %s", pod.Namespace, name, pod.Name)
+	}
+
+	return nil
+}
+
+// deleteReverseService deletes the service that exposes reverse forwarded ports and its endpoints
+func (serviceClient *client) deleteReverseService(namespace string, name string) error {
+	kubeClient := serviceClient.client.KubeClient()
+	err := kubeClient.CoreV1().Services(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && kerrors.IsNotFound(err) == false {
+		return errors.Wrapf(err, "delete service %s/%s", namespace, name)
+	}
+
+	err = kubeClient.CoreV1().Endpoints(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{})
+	if err != nil && kerrors.IsNotFound(err) == false {
+		return errors.Wrapf(err, "delete endpoints %s/%s", namespace, name)
+	}
+
+	return nil
+}
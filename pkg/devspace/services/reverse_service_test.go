@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/util/exit"
+	"github.com/loft-sh/devspace/pkg/util/ptr"
+	"gotest.tools/assert"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func tunnelPod(name string, ip string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "test",
+		},
+		Status: v1.PodStatus{
+			PodIP: ip,
+		},
+	}
+}
+
+func TestExposeReverseForwarding(t *testing.T) {
+	defer exit.UnregisterCleanup("reverse-service-test/local-api")
+
+	kubeClient := fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "test"},
+	})
+	client := &client{
+		client: &kubectltesting.Client{Client: kubeClient},
+	}
+	portForwarding := &latest.PortForwardingConfig{
+		PortMappingsReverse: []*latest.PortMapping{
+			{LocalPort: ptr.Int(3000), RemotePort: ptr.Int(8080)},
+			{LocalPort: ptr.Int(8125), Protocol: latest.PortMappingProtocolUDP},
+		},
+		ReverseForwardService: "local-api",
+	}
+
+	err := client.exposeReverseForwarding(portForwarding, tunnelPod("first", "10.0.0.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service, err := kubeClient.CoreV1().Services("test").Get(context.TODO(), "local-api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, len(service.Spec.Selector), "Service should not select pods")
+	assert.Equal(t, 2, len(service.Spec.Ports), "Wrong number of service ports")
+	assert.Equal(t, int32(8080), service.Spec.Ports[0].Port, "Remote port not exposed")
+	assert.Equal(t, v1.ProtocolUDP, service.Spec.Ports[1].Protocol, "Udp port not exposed")
+
+	// the endpoints follow the tunnel to a new pod
+	err = client.exposeReverseForwarding(portForwarding, tunnelPod("second", "10.0.0.2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	endpoints, err := kubeClient.CoreV1().Endpoints("test").Get(context.TODO(), "local-api", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 1, len(endpoints.Subsets), "Wrong number of endpoint subsets")
+	assert.Equal(t, 1, len(endpoints.Subsets[0].Addresses), "Wrong number of endpoint addresses")
+	assert.Equal(t, "10.0.0.2", endpoints.Subsets[0].Addresses[0].IP, "Endpoints not updated")
+	assert.Equal(t, "second", endpoints.Subsets[0].Addresses[0].TargetRef.Name, "Endpoints not updated")
+
+	// services that were not created by devspace are not changed
+	portForwarding.ReverseForwardService = "other"
+	err = client.exposeReverseForwarding(portForwarding, tunnelPod("first", "10.0.0.1"))
+	if err == nil {
+		t.Fatal("Expected error for existing service")
+	}
+
+	err = client.deleteReverseService("test", "local-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = kubeClient.CoreV1().Services("test").Get(context.TODO(), "local-api", metav1.GetOptions{})
+	assert.Equal(t, true, kerrors.IsNotFound(err), "Service not deleted")
+	_, err = kubeClient.CoreV1().Endpoints("test").Get(context.TODO(), "local-api", metav1.GetOptions{})
+	assert.Equal(t, true, kerrors.IsNotFound(err), "Endpoints not deleted")
+}
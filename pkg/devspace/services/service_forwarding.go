@@ -0,0 +1,537 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/generated"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// serviceForwardingInterval is the interval in which the ready endpoints of a forwarded service are updated
+var serviceForwardingInterval = time.Second * 2
+
+// serviceEndpoint is a ready endpoint pod of a service with the container ports of the forwarded service ports
+type serviceEndpoint struct {
+	pod   *v1.Pod
+	ports map[int]int
+}
+
+// resolveServiceEndpoints returns the ready endpoint pods of the service by namespace and name. Endpoints that
+// don't serve all of the service ports or don't belong to a pod are skipped
+func resolveServiceEndpoints(client kubernetes.Interface, namespace string, name string, servicePorts []int) (map[string]*serviceEndpoint, error) {
+	service, err := client.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "get service %s/%s", namespace, name)
+	}
+
+	portNames := map[int]string{}
+	for _, servicePort := range servicePorts {
+		found := false
+		for _, p := range service.Spec.Ports {
+			if int(p.Port) == servicePort && (p.Protocol == "" || p.Protocol == v1.ProtocolTCP) {
+				portNames[servicePort] = p.Name
+				found = true
+				break
+			}
+		}
+		if found == false {
+			return nil, errors.Errorf("service %s/%s has no tcp port %d", namespace, name, servicePort)
+		}
+	}
+
+	endpoints, err := client.CoreV1().Endpoints(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return map[string]*serviceEndpoint{}, nil
+		}
+
+		return nil, errors.Wrapf(err, "get endpoints of service %s/%s", namespace, name)
+	}
+
+	ready := map[string]*serviceEndpoint{}
+	for _, subset := range endpoints.Subsets {
+		ports := map[int]int{}
+		for servicePort, portName := range portNames {
+			for _, endpointPort := range subset.Ports {
+				if endpointPort.Name == portName && (endpointPort.Protocol == "" || endpointPort.Protocol == v1.ProtocolTCP) {
+					ports[servicePort] = int(endpointPort.Port)
+					break
+				}
+			}
+		}
+		if len(ports) != len(portNames) {
+			continue
+		}
+
+		for _, address := range subset.Addresses {
+			if address.TargetRef == nil || address.TargetRef.Kind != "Pod" {
+				continue
+			}
+
+			podNamespace := address.TargetRef.Namespace
+			if podNamespace == "" {
+				podNamespace = namespace
+			}
+
+			ready[podNamespace+"/"+address.TargetRef.Name] = &serviceEndpoint{
+				pod: &v1.Pod{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      address.TargetRef.Name,
+						Namespace: podNamespace,
+					},
+				},
+				ports: ports,
+			}
+		}
+	}
+
+	return ready, nil
+}
+
+// serviceBackend forwards the service ports to random local ports of an endpoint pod
+type serviceBackend struct {
+	endpoint   *serviceEndpoint
+	localPorts map[int]int
+	forwarding *forwarding
+
+	// connectionFailed returns true if the local connection from the given address couldn't be forwarded to the pod
+	connectionFailed func(localAddr string) bool
+}
+
+// serviceBalancer distributes new connections across the backends of a service round-robin
+type serviceBalancer struct {
+	m        sync.Mutex
+	backends []*serviceBackend
+	next     int
+}
+
+func (b *serviceBalancer) setBackends(backends []*serviceBackend) {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	b.backends = backends
+}
+
+// nextBackends returns all backends starting with the next one in round-robin order, so that a connection can
+// fall back to the other backends
+func (b *serviceBalancer) nextBackends() []*serviceBackend {
+	b.m.Lock()
+	defer b.m.Unlock()
+
+	if len(b.backends) == 0 {
+		return nil
+	}
+
+	start := b.next % len(b.backends)
+	b.next = start + 1
+	return append(append([]*serviceBackend{}, b.backends[start:]...), b.backends[:start]...)
+}
+
+// serviceForwarder forwards local ports to the ready endpoints of a service
+type serviceForwarder struct {
+	serviceClient *client
+
+	namespace    string
+	name         string
+	servicePorts []int
+
+	balancer serviceBalancer
+	backends map[string]*serviceBackend
+
+	logFile logpkg.Logger
+}
+
+// reconcile starts port forwardings to new ready endpoints and stops the ones to endpoints that went unready
+// or broke. Broken endpoints are forwarded again if they are still ready
+func (s *serviceForwarder) reconcile() error {
+	endpoints, err := resolveServiceEndpoints(s.serviceClient.client.KubeClient(), s.namespace, s.name, s.servicePorts)
+	if err != nil {
+		return err
+	}
+
+	for key, backend := range s.backends {
+		broken := false
+		select {
+		case err := <-backend.forwarding.broken:
+			s.logFile.Errorf("Port forwarding to endpoint %s of service %s/%s broke: %v", key, s.namespace, s.name, err)
+			broken = true
+		default:
+		}
+
+		endpoint, ok := endpoints[key]
+		if ok == false || broken || reflect.DeepEqual(endpoint.ports, backend.endpoint.ports) == false {
+			backend.forwarding.stop()
+			delete(s.backends, key)
+			s.logFile.Infof("Removed endpoint %s of service %s/%s", key, s.namespace, s.name)
+		}
+	}
+
+	for key, endpoint := range endpoints {
+		if _, ok := s.backends[key]; ok {
+			continue
+		}
+
+		backend, err := s.startBackend(endpoint)
+		if err != nil {
+			s.logFile.Errorf("Error forwarding to endpoint %s of service %s/%s: %v", key, s.namespace, s.name, err)
+			continue
+		}
+
+		s.backends[key] = backend
+		s.logFile.Infof("Added endpoint %s of service %s/%s", key, s.namespace, s.name)
+	}
+
+	keys := make([]string, 0, len(s.backends))
+	for key := range s.backends {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	backends := make([]*serviceBackend, 0, len(keys))
+	for _, key := range keys {
+		backends = append(backends, s.backends[key])
+	}
+
+	s.balancer.setBackends(backends)
+	return nil
+}
+
+// startBackend forwards the container ports of the endpoint to random local ports
+func (s *serviceForwarder) startBackend(endpoint *serviceEndpoint) (*serviceBackend, error) {
+	ports := make([]string, len(s.servicePorts))
+	for index, servicePort := range s.servicePorts {
+		ports[index] = "0:" + strconv.Itoa(endpoint.ports[servicePort])
+	}
+
+	pf, forwarding, err := s.serviceClient.forwardPorts(endpoint.pod, ports, []string{"127.0.0.1"})
+	if err != nil {
+		return nil, err
+	}
+
+	forwardedPorts, err := pf.GetPorts()
+	if err != nil {
+		forwarding.stop()
+		return nil, err
+	}
+
+	localPorts := map[int]int{}
+	for index, servicePort := range s.servicePorts {
+		localPorts[servicePort] = int(forwardedPorts[index].Local)
+	}
+
+	return &serviceBackend{
+		endpoint:         endpoint,
+		localPorts:       localPorts,
+		forwarding:       forwarding,
+		connectionFailed: pf.ConnectionFailed,
+	}, nil
+}
+
+// stop stops the port forwardings to all endpoints
+func (s *serviceForwarder) stop() {
+	for key, backend := range s.backends {
+		backend.forwarding.stop()
+		delete(s.backends, key)
+	}
+
+	s.balancer.setBackends(nil)
+}
+
+// serve accepts the connections of the listener until it is closed
+func (s *serviceForwarder) serve(listener net.Listener, servicePort int) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go s.handleConnection(conn, servicePort)
+	}
+}
+
+// handleConnection proxies the connection to the next backend that answers it. The port forwarding accepts every
+// connection, even if the stream to the pod cannot be opened, and closes it right away then. So a backend whose
+// forwarding failed before it answered is skipped and the data the client has sent so far is sent to the next backend.
+// A backend that closes the connection without an answer although the forwarding worked is not skipped, because the
+// pod may have processed the data already
+func (s *serviceForwarder) handleConnection(conn net.Conn, servicePort int) {
+	defer conn.Close()
+
+	client := newReplayConn(conn)
+	defer client.close()
+
+	for _, backend := range s.balancer.nextBackends() {
+		backendConn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(backend.localPorts[servicePort])))
+		if err != nil {
+			continue
+		}
+
+		answered := client.proxy(backendConn, func() bool {
+			return backend.connectionFailed != nil && backend.connectionFailed(backendConn.LocalAddr().String())
+		})
+		_ = backendConn.Close()
+		if answered {
+			return
+		}
+	}
+
+	s.logFile.Errorf("No ready endpoint of service %s/%s for connection on port %d", s.namespace, s.name, servicePort)
+}
+
+// maxReplayBytes is the amount of data a client can send before a backend answered, which is kept to be sent to
+// the next backend if the backend fails. If the client sends more, the connection stays with the current backend
+var maxReplayBytes = 64 * 1024
+
+// replayConn reads a client connection and keeps the data that was sent before a backend answered
+type replayConn struct {
+	conn   net.Conn
+	chunks chan []byte
+	done   chan struct{}
+
+	// sent is the data that was sent to the current backend, it is nil if the data exceeded maxReplayBytes
+	sent      [][]byte
+	sentBytes int
+	eof       bool
+}
+
+func newReplayConn(conn net.Conn) *replayConn {
+	c := &replayConn{
+		conn:   conn,
+		chunks: make(chan []byte),
+		done:   make(chan struct{}),
+		sent:   [][]byte{},
+	}
+
+	go func() {
+		defer close(c.chunks)
+		for {
+			buf := make([]byte, 32*1024)
+			n, err := conn.Read(buf)
+			if n > 0 {
+				select {
+				case c.chunks <- buf[:n]:
+				case <-c.done:
+					return
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return c
+}
+
+// close stops reading the client connection
+func (c *replayConn) close() {
+	close(c.done)
+}
+
+// proxy sends the client data to the backend and copies the data between both connections until both directions
+// are done. False is returned if the backend closed the connection before it answered, because failed reported that
+// the forwarding of the connection failed, and the client data was kept, so that the connection can be proxied to
+// another backend
+func (c *replayConn) proxy(backendConn net.Conn, failed func() bool) bool {
+	for _, chunk := range c.sent {
+		if _, err := backendConn.Write(chunk); err != nil {
+			return failed() == false
+		}
+	}
+	if c.eof {
+		closeWrite(backendConn)
+	}
+
+	// wait for the first answer of the backend and send the client data in the meantime
+	answer := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := backendConn.Read(buf)
+			if n > 0 {
+				answer <- buf[:n]
+				return
+			} else if err != nil {
+				answer <- nil
+				return
+			}
+		}
+	}()
+
+	for answered := false; answered == false; {
+		select {
+		case first := <-answer:
+			if first == nil {
+				return c.sent == nil || failed() == false
+			} else if _, err := c.conn.Write(first); err != nil {
+				return true
+			}
+
+			answered = true
+		case chunk, ok := <-c.clientChunks():
+			if ok == false {
+				c.eof = true
+				closeWrite(backendConn)
+				continue
+			}
+
+			if c.sent != nil {
+				c.sent = append(c.sent, chunk)
+				c.sentBytes += len(chunk)
+				if c.sentBytes > maxReplayBytes {
+					c.sent = nil
+				}
+			}
+			if _, err := backendConn.Write(chunk); err != nil {
+				return c.sent == nil || failed() == false
+			}
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(c.conn, backendConn)
+		closeWrite(c.conn)
+		close(done)
+	}()
+
+	if c.eof == false {
+		for chunk := range c.chunks {
+			if _, err := backendConn.Write(chunk); err != nil {
+				break
+			}
+		}
+		closeWrite(backendConn)
+	}
+
+	<-done
+	return true
+}
+
+// clientChunks returns the channel of the client data or nil if the client closed the connection already
+func (c *replayConn) clientChunks() chan []byte {
+	if c.eof {
+		return nil
+	}
+
+	return c.chunks
+}
+
+// closeWriter is implemented by connections that can be half closed
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// closeWrite half closes the connection if possible and closes it otherwise
+func closeWrite(conn net.Conn) {
+	if c, ok := conn.(closeWriter); ok {
+		_ = c.CloseWrite()
+	} else {
+		_ = conn.Close()
+	}
+}
+
+// startServiceForwarding forwards the tcp ports of the port forwarding config to the ready endpoints of its service
+func (serviceClient *client) startServiceForwarding(cache *generated.CacheConfig, portForwarding *latest.PortForwardingConfig, interrupt chan error, log logpkg.Logger) error {
+	namespace := portForwarding.Namespace
+	if namespace == "" {
+		namespace = serviceClient.client.Namespace()
+	}
+
+	portMappings := tcpPortMappings(portForwarding.PortMappings)
+	ports := make([]string, len(portMappings))
+	servicePorts := make([]int, len(portMappings))
+	localPorts := make([]int, len(portMappings))
+	for index, value := range portMappings {
+		if value.LocalPort == nil {
+			return errors.Errorf("port is not defined in portmapping %d", index)
+		}
+
+		servicePorts[index] = *value.LocalPort
+		if value.RemotePort != nil {
+			servicePorts[index] = *value.RemotePort
+		}
+
+		localPorts[index] = serviceClient.localPort(value, log)
+		ports[index] = strconv.Itoa(localPorts[index]) + ":" + strconv.Itoa(servicePorts[index])
+	}
+
+	forwarder := &serviceForwarder{
+		serviceClient: serviceClient,
+		namespace:     namespace,
+		name:          portForwarding.Service,
+		servicePorts:  servicePorts,
+		backends:      map[string]*serviceBackend{},
+		logFile:       logpkg.GetFileLogger("portforwarding"),
+	}
+
+	log.StartWait("Port-Forwarding: Forwarding to endpoints of service " + namespace + "/" + portForwarding.Service + "...")
+	err := forwarder.reconcile()
+	log.StopWait()
+	if err != nil {
+		forwarder.stop()
+		return err
+	} else if len(forwarder.backends) == 0 {
+		log.Warnf("Port-Forwarding: Service %s/%s has no ready endpoints yet", namespace, portForwarding.Service)
+	}
+
+	listeners := []net.Listener{}
+	closeListeners := func() {
+		for _, listener := range listeners {
+			_ = listener.Close()
+		}
+	}
+	for index, value := range portMappings {
+		address := value.BindAddress
+		if address == "" {
+			address = "localhost"
+		}
+
+		listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(localPorts[index])))
+		if err != nil {
+			closeListeners()
+			forwarder.stop()
+			return errors.Wrapf(err, "listen on port %d", localPorts[index])
+		}
+
+		listeners = append(listeners, listener)
+		go forwarder.serve(listener, servicePorts[index])
+	}
+
+	log.Donef("Port forwarding started on %s (service %s/%s)", strings.Join(ports, ", "), namespace, portForwarding.Service)
+
+	go func() {
+		ticker := time.NewTicker(serviceForwardingInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-interrupt:
+				closeListeners()
+				forwarder.stop()
+				return
+			case <-ticker.C:
+				err := forwarder.reconcile()
+				if err != nil {
+					forwarder.logFile.Error(err)
+				}
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,331 @@
+package services
+
+import (
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveServiceEndpoints(t *testing.T) {
+	service := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+				{Name: "metrics", Port: 9090},
+			},
+		},
+	}
+	endpoints := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					{TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "api-1"}},
+					{IP: "10.0.0.1"},
+				},
+				NotReadyAddresses: []v1.EndpointAddress{
+					{TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "api-2"}},
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "http", Port: 8080},
+					{Name: "metrics", Port: 9090},
+				},
+			},
+			{
+				Addresses: []v1.EndpointAddress{
+					{TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "api-3"}},
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "http", Port: 3000},
+				},
+			},
+		},
+	}
+	kubeClient := fake.NewSimpleClientset(service, endpoints)
+
+	ready, err := resolveServiceEndpoints(kubeClient, "test", "api", []int{80})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(ready) != 2 || ready["test/api-1"].ports[80] != 8080 || ready["test/api-3"].ports[80] != 3000 {
+		t.Fatalf("Unexpected ready endpoints %v", ready)
+	}
+
+	// endpoints that don't serve all forwarded ports are skipped
+	ready, err = resolveServiceEndpoints(kubeClient, "test", "api", []int{80, 9090})
+	if err != nil {
+		t.Fatal(err)
+	} else if len(ready) != 1 || ready["test/api-1"] == nil || ready["test/api-1"].pod.Name != "api-1" {
+		t.Fatalf("Unexpected ready endpoints %v", ready)
+	}
+
+	_, err = resolveServiceEndpoints(kubeClient, "test", "api", []int{443})
+	if err == nil {
+		t.Fatal("Expected error for port that is not exposed by the service")
+	}
+}
+
+// listenBackend starts a server that answers every connection with its name
+func listenBackend(t *testing.T, name string) (*serviceBackend, net.Listener) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_, _ = conn.Write([]byte(name))
+			_ = conn.Close()
+		}
+	}()
+
+	return &serviceBackend{
+		localPorts: map[int]int{80: listener.Addr().(*net.TCPAddr).Port},
+	}, listener
+}
+
+func TestServiceForwarderRoundRobin(t *testing.T) {
+	first, firstListener := listenBackend(t, "first")
+	defer firstListener.Close()
+	second, secondListener := listenBackend(t, "second")
+	defer secondListener.Close()
+
+	forwarder := &serviceForwarder{
+		namespace: "test",
+		name:      "api",
+		logFile:   logpkg.Discard,
+	}
+	forwarder.balancer.setBackends([]*serviceBackend{first, second})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go forwarder.serve(listener, 80)
+
+	request := func() string {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		out, err := ioutil.ReadAll(conn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return string(out)
+	}
+
+	for _, expected := range []string{"first", "second", "first"} {
+		if answer := request(); answer != expected {
+			t.Fatalf("Expected answer of %s, got %q", expected, answer)
+		}
+	}
+
+	// connections fall back to the next backend if an endpoint doesn't accept them
+	_ = secondListener.Close()
+	for i := 0; i < 2; i++ {
+		if answer := request(); answer != "first" {
+			t.Fatalf("Expected answer of the remaining endpoint, got %q", answer)
+		}
+	}
+
+	// connections are closed if there are no ready endpoints
+	forwarder.balancer.setBackends(nil)
+	if answer := request(); answer != "" {
+		t.Fatalf("Expected closed connection, got %q", answer)
+	}
+}
+
+func TestServiceForwarderFallback(t *testing.T) {
+	// the port forwarding accepts connections to a pod it cannot reach, records them as failed and closes them
+	dead, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dead.Close()
+
+	failedLock := sync.Mutex{}
+	failed := map[string]bool{}
+	go func() {
+		for {
+			conn, err := dead.Accept()
+			if err != nil {
+				return
+			}
+
+			failedLock.Lock()
+			failed[conn.RemoteAddr().String()] = true
+			failedLock.Unlock()
+			_ = conn.Close()
+		}
+	}()
+
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+
+			data, _ := ioutil.ReadAll(conn)
+			_, _ = conn.Write(append([]byte("echo:"), data...))
+			_ = conn.Close()
+		}
+	}()
+
+	forwarder := &serviceForwarder{
+		namespace: "test",
+		name:      "api",
+		logFile:   logpkg.Discard,
+	}
+	forwarder.balancer.setBackends([]*serviceBackend{
+		{
+			localPorts: map[int]int{80: dead.Addr().(*net.TCPAddr).Port},
+			connectionFailed: func(localAddr string) bool {
+				failedLock.Lock()
+				defer failedLock.Unlock()
+
+				return failed[localAddr]
+			},
+		},
+		{localPorts: map[int]int{80: echo.Addr().(*net.TCPAddr).Port}},
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go forwarder.serve(listener, 80)
+
+	// the request that was sent to the failed backend is sent to the next one
+	for i := 0; i < 2; i++ {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = conn.Write([]byte("ping"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.(*net.TCPConn).CloseWrite()
+
+		out, err := ioutil.ReadAll(conn)
+		_ = conn.Close()
+		if err != nil {
+			t.Fatal(err)
+		} else if string(out) != "echo:ping" {
+			t.Fatalf("Expected answer of the remaining endpoint, got %q", string(out))
+		}
+	}
+}
+
+func TestServiceForwarderNoFallback(t *testing.T) {
+	// the pod consumes the request and closes the connection without an answer
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+
+			_, _ = ioutil.ReadAll(conn)
+			_ = conn.Close()
+		}
+	}()
+
+	counting, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer counting.Close()
+
+	countLock := sync.Mutex{}
+	count := 0
+	go func() {
+		for {
+			conn, err := counting.Accept()
+			if err != nil {
+				return
+			}
+
+			countLock.Lock()
+			count++
+			countLock.Unlock()
+			_ = conn.Close()
+		}
+	}()
+
+	forwarder := &serviceForwarder{
+		namespace: "test",
+		name:      "api",
+		logFile:   logpkg.Discard,
+	}
+	forwarder.balancer.setBackends([]*serviceBackend{
+		{
+			localPorts:       map[int]int{80: silent.Addr().(*net.TCPAddr).Port},
+			connectionFailed: func(localAddr string) bool { return false },
+		},
+		{localPorts: map[int]int{80: counting.Addr().(*net.TCPAddr).Port}},
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go forwarder.serve(listener, 80)
+
+	// the request is not sent again to the next backend, because the forwarding didn't fail
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.(*net.TCPConn).CloseWrite()
+
+	out, err := ioutil.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(out) != 0 {
+		t.Fatalf("Expected no answer, got %q", string(out))
+	}
+
+	countLock.Lock()
+	defer countLock.Unlock()
+	if count != 0 {
+		t.Fatalf("Request was sent to %d other backends", count)
+	}
+}
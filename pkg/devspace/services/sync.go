@@ -9,6 +9,7 @@ import (
 
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
+	devspacesync "github.com/loft-sh/devspace/pkg/devspace/sync"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 
 	"github.com/pkg/errors"
@@ -46,12 +47,30 @@ func (serviceClient *client) StartSyncFromCmd(targetOptions targetselector.Optio
 	return nil
 }
 
+// DryRunSyncFromCmd calculates the changes the initial sync would apply without changing anything
+func (serviceClient *client) DryRunSyncFromCmd(targetOptions targetselector.Options, syncConfig *latest.SyncConfig, verbose bool) (*devspacesync.Diff, error) {
+	options := &synccontroller.Options{
+		SyncConfig:    syncConfig,
+		TargetOptions: targetOptions,
+		SyncLog:       logpkg.Discard,
+		Verbose:       verbose,
+	}
+
+	return synccontroller.NewController(serviceClient.config, serviceClient.dependencies, serviceClient.client, serviceClient.log).DryRun(options, serviceClient.log)
+}
+
 // StartSync starts the syncing functionality
 func (serviceClient *client) StartSync(interrupt chan error, printSyncLog bool, verboseSync bool) error {
 	if serviceClient.config == nil || serviceClient.config.Config() == nil {
 		return fmt.Errorf("DevSpace config is nil")
 	}
 
+	// Remove the persisted states of syncs that are not configured anymore
+	err := synccontroller.PruneStates(synccontroller.StateFolder, serviceClient.config.Config().Dev.Sync)
+	if err != nil {
+		serviceClient.log.Warnf("Error removing stale sync states: %v", err)
+	}
+
 	// Start sync client
 	waitGroup := sync.WaitGroup{}
 	errs := []error{}
@@ -2,6 +2,10 @@ package synccontroller
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"github.com/loft-sh/devspace/helper/server/permissions"
+	"github.com/loft-sh/devspace/helper/server/uploadrules"
 	"github.com/loft-sh/devspace/pkg/devspace/config"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/dependency/types"
@@ -10,20 +14,27 @@ import (
 	"github.com/loft-sh/devspace/pkg/devspace/services/inject"
 	"github.com/loft-sh/devspace/pkg/devspace/services/targetselector"
 	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	"github.com/loft-sh/devspace/pkg/util/hash"
 	"github.com/loft-sh/devspace/pkg/util/imageselector"
 	logpkg "github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/pkg/errors"
 	"io"
 	v1 "k8s.io/api/core/v1"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
+	gosync "sync"
 	"time"
 )
 
+// StateFolder is the folder where the state of the last sync sessions is persisted
+const StateFolder = ".devspace/sync"
+
 type Controller interface {
 	Start(options *Options, log logpkg.Logger) error
+	DryRun(options *Options, log logpkg.Logger) (*sync.Diff, error)
 }
 
 func NewController(config config.Config, dependencies []types.Dependency, client kubectl.Client, log logpkg.Logger) Controller {
@@ -32,6 +43,7 @@ func NewController(config config.Config, dependencies []types.Dependency, client
 		dependencies: dependencies,
 		client:       client,
 		log:          log,
+		stopped:      make(chan struct{}),
 	}
 }
 
@@ -40,6 +52,15 @@ type controller struct {
 	dependencies []types.Dependency
 	client       kubectl.Client
 	log          logpkg.Logger
+
+	// primary is the container the bidirectional sync currently runs against
+	primary      string
+	primaryMutex gosync.Mutex
+
+	// stopped is closed when the bidirectional sync is stopped for good and won't be restarted,
+	// the syncs to the other matching containers are stopped with it
+	stopped     chan struct{}
+	stoppedOnce gosync.Once
 }
 
 type Options struct {
@@ -57,7 +78,29 @@ type Options struct {
 }
 
 func (c *controller) Start(options *Options, log logpkg.Logger) error {
-	return c.startWithWait(options, log)
+	err := c.startWithWait(options, log)
+	if err != nil {
+		return err
+	}
+
+	// upload to the other matching containers as well
+	if options.SyncConfig.AllContainers {
+		go c.fanOut(options, log)
+	}
+
+	// remove the helper pod of a persistent volume claim again
+	if options.SyncConfig.PersistentVolumeClaim != nil {
+		go func() {
+			select {
+			case <-options.Interrupt:
+			case <-options.Done:
+			}
+
+			c.stopVolumePod(options)
+		}()
+	}
+
+	return nil
 }
 
 func (c *controller) startWithWait(options *Options, log logpkg.Logger) error {
@@ -90,6 +133,7 @@ func (c *controller) startWithWait(options *Options, log logpkg.Logger) error {
 		for {
 			select {
 			case err := <-onError:
+				unregisterSync(client)
 				return errors.Wrap(err, "initial sync")
 			case <-onInitUploadDone:
 				uploadDone = true
@@ -97,11 +141,13 @@ func (c *controller) startWithWait(options *Options, log logpkg.Logger) error {
 				downloadDone = true
 			case <-options.Interrupt:
 				client.Stop(nil)
+				c.stop()
 				return nil
 			case <-onDone:
 				if options.Done != nil {
 					close(options.Done)
 				}
+				c.stop()
 				return nil
 			}
 			if uploadDone && downloadDone {
@@ -119,6 +165,7 @@ func (c *controller) startWithWait(options *Options, log logpkg.Logger) error {
 					c.log.Fatalf("Fatal error in sync: %v", err)
 				}
 
+				unregisterSync(syncClient)
 				options.RestartLog.Info("Restarting sync...")
 				for {
 					err := c.startWithWait(options, options.RestartLog)
@@ -133,17 +180,36 @@ func (c *controller) startWithWait(options *Options, log logpkg.Logger) error {
 				}
 			case <-options.Interrupt:
 				syncClient.Stop(nil)
+				c.stop()
 			case <-onDone:
 				if options.Done != nil {
 					close(options.Done)
 				}
+				c.stop()
 			}
 		}(client, options)
+	} else {
+		go func() {
+			select {
+			case <-onError:
+			case <-options.Interrupt:
+			case <-onDone:
+			}
+
+			c.stop()
+		}()
 	}
 
 	return nil
 }
 
+// stop marks the bidirectional sync as stopped for good
+func (c *controller) stop() {
+	c.stoppedOnce.Do(func() {
+		close(c.stopped)
+	})
+}
+
 func (c *controller) startSync(options *Options, onInitUploadDone chan struct{}, onInitDownloadDone chan struct{}, onDone chan struct{}, onError chan error, log logpkg.Logger) (*sync.Sync, error) {
 	options.TargetOptions.SkipInitContainers = true
 	var (
@@ -168,27 +234,22 @@ func (c *controller) startSync(options *Options, onInitUploadDone chan struct{},
 		}
 	}
 
-	options.TargetOptions.ImageSelector = []imageselector.ImageSelector{}
-	imageSelector, err := imageselector.Resolve(syncConfig.ImageName, c.config, c.dependencies)
-	if err != nil {
-		return nil, err
-	} else if imageSelector != nil {
-		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
-	}
-	if syncConfig.ImageSelector != "" {
-		imageSelector, err := util.ResolveImageAsImageSelector(syncConfig.ImageSelector, c.config, c.dependencies)
+	var container *kubectl.SelectedPodContainer
+	if syncConfig.PersistentVolumeClaim != nil {
+		container, err = c.startVolumePod(options, log)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		container, err = c.selectContainer(options, log)
 		if err != nil {
 			return nil, err
 		}
-
-		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
 	}
 
-	log.Info("Waiting for pods...")
-	container, err := targetselector.NewTargetSelector(c.client).SelectSingleContainer(context.TODO(), options.TargetOptions, c.log)
-	if err != nil {
-		return nil, errors.Errorf("Error selecting pod: %v", err)
-	}
+	c.primaryMutex.Lock()
+	c.primary = containerKey(container)
+	c.primaryMutex.Unlock()
 
 	log.Info("Starting sync...")
 	syncClient, err := c.initClient(container.Pod, container.Container.Name, syncConfig, options.Verbose, options.SyncLog)
@@ -206,10 +267,46 @@ func (c *controller) startSync(options *Options, onInitUploadDone chan struct{},
 		containerPath = syncConfig.ContainerPath
 	}
 
+	registerSync(syncClient, containerPath, container.Pod.Namespace+"/"+container.Pod.Name)
 	log.Donef("Sync started on %s <-> %s (Pod: %s/%s)", syncClient.LocalPath, containerPath, container.Pod.Namespace, container.Pod.Name)
 	return syncClient, nil
 }
 
+// selectContainer resolves the image selectors of the sync config and selects the container
+// the bidirectional sync should be started for
+func (c *controller) selectContainer(options *Options, log logpkg.Logger) (*kubectl.SelectedPodContainer, error) {
+	syncConfig := options.SyncConfig
+	options.TargetOptions.ImageSelector = []imageselector.ImageSelector{}
+	imageSelector, err := imageselector.Resolve(syncConfig.ImageName, c.config, c.dependencies)
+	if err != nil {
+		return nil, err
+	} else if imageSelector != nil {
+		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
+	}
+	if syncConfig.ImageSelector != "" {
+		imageSelector, err := util.ResolveImageAsImageSelector(syncConfig.ImageSelector, c.config, c.dependencies)
+		if err != nil {
+			return nil, err
+		}
+
+		options.TargetOptions.ImageSelector = append(options.TargetOptions.ImageSelector, *imageSelector)
+	}
+
+	targetOptions := options.TargetOptions
+	if syncConfig.AllContainers && len(syncConfig.PrimaryLabelSelector) > 0 {
+		targetOptions.FilterPod = filterNonPrimaryPods(targetOptions.FilterPod, syncConfig.PrimaryLabelSelector)
+	}
+
+	log.Info("Waiting for pods...")
+	container, err := targetselector.NewTargetSelector(c.client).SelectSingleContainer(context.TODO(), targetOptions, c.log)
+	if err != nil {
+		return nil, errors.Errorf("Error selecting pod: %v", err)
+	}
+
+	return container, nil
+
+}
+
 func (c *controller) isFatalSyncError(err error) bool {
 	if strings.Index(err.Error(), "You are trying to sync the complete container root") != -1 {
 		return true
@@ -253,10 +350,18 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 		Verbose:              verbose,
 		InitialSyncCompareBy: compareBy,
 		InitialSync:          syncConfig.InitialSync,
+		ConflictStrategy:     syncConfig.ConflictStrategy,
+		Symlinks:             syncConfig.Symlinks,
 		UpstreamDisabled:     upstreamDisabled,
 		DownstreamDisabled:   downstreamDisabled,
 		Log:                  customLog,
 		Polling:              syncConfig.Polling,
+		UseGitignore:         syncConfig.UseGitignore,
+		UseDockerignore:      syncConfig.UseDockerignore,
+		Permissions:          getPermissionRules(syncConfig),
+		Compression:          compression.Codec(syncConfig.Compression),
+		CompressionLevel:     syncConfig.CompressionLevel,
+		StatePath:            getStatePath(pod, container, syncConfig),
 	}
 
 	// Add onDownload hooks
@@ -300,6 +405,24 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 		return nil, errors.Wrap(err, "create sync")
 	}
 
+	// Show the progress of long transfers and forward it to the ui server
+	syncClient.Options.OnProgress = func(progress sync.Progress) {
+		event := ProgressEvent{
+			Progress:      progress,
+			LocalPath:     syncClient.LocalPath,
+			ContainerPath: containerPath,
+			Pod:           pod.Namespace + "/" + pod.Name,
+		}
+
+		showProgress(customLog, event)
+		publishProgress(event)
+	}
+
+	startStream := inject.StartStream
+	if syncConfig.Multiplex {
+		startStream = inject.StartMultiplexedStream
+	}
+
 	// Start upstream
 	upstreamArgs := []string{inject.DevSpaceHelperContainerPath, "sync", "upstream"}
 	if runtime.GOOS == "darwin" || runtime.GOOS == "linux" {
@@ -311,6 +434,31 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 	for _, exclude := range options.DownloadExcludePaths {
 		upstreamArgs = append(upstreamArgs, "--exclude", exclude)
 	}
+	if syncConfig.UseGitignore {
+		upstreamArgs = append(upstreamArgs, "--use-gitignore")
+	}
+	if syncConfig.UseDockerignore {
+		upstreamArgs = append(upstreamArgs, "--use-dockerignore")
+	}
+	if syncConfig.Symlinks != "" {
+		upstreamArgs = append(upstreamArgs, "--symlinks", string(syncConfig.Symlinks))
+	}
+	if len(options.Permissions) > 0 {
+		out, err := json.Marshal(options.Permissions)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal permissions")
+		}
+
+		upstreamArgs = append(upstreamArgs, "--permissions", string(out))
+	}
+	if uploadRules := getUploadRules(syncConfig); len(uploadRules) > 0 {
+		out, err := json.Marshal(uploadRules)
+		if err != nil {
+			return nil, errors.Wrap(err, "marshal upload rules")
+		}
+
+		upstreamArgs = append(upstreamArgs, "--upload-rules", string(out))
+	}
 	if syncConfig.OnUpload != nil && syncConfig.OnUpload.ExecRemote != nil {
 		onUpload := syncConfig.OnUpload.ExecRemote
 		fileCmd, fileArgs, dirCmd, dirArgs := getSyncCommands(onUpload)
@@ -340,7 +488,7 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 	upStdoutReader, upStdoutWriter := io.Pipe()
 
 	go func() {
-		err := inject.StartStream(c.client, pod, container, upstreamArgs, upStdinReader, upStdoutWriter)
+		err := startStream(c.client, pod, container, upstreamArgs, upStdinReader, upStdoutWriter)
 		if err != nil {
 			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, err))
 		}
@@ -359,19 +507,28 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 	if syncConfig.Polling {
 		downstreamArgs = append(downstreamArgs, "--polling")
 	}
+	if syncConfig.Symlinks != "" {
+		downstreamArgs = append(downstreamArgs, "--symlinks", string(syncConfig.Symlinks))
+	}
 	for _, exclude := range options.ExcludePaths {
 		downstreamArgs = append(downstreamArgs, "--exclude", exclude)
 	}
 	for _, exclude := range options.DownloadExcludePaths {
 		downstreamArgs = append(downstreamArgs, "--exclude", exclude)
 	}
+	if syncConfig.UseGitignore {
+		downstreamArgs = append(downstreamArgs, "--use-gitignore")
+	}
+	if syncConfig.UseDockerignore {
+		downstreamArgs = append(downstreamArgs, "--use-dockerignore")
+	}
 	downstreamArgs = append(downstreamArgs, containerPath)
 
 	downStdinReader, downStdinWriter := io.Pipe()
 	downStdoutReader, downStdoutWriter := io.Pipe()
 
 	go func() {
-		err := inject.StartStream(c.client, pod, container, downstreamArgs, downStdinReader, downStdoutWriter)
+		err := startStream(c.client, pod, container, downstreamArgs, downStdinReader, downStdoutWriter)
 		if err != nil {
 			syncClient.Stop(errors.Errorf("Sync - connection lost to pod %s/%s: %v", pod.Namespace, pod.Name, err))
 		}
@@ -385,6 +542,65 @@ func (c *controller) initClient(pod *v1.Pod, container string, syncConfig *lates
 	return syncClient, nil
 }
 
+// getStatePath returns the path of the persisted sync state, which is keyed by the sync config, the pod uid
+// and the container, so that the state is only reused if the sync restarts unchanged against the same container
+func getStatePath(pod *v1.Pod, container string, syncConfig *latest.SyncConfig) string {
+	key := hash.String(string(pod.UID) + ":" + container)
+	return filepath.Join(StateFolder, getStateConfigKey(syncConfig)+"-"+key[:16]+".json")
+}
+
+// getPermissionRules converts the permission options of the sync config into rules, the
+// options for all paths come first, so that the path specific ones override them
+func getPermissionRules(syncConfig *latest.SyncConfig) []permissions.Rule {
+	rules := []permissions.Rule{}
+	if syncConfig.FileMode != "" || syncConfig.DirMode != "" || syncConfig.Owner != "" || syncConfig.Group != "" {
+		rules = append(rules, permissions.Rule{
+			FileMode: syncConfig.FileMode,
+			DirMode:  syncConfig.DirMode,
+			Owner:    syncConfig.Owner,
+			Group:    syncConfig.Group,
+		})
+	}
+
+	for _, permission := range syncConfig.Permissions {
+		if permission == nil {
+			continue
+		}
+
+		rules = append(rules, permissions.Rule{
+			Path:     permission.Path,
+			FileMode: permission.FileMode,
+			DirMode:  permission.DirMode,
+			Owner:    permission.Owner,
+			Group:    permission.Group,
+		})
+	}
+
+	return rules
+}
+
+// getUploadRules converts the onUpload rules of the sync config into the rules of the helper
+func getUploadRules(syncConfig *latest.SyncConfig) []uploadrules.Rule {
+	rules := []uploadrules.Rule{}
+	if syncConfig.OnUpload == nil {
+		return rules
+	}
+
+	for _, rule := range syncConfig.OnUpload.Rules {
+		if rule == nil {
+			continue
+		}
+
+		rules = append(rules, uploadrules.Rule{
+			Path:    rule.Path,
+			Command: rule.Command,
+			Args:    rule.Args,
+		})
+	}
+
+	return rules
+}
+
 func getSyncCommands(cmd *latest.SyncExecCommand) (string, []string, string, []string) {
 	if cmd.Command != "" {
 		return cmd.Command, cmd.Args, cmd.Command, cmd.Args
@@ -0,0 +1,53 @@
+package synccontroller
+
+import (
+	"os"
+
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+)
+
+// DryRun connects to the helper in the selected container and calculates the changes the initial
+// sync would apply with the configured strategy, without changing any files
+func (c *controller) DryRun(options *Options, log logpkg.Logger) (*sync.Diff, error) {
+	options.TargetOptions.SkipInitContainers = true
+	syncConfig := options.SyncConfig
+
+	localPath := "."
+	if syncConfig.LocalSubPath != "" {
+		localPath = syncConfig.LocalSubPath
+	}
+
+	_, err := os.Stat(localPath)
+	if err != nil {
+		return nil, errors.Wrapf(err, "local path %s", localPath)
+	}
+
+	var container *kubectl.SelectedPodContainer
+	if syncConfig.PersistentVolumeClaim != nil {
+		container, err = c.startVolumePod(options, log)
+		if err != nil {
+			return nil, err
+		}
+
+		defer c.stopVolumePod(options)
+	} else {
+		container, err = c.selectContainer(options, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.StartWait("Calculate changes...")
+	defer log.StopWait()
+
+	syncClient, err := c.initClient(container.Pod, container.Container.Name, syncConfig, options.Verbose, options.SyncLog)
+	if err != nil {
+		return nil, errors.Wrap(err, "start sync")
+	}
+	defer syncClient.Stop(nil)
+
+	return syncClient.DryRun()
+}
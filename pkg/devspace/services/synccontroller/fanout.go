@@ -0,0 +1,170 @@
+package synccontroller
+
+import (
+	"context"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// fanOutInterval is the interval in which the controller looks for new or removed containers
+// of a sync that targets all matching containers
+var fanOutInterval = time.Second * 5
+
+// fanOut keeps an upload only sync running for every container that matches the sync config besides
+// the primary one, which is handled by the bidirectional sync of startWithWait. The targets are
+// reconciled until the bidirectional sync is stopped for good
+func (c *controller) fanOut(options *Options, log logpkg.Logger) {
+	targets := map[string]*sync.Sync{}
+	stopped := make(chan *sync.Sync)
+	exit := make(chan struct{})
+	defer close(exit)
+	defer func() {
+		for _, target := range targets {
+			target.Stop(nil)
+		}
+	}()
+
+	ticker := time.NewTicker(fanOutInterval)
+	defer ticker.Stop()
+
+	onStop := func(client *sync.Sync) {
+		select {
+		case stopped <- client:
+		case <-exit:
+		}
+	}
+
+	c.reconcileTargets(options, targets, onStop, log)
+	for {
+		select {
+		case <-c.stopped:
+			return
+		case client := <-stopped:
+			for key, target := range targets {
+				if target == client {
+					delete(targets, key)
+				}
+			}
+
+			unregisterSync(client)
+		case <-ticker.C:
+			c.reconcileTargets(options, targets, onStop, log)
+		}
+	}
+}
+
+// reconcileTargets starts a sync for every new secondary container and stops the syncs of containers that
+// are gone or became the primary
+func (c *controller) reconcileTargets(options *Options, targets map[string]*sync.Sync, onStop func(client *sync.Sync), log logpkg.Logger) {
+	selector := options.TargetOptions.Selector
+	selector.FilterPod = kubectl.FilterNonRunningPods
+	selector.FilterContainer = kubectl.FilterNonRunningContainers
+	selector.SkipInitContainers = true
+
+	containers, err := kubectl.NewFilter(c.client).SelectContainers(context.TODO(), selector)
+	if err != nil {
+		log.Warnf("Error selecting sync targets: %v", err)
+		return
+	}
+
+	c.primaryMutex.Lock()
+	primary := c.primary
+	c.primaryMutex.Unlock()
+
+	start, stop := diffTargets(containers, primary, targets)
+	for _, key := range stop {
+		targets[key].Stop(nil)
+		delete(targets, key)
+	}
+
+	for _, container := range start {
+		client, err := c.startSecondarySync(options, container, onStop, log)
+		if err != nil {
+			log.Warnf("Error starting sync to pod %s/%s: %v", container.Pod.Namespace, container.Pod.Name, err)
+			continue
+		}
+
+		targets[containerKey(container)] = client
+	}
+}
+
+// startSecondarySync starts a sync that only uploads local changes to the given container
+func (c *controller) startSecondarySync(options *Options, container *kubectl.SelectedPodContainer, onStop func(client *sync.Sync), log logpkg.Logger) (*sync.Sync, error) {
+	disableDownload := true
+	syncConfig := *options.SyncConfig
+	syncConfig.DisableDownload = &disableDownload
+
+	client, err := c.initClient(container.Pod, container.Container.Name, &syncConfig, options.Verbose, options.SyncLog)
+	if err != nil {
+		return nil, err
+	}
+
+	onDone := make(chan struct{})
+	err = client.Start(nil, nil, onDone, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-onDone
+		onStop(client)
+	}()
+
+	containerPath := "."
+	if syncConfig.ContainerPath != "" {
+		containerPath = syncConfig.ContainerPath
+	}
+
+	registerSync(client, containerPath, container.Pod.Namespace+"/"+container.Pod.Name)
+	log.Donef("Sync started on %s -> %s (Pod: %s/%s)", client.LocalPath, containerPath, container.Pod.Namespace, container.Pod.Name)
+	return client, nil
+}
+
+// diffTargets returns the containers a sync should be started for and the keys of the running
+// syncs that should be stopped
+func diffTargets(containers []*kubectl.SelectedPodContainer, primary string, targets map[string]*sync.Sync) ([]*kubectl.SelectedPodContainer, []string) {
+	start := []*kubectl.SelectedPodContainer{}
+	selected := map[string]bool{}
+	for _, container := range containers {
+		key := containerKey(container)
+		if key == primary {
+			continue
+		}
+
+		selected[key] = true
+		if _, ok := targets[key]; !ok {
+			start = append(start, container)
+		}
+	}
+
+	stop := []string{}
+	for key := range targets {
+		if !selected[key] {
+			stop = append(stop, key)
+		}
+	}
+
+	return start, stop
+}
+
+// filterNonPrimaryPods extends the given pod filter to also filter pods that do not match the
+// primary label selector
+func filterNonPrimaryPods(filter kubectl.FilterPod, primaryLabelSelector map[string]string) kubectl.FilterPod {
+	selector := labels.SelectorFromSet(primaryLabelSelector)
+	return func(p *v1.Pod) bool {
+		if filter != nil && filter(p) {
+			return true
+		}
+
+		return !selector.Matches(labels.Set(p.Labels))
+	}
+}
+
+func containerKey(container *kubectl.SelectedPodContainer) string {
+	return string(container.Pod.UID) + "/" + container.Container.Name
+}
@@ -0,0 +1,99 @@
+package synccontroller
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newSelectedContainer(uid string, labels map[string]string) *kubectl.SelectedPodContainer {
+	return &kubectl.SelectedPodContainer{
+		Pod: &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   uid,
+				UID:    types.UID(uid),
+				Labels: labels,
+			},
+		},
+		Container: &v1.Container{
+			Name: "container",
+		},
+	}
+}
+
+func TestDiffTargets(t *testing.T) {
+	containers := []*kubectl.SelectedPodContainer{
+		newSelectedContainer("primary", nil),
+		newSelectedContainer("running", nil),
+		newSelectedContainer("new", nil),
+	}
+	targets := map[string]*sync.Sync{
+		"running/container": {},
+		"gone/container":    {},
+		"primary/container": {},
+	}
+
+	start, stop := diffTargets(containers, "primary/container", targets)
+	if len(start) != 1 || containerKey(start[0]) != "new/container" {
+		t.Fatalf("Expected to start a sync for new/container, got %v", start)
+	}
+
+	sort.Strings(stop)
+	if len(stop) != 2 || stop[0] != "gone/container" || stop[1] != "primary/container" {
+		t.Fatalf("Expected to stop the syncs of gone/container and primary/container, got %v", stop)
+	}
+}
+
+func TestFilterNonPrimaryPods(t *testing.T) {
+	filter := filterNonPrimaryPods(func(p *v1.Pod) bool {
+		return p.Name == "filtered"
+	}, map[string]string{"role": "primary"})
+
+	testCases := map[string]bool{
+		"primary":  false,
+		"other":    true,
+		"filtered": true,
+	}
+	for name, expected := range testCases {
+		labels := map[string]string{"role": name}
+		if name == "filtered" {
+			labels["role"] = "primary"
+		}
+
+		if filter(newSelectedContainer(name, labels).Pod) != expected {
+			t.Fatalf("Expected pod %s to be filtered: %v", name, expected)
+		}
+	}
+}
+
+func TestFanOutStopsWithPrimary(t *testing.T) {
+	c := &controller{
+		client:  &kubectltesting.Client{Client: fake.NewSimpleClientset()},
+		log:     logpkg.Discard,
+		stopped: make(chan struct{}),
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.fanOut(&Options{SyncConfig: &latest.SyncConfig{AllContainers: true}}, logpkg.Discard)
+		close(done)
+	}()
+
+	// options without interrupt and done channels as used by devspace dev
+	c.stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Fan out was not stopped with the bidirectional sync")
+	}
+}
@@ -0,0 +1,98 @@
+package synccontroller
+
+import (
+	"sort"
+	"strings"
+	gosync "sync"
+
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// ProgressEvent is the progress of an upload or download of one of the syncs of this process
+type ProgressEvent struct {
+	sync.Progress
+
+	LocalPath     string `json:"localPath"`
+	ContainerPath string `json:"containerPath"`
+	Pod           string `json:"pod"`
+}
+
+// progressSubscribers hold the channels that receive the progress events, e.g. of the ui server
+var (
+	progressSubscribers      = map[chan ProgressEvent]bool{}
+	progressSubscribersMutex gosync.Mutex
+)
+
+// SubscribeProgress returns a channel that receives the progress events of all syncs of this process
+// until the returned function is called. Events are dropped if the receiver can't keep up
+func SubscribeProgress() (<-chan ProgressEvent, func()) {
+	progressSubscribersMutex.Lock()
+	defer progressSubscribersMutex.Unlock()
+
+	events := make(chan ProgressEvent, 100)
+	progressSubscribers[events] = true
+	return events, func() {
+		progressSubscribersMutex.Lock()
+		defer progressSubscribersMutex.Unlock()
+
+		delete(progressSubscribers, events)
+	}
+}
+
+func publishProgress(event ProgressEvent) {
+	progressSubscribersMutex.Lock()
+	defer progressSubscribersMutex.Unlock()
+
+	for events := range progressSubscribers {
+		select {
+		case events <- event:
+		default:
+		}
+	}
+}
+
+// progressLine holds the running transfers of all syncs of this process, which share a single status line
+// in the log, so that the syncs don't clear each other's line
+var (
+	progressLine      = map[string]string{}
+	progressLineShown bool
+	progressLineMutex gosync.Mutex
+)
+
+// showProgress updates the transfer of the event in the status line of the log. The line is only cleared
+// if it was shown by the syncs, so that other wait messages are kept
+func showProgress(log logpkg.Logger, event ProgressEvent) {
+	progressLineMutex.Lock()
+	defer progressLineMutex.Unlock()
+
+	key := event.Pod + ":" + event.ContainerPath + ":" + event.LocalPath + ":" + event.Direction
+	if event.Done {
+		delete(progressLine, key)
+	} else {
+		progressLine[key] = "[" + event.Pod + ":" + event.ContainerPath + "] " + event.Progress.String()
+	}
+
+	if len(progressLine) == 0 {
+		if progressLineShown {
+			log.StopWait()
+			progressLineShown = false
+		}
+
+		return
+	}
+
+	keys := make([]string, 0, len(progressLine))
+	for key := range progressLine {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		lines = append(lines, progressLine[key])
+	}
+
+	log.StartWait(strings.Join(lines, " | "))
+	progressLineShown = true
+}
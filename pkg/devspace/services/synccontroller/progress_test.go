@@ -0,0 +1,64 @@
+package synccontroller
+
+import (
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+)
+
+// waitLogger records the status line of the log
+type waitLogger struct {
+	*logpkg.DiscardLogger
+
+	line string
+}
+
+func (w *waitLogger) StartWait(message string) {
+	w.line = message
+}
+
+func (w *waitLogger) StopWait() {
+	w.line = ""
+}
+
+func TestShowProgress(t *testing.T) {
+	logger := &waitLogger{DiscardLogger: logpkg.Discard}
+	upload := ProgressEvent{
+		Progress:      sync.Progress{Direction: sync.ProgressDirectionUpload, Files: 1, BytesTotal: 10, ETA: -1},
+		ContainerPath: "/app",
+		Pod:           "default/first",
+	}
+	download := ProgressEvent{
+		Progress:      sync.Progress{Direction: sync.ProgressDirectionDownload, Files: 2, BytesTotal: 20, ETA: -1},
+		ContainerPath: "/app",
+		Pod:           "default/second",
+	}
+
+	// the transfers of all syncs are shown in the same line
+	showProgress(logger, upload)
+	showProgress(logger, download)
+	if logger.line != "[default/first:/app] "+upload.String()+" | [default/second:/app] "+download.String() {
+		t.Fatalf("Unexpected status line %s", logger.line)
+	}
+
+	// a finished transfer doesn't clear the transfer of another sync
+	upload.Done = true
+	showProgress(logger, upload)
+	if logger.line != "[default/second:/app] "+download.String() {
+		t.Fatalf("Unexpected status line %s", logger.line)
+	}
+
+	download.Done = true
+	showProgress(logger, download)
+	if logger.line != "" {
+		t.Fatalf("Expected cleared status line, got %s", logger.line)
+	}
+
+	// a wait message of another component is not cleared
+	logger.StartWait("Waiting for pods...")
+	showProgress(logger, download)
+	if logger.line != "Waiting for pods..." {
+		t.Fatalf("Wait message was cleared")
+	}
+}
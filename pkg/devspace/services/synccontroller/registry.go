@@ -0,0 +1,142 @@
+package synccontroller
+
+import (
+	"context"
+	"sort"
+	gosync "sync"
+
+	"github.com/loft-sh/devspace/pkg/devspace/sync"
+	"github.com/pkg/errors"
+)
+
+// Conflict is a conflict that was detected by one of the syncs of this process
+type Conflict struct {
+	sync.Conflict
+
+	LocalPath     string `json:"localPath"`
+	ContainerPath string `json:"containerPath"`
+	Pod           string `json:"pod"`
+}
+
+// Status is the runtime state of one of the syncs of this process
+type Status struct {
+	sync.Status
+
+	LocalPath     string `json:"localPath"`
+	ContainerPath string `json:"containerPath"`
+	Pod           string `json:"pod"`
+	Conflicts     int    `json:"conflicts"`
+
+	// Collisions are the remote paths that are not synced, because they collide with another path locally
+	Collisions []sync.Collision `json:"collisions,omitempty"`
+}
+
+type runningSync struct {
+	client *sync.Sync
+
+	containerPath string
+	pod           string
+}
+
+// running holds the syncs started by this process, so that their state can be retrieved
+// by the ui server. Several syncs can share the same local and container path, if they
+// sync to different containers
+var (
+	running      = map[*sync.Sync]*runningSync{}
+	runningMutex gosync.Mutex
+)
+
+func registerSync(client *sync.Sync, containerPath, pod string) {
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+
+	running[client] = &runningSync{
+		client:        client,
+		containerPath: containerPath,
+		pod:           pod,
+	}
+}
+
+// unregisterSync removes a sync that was replaced by a restarted one or whose container is gone
+func unregisterSync(client *sync.Sync) {
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+
+	delete(running, client)
+}
+
+// Conflicts returns the latest conflicts of all syncs that were started by this process
+func Conflicts() []Conflict {
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+
+	conflicts := []Conflict{}
+	for _, s := range running {
+		for _, conflict := range s.client.Conflicts() {
+			conflicts = append(conflicts, Conflict{
+				Conflict:      conflict,
+				LocalPath:     s.client.LocalPath,
+				ContainerPath: s.containerPath,
+				Pod:           s.pod,
+			})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		return conflicts[i].Time.Before(conflicts[j].Time)
+	})
+	return conflicts
+}
+
+// Statuses returns the runtime state of all syncs that were started by this process
+func Statuses() []Status {
+	runningMutex.Lock()
+	defer runningMutex.Unlock()
+
+	statuses := make([]Status, 0, len(running))
+	for _, s := range running {
+		statuses = append(statuses, Status{
+			Status:        s.client.Status(),
+			LocalPath:     s.client.LocalPath,
+			ContainerPath: s.containerPath,
+			Pod:           s.pod,
+			Conflicts:     len(s.client.Conflicts()),
+			Collisions:    s.client.Collisions(),
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].LocalPath == statuses[j].LocalPath {
+			if statuses[i].ContainerPath == statuses[j].ContainerPath {
+				return statuses[i].Pod < statuses[j].Pod
+			}
+
+			return statuses[i].ContainerPath < statuses[j].ContainerPath
+		}
+
+		return statuses[i].LocalPath < statuses[j].LocalPath
+	})
+	return statuses
+}
+
+// WaitForUploads blocks until all running syncs of this process have uploaded the local
+// changes that were observed before the call
+func WaitForUploads(ctx context.Context) error {
+	runningMutex.Lock()
+	syncs := make([]*runningSync, 0, len(running))
+	for _, s := range running {
+		if s.client.Status().Stopped == false {
+			syncs = append(syncs, s)
+		}
+	}
+	runningMutex.Unlock()
+
+	for _, s := range syncs {
+		err := s.client.WaitForUpload(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "sync %s to %s:%s", s.client.LocalPath, s.pod, s.containerPath)
+		}
+	}
+
+	return nil
+}
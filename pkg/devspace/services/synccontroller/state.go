@@ -0,0 +1,102 @@
+package synccontroller
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+)
+
+// stateConfig holds the options of a sync config that the persisted sync state depends on. A state that was
+// saved with other options would let the sync make decisions based on files that were selected differently
+type stateConfig struct {
+	LocalPath            string                      `json:"localPath"`
+	ContainerPath        string                      `json:"containerPath"`
+	ExcludePaths         []string                    `json:"excludePaths,omitempty"`
+	DownloadExcludePaths []string                    `json:"downloadExcludePaths,omitempty"`
+	UploadExcludePaths   []string                    `json:"uploadExcludePaths,omitempty"`
+	UseGitignore         bool                        `json:"useGitignore,omitempty"`
+	UseDockerignore      bool                        `json:"useDockerignore,omitempty"`
+	Symlinks             latest.SyncSymlinks         `json:"symlinks,omitempty"`
+	InitialSync          latest.InitialSyncStrategy  `json:"initialSync,omitempty"`
+	InitialSyncCompareBy latest.InitialSyncCompareBy `json:"initialSyncCompareBy,omitempty"`
+	ConflictStrategy     latest.ConflictStrategy     `json:"conflictStrategy,omitempty"`
+}
+
+// getStateConfigKey returns the prefix of the state files of a sync config, which is derived from the
+// synced paths and the options that select and compare the synced files, so that the states of sync
+// configs that were removed or changed can be found
+func getStateConfigKey(syncConfig *latest.SyncConfig) string {
+	localPath := "."
+	if syncConfig.LocalSubPath != "" {
+		localPath = syncConfig.LocalSubPath
+	}
+	absLocalPath, err := filepath.Abs(localPath)
+	if err == nil {
+		localPath = absLocalPath
+	}
+
+	containerPath := "."
+	if syncConfig.ContainerPath != "" {
+		containerPath = syncConfig.ContainerPath
+	}
+
+	out, err := json.Marshal(&stateConfig{
+		LocalPath:            localPath,
+		ContainerPath:        containerPath,
+		ExcludePaths:         syncConfig.ExcludePaths,
+		DownloadExcludePaths: syncConfig.DownloadExcludePaths,
+		UploadExcludePaths:   syncConfig.UploadExcludePaths,
+		UseGitignore:         syncConfig.UseGitignore,
+		UseDockerignore:      syncConfig.UseDockerignore,
+		Symlinks:             syncConfig.Symlinks,
+		InitialSync:          syncConfig.InitialSync,
+		InitialSyncCompareBy: syncConfig.InitialSyncCompareBy,
+		ConflictStrategy:     syncConfig.ConflictStrategy,
+	})
+	if err != nil {
+		out = []byte(containerPath + ":" + localPath)
+	}
+
+	return hash.String(string(out))[:8]
+}
+
+// PruneStates removes the persisted sync states in the state folder that don't belong to any of the
+// given sync configs anymore
+func PruneStates(stateFolder string, syncConfigs []*latest.SyncConfig) error {
+	files, err := ioutil.ReadDir(stateFolder)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	keys := map[string]bool{}
+	for _, syncConfig := range syncConfigs {
+		keys[getStateConfigKey(syncConfig)] = true
+	}
+
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+
+		prefix := strings.Split(file.Name(), "-")[0]
+		if keys[prefix] && strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+
+		err = os.Remove(filepath.Join(stateFolder, file.Name()))
+		if err != nil && os.IsNotExist(err) == false {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package synccontroller
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPruneStates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sync-state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{UID: types.UID("pod")}}
+	configured := &latest.SyncConfig{LocalSubPath: "/app", ContainerPath: "/app", ExcludePaths: []string{"node_modules"}}
+	otherContainerPath := &latest.SyncConfig{LocalSubPath: "/app", ContainerPath: "/other", ExcludePaths: []string{"node_modules"}}
+	otherLocalPath := &latest.SyncConfig{LocalSubPath: "/other", ContainerPath: "/app", ExcludePaths: []string{"node_modules"}}
+	otherExcludes := &latest.SyncConfig{LocalSubPath: "/app", ContainerPath: "/app"}
+	otherSymlinks := &latest.SyncConfig{LocalSubPath: "/app", ContainerPath: "/app", ExcludePaths: []string{"node_modules"}, Symlinks: latest.SyncSymlinksPreserve}
+	files := map[string]bool{
+		filepath.Base(getStatePath(pod, "first", configured)):          true,
+		filepath.Base(getStatePath(pod, "second", configured)):         true,
+		filepath.Base(getStatePath(pod, "first", otherContainerPath)):  false,
+		filepath.Base(getStatePath(pod, "first", otherLocalPath)):      false,
+		filepath.Base(getStatePath(pod, "first", otherExcludes)):       false,
+		filepath.Base(getStatePath(pod, "first", otherSymlinks)):       false,
+		filepath.Base(getStatePath(pod, "first", configured)) + ".tmp": false,
+		"0123456789abcdef.json":                                        false,
+	}
+	for name := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = PruneStates(dir, []*latest.SyncConfig{configured})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, kept := range files {
+		_, err := os.Stat(filepath.Join(dir, name))
+		if kept && err != nil {
+			t.Fatalf("State %s of configured sync was removed", name)
+		} else if kept == false && err == nil {
+			t.Fatalf("Stale state %s was not removed", name)
+		}
+	}
+
+	// a missing state folder is not an error
+	err = PruneStates(filepath.Join(dir, "missing"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,266 @@
+package synccontroller
+
+import (
+	"context"
+	gosync "sync"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/devspace/kubectl"
+	"github.com/loft-sh/devspace/pkg/util/exit"
+	"github.com/loft-sh/devspace/pkg/util/hash"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// volumeHelperImage is the image of the helper pod that mounts a persistent volume claim by default
+	volumeHelperImage = "alpine"
+
+	// volumeHelperContainer is the name of the container within the helper pod
+	volumeHelperContainer = "sync"
+
+	// volumeMountPath is the path the persistent volume claim is mounted at within the helper pod
+	volumeMountPath = "/volume"
+
+	// volumeClaimAnnotation holds the name of the claim a helper pod was created for
+	volumeClaimAnnotation = "devspace.sh/sync-volume"
+)
+
+// volumePodTimeout is the time to wait for the helper pod to become running
+var volumePodTimeout = time.Minute * 5
+
+type volumePod struct {
+	client    kubectl.Client
+	namespace string
+	name      string
+
+	// users are the syncs that use the helper pod, the pod is only deleted when the last one stopped
+	users map[*Options]bool
+}
+
+// volumePods holds the helper pods created by this process, so that they can be deleted if the
+// process exits
+var (
+	volumePods      = map[string]*volumePod{}
+	volumePodsMutex gosync.Mutex
+)
+
+// startVolumePod creates or reuses the helper pod for the persistent volume claim of the sync config and
+// waits until it is running
+func (c *controller) startVolumePod(options *Options, log logpkg.Logger) (*kubectl.SelectedPodContainer, error) {
+	namespace := c.volumeNamespace(options)
+	name := volumePodName(options.SyncConfig.PersistentVolumeClaim.Name)
+
+	// register the sync before the pod is started, so that other syncs of the same claim don't delete it
+	acquireVolumePod(c.client, namespace, name, options)
+	container, err := c.waitForVolumePod(options, namespace, name, log)
+	if err != nil {
+		c.stopVolumePod(options)
+		return nil, err
+	}
+
+	return container, nil
+}
+
+// waitForVolumePod creates the helper pod if it doesn't exist yet and waits until it is running
+func (c *controller) waitForVolumePod(options *Options, namespace, name string, log logpkg.Logger) (*kubectl.SelectedPodContainer, error) {
+	claim := options.SyncConfig.PersistentVolumeClaim
+	pods := c.client.KubeClient().CoreV1().Pods(namespace)
+	existing, err := pods.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil && kerrors.IsNotFound(err) == false {
+		return nil, errors.Wrap(err, "get sync helper pod")
+	} else if err == nil && (existing.DeletionTimestamp != nil || existing.Status.Phase == v1.PodSucceeded || existing.Status.Phase == v1.PodFailed) {
+		log.Infof("Recreate sync helper pod %s/%s", namespace, name)
+		err = deleteVolumePod(c.client, namespace, name)
+		if err != nil {
+			return nil, err
+		}
+
+		err = wait.PollImmediate(time.Second, volumePodTimeout, func() (bool, error) {
+			_, err := pods.Get(context.TODO(), name, metav1.GetOptions{})
+			return kerrors.IsNotFound(err), nil
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "wait for sync helper pod %s/%s to be deleted", namespace, name)
+		}
+
+		existing = nil
+	} else if err != nil {
+		existing = nil
+	}
+
+	if existing == nil {
+		log.Infof("Start sync helper pod %s/%s for persistent volume claim %s", namespace, name, claim.Name)
+		_, err = pods.Create(context.TODO(), getVolumePod(name, namespace, claim), metav1.CreateOptions{})
+		if err != nil && kerrors.IsAlreadyExists(err) == false {
+			return nil, errors.Wrap(err, "create sync helper pod")
+		}
+	}
+
+	var pod *v1.Pod
+	err = wait.PollImmediate(time.Second, volumePodTimeout, func() (bool, error) {
+		pod, err = pods.Get(context.TODO(), name, metav1.GetOptions{})
+		if err != nil {
+			if kerrors.IsNotFound(err) {
+				return false, nil
+			}
+
+			return false, err
+		}
+
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Name != volumeHelperContainer {
+				continue
+			} else if status.State.Waiting != nil && kubectl.CriticalStatus[status.State.Waiting.Reason] {
+				return false, errors.Errorf("sync helper pod %s/%s cannot start: %s (%s)", namespace, name, status.State.Waiting.Message, status.State.Waiting.Reason)
+			}
+
+			return status.State.Running != nil, nil
+		}
+
+		return false, nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "wait for sync helper pod %s/%s", namespace, name)
+	}
+
+	return &kubectl.SelectedPodContainer{
+		Pod:       pod,
+		Container: &pod.Spec.Containers[0],
+	}, nil
+}
+
+// stopVolumePod releases the helper pod of the sync config and deletes it if no other sync uses it anymore
+func (c *controller) stopVolumePod(options *Options) {
+	volumePodsMutex.Lock()
+	defer volumePodsMutex.Unlock()
+
+	key := c.volumeNamespace(options) + "/" + volumePodName(options.SyncConfig.PersistentVolumeClaim.Name)
+	volumePod, ok := volumePods[key]
+	if !ok || volumePod.users[options] == false {
+		return
+	}
+
+	delete(volumePod.users, options)
+	if len(volumePod.users) > 0 {
+		return
+	}
+
+	err := deleteVolumePod(volumePod.client, volumePod.namespace, volumePod.name)
+	if err != nil {
+		c.log.Warnf("Error deleting sync helper pod %s: %v", key, err)
+	}
+
+	delete(volumePods, key)
+}
+
+func (c *controller) volumeNamespace(options *Options) string {
+	if options.TargetOptions.Namespace != "" {
+		return options.TargetOptions.Namespace
+	}
+
+	return c.client.Namespace()
+}
+
+// acquireVolumePod registers the sync as user of the helper pod, so that the pod is deleted when the last
+// sync stopped or the process exits
+func acquireVolumePod(client kubectl.Client, namespace, name string, options *Options) {
+	volumePodsMutex.Lock()
+	key := namespace + "/" + name
+	if volumePods[key] == nil {
+		volumePods[key] = &volumePod{
+			client:    client,
+			namespace: namespace,
+			name:      name,
+			users:     map[*Options]bool{},
+		}
+	}
+	volumePods[key].users[options] = true
+	volumePodsMutex.Unlock()
+
+	exit.RegisterCleanup("sync-volume-pods", deleteVolumePods)
+}
+
+// deleteVolumePods deletes all helper pods that were created by this process
+func deleteVolumePods() {
+	volumePodsMutex.Lock()
+	defer volumePodsMutex.Unlock()
+
+	for key, volumePod := range volumePods {
+		_ = deleteVolumePod(volumePod.client, volumePod.namespace, volumePod.name)
+		delete(volumePods, key)
+	}
+}
+
+func deleteVolumePod(client kubectl.Client, namespace, name string) error {
+	gracePeriod := int64(1)
+	err := client.KubeClient().CoreV1().Pods(namespace).Delete(context.TODO(), name, metav1.DeleteOptions{
+		GracePeriodSeconds: &gracePeriod,
+	})
+	if err != nil && kerrors.IsNotFound(err) == false {
+		return errors.Wrapf(err, "delete sync helper pod %s/%s", namespace, name)
+	}
+
+	return nil
+}
+
+// volumePodName returns the name of the helper pod for the given claim
+func volumePodName(claim string) string {
+	name := "devspace-sync-" + claim
+	if len(name) > 63 {
+		name = "devspace-sync-" + hash.String(claim)[:16]
+	}
+
+	return name
+}
+
+func getVolumePod(name, namespace string, claim *latest.SyncPersistentVolumeClaim) *v1.Pod {
+	image := volumeHelperImage
+	if claim.Image != "" {
+		image = claim.Image
+	}
+
+	gracePeriod := int64(1)
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				volumeClaimAnnotation: claim.Name,
+			},
+		},
+		Spec: v1.PodSpec{
+			TerminationGracePeriodSeconds: &gracePeriod,
+			RestartPolicy:                 v1.RestartPolicyNever,
+			Containers: []v1.Container{
+				{
+					Name:       volumeHelperContainer,
+					Image:      image,
+					Command:    []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 1; done"},
+					WorkingDir: volumeMountPath,
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      "volume",
+							MountPath: volumeMountPath,
+						},
+					},
+				},
+			},
+			Volumes: []v1.Volume{
+				{
+					Name: "volume",
+					VolumeSource: v1.VolumeSource{
+						PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+							ClaimName: claim.Name,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package synccontroller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	kubectltesting "github.com/loft-sh/devspace/pkg/devspace/kubectl/testing"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestVolumePodName(t *testing.T) {
+	if name := volumePodName("data"); name != "devspace-sync-data" {
+		t.Fatalf("Unexpected pod name %s", name)
+	}
+
+	name := volumePodName(strings.Repeat("a", 60))
+	if len(name) > 63 || strings.HasPrefix(name, "devspace-sync-") == false {
+		t.Fatalf("Unexpected pod name %s", name)
+	}
+}
+
+func TestStartVolumePod(t *testing.T) {
+	claim := &latest.SyncPersistentVolumeClaim{Name: "data"}
+	pod := getVolumePod(volumePodName(claim.Name), "testNamespace", claim)
+	if pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName != "data" || pod.Spec.Containers[0].Image != volumeHelperImage {
+		t.Fatalf("Unexpected helper pod %#+v", pod.Spec)
+	}
+
+	// a running helper pod is reused
+	pod.Status.ContainerStatuses = []v1.ContainerStatus{
+		{
+			Name: volumeHelperContainer,
+			State: v1.ContainerState{
+				Running: &v1.ContainerStateRunning{},
+			},
+		},
+	}
+	kubeClient := &kubectltesting.Client{
+		Client: fake.NewSimpleClientset(pod),
+	}
+	c := &controller{
+		client: kubeClient,
+		log:    logpkg.Discard,
+	}
+	options := &Options{
+		SyncConfig: &latest.SyncConfig{
+			PersistentVolumeClaim: claim,
+		},
+	}
+
+	container, err := c.startVolumePod(options, logpkg.Discard)
+	if err != nil {
+		t.Fatal(err)
+	} else if container.Pod.Name != "devspace-sync-data" || container.Container.Name != volumeHelperContainer {
+		t.Fatalf("Unexpected container %s/%s", container.Pod.Name, container.Container.Name)
+	}
+
+	// a second sync of the same claim shares the helper pod
+	other := &Options{
+		SyncConfig: &latest.SyncConfig{
+			PersistentVolumeClaim: claim,
+		},
+	}
+	_, err = c.startVolumePod(other, logpkg.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.stopVolumePod(other)
+	_, err = kubeClient.Client.CoreV1().Pods("testNamespace").Get(context.TODO(), "devspace-sync-data", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Helper pod was deleted while it is still used: %v", err)
+	}
+
+	c.stopVolumePod(options)
+	_, err = kubeClient.Client.CoreV1().Pods("testNamespace").Get(context.TODO(), "devspace-sync-data", metav1.GetOptions{})
+	if err == nil {
+		t.Fatal("Expected helper pod to be deleted")
+	}
+}
@@ -0,0 +1,215 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/pkg/errors"
+)
+
+// maxConflicts is the maximum number of conflicts that are remembered by a sync
+const maxConflicts = 100
+
+// ConflictSuffix is appended to the remote version of a conflicting file if both versions are kept
+const ConflictSuffix = ".conflict"
+
+// ConflictResolution describes how a conflict was resolved
+type ConflictResolution string
+
+// List of values that a conflict resolution can take
+const (
+	ConflictResolutionKeepLocal  ConflictResolution = "kept local version"
+	ConflictResolutionKeepRemote ConflictResolution = "kept remote version"
+	ConflictResolutionKeepBoth   ConflictResolution = "kept both versions"
+)
+
+// Conflict is a file that was changed locally and in the container since it was synced the last time
+type Conflict struct {
+	Path       string             `json:"path"`
+	Resolution ConflictResolution `json:"resolution"`
+	Time       time.Time          `json:"time"`
+}
+
+// Conflicts returns the latest conflicts that were detected by the sync
+func (s *Sync) Conflicts() []Conflict {
+	s.conflictsMutex.Lock()
+	defer s.conflictsMutex.Unlock()
+
+	conflicts := make([]Conflict, len(s.conflicts))
+	copy(conflicts, s.conflicts)
+	return conflicts
+}
+
+// resolveConflict decides which version of a conflicting file should be kept. Without a configured
+// conflict strategy the version with the newer modification time is kept
+func (s *Sync) resolveConflict(localMtime, remoteMtime int64) ConflictResolution {
+	switch s.Options.ConflictStrategy {
+	case latest.ConflictStrategyPreferLocal:
+		return ConflictResolutionKeepLocal
+	case latest.ConflictStrategyPreferRemote:
+		return ConflictResolutionKeepRemote
+	case latest.ConflictStrategyKeepBoth:
+		return ConflictResolutionKeepBoth
+	}
+
+	if localMtime > remoteMtime {
+		return ConflictResolutionKeepLocal
+	}
+
+	return ConflictResolutionKeepRemote
+}
+
+func (s *Sync) reportConflict(relativePath string, resolution ConflictResolution) {
+	s.log.Warnf("Conflict - '.%s' was changed locally and in the container, %s", relativePath, resolution)
+
+	s.conflictsMutex.Lock()
+	defer s.conflictsMutex.Unlock()
+
+	s.conflicts = append(s.conflicts, Conflict{
+		Path:       relativePath,
+		Resolution: resolution,
+		Time:       time.Now(),
+	})
+	if len(s.conflicts) > maxConflicts {
+		s.conflicts = s.conflicts[len(s.conflicts)-maxConflicts:]
+	}
+}
+
+// isLocallyChanged checks if the local file changed since it was synced the last time. Before the initial
+// sync is completed the file index doesn't represent a synced state yet, so no changes are reported.
+// s.fileIndex needs to be locked before this function is called
+func (s *Sync) isLocallyChanged(relativePath string, stat os.FileInfo) bool {
+	if stat == nil || stat.IsDir() || s.isInitialSyncCompleted() == false {
+		return false
+	}
+
+	synced := s.fileIndex.fileMap[relativePath]
+	return synced == nil || synced.IsDirectory || synced.Size != stat.Size() || synced.Mtime != stat.ModTime().Unix()
+}
+
+// uploadKeptLocal uploads the local versions of the conflicting files that were kept
+// during the last download
+func (d *downstream) uploadKeptLocal() {
+	d.sync.fileIndex.fileMapMutex.Lock()
+	keptLocal := d.unarchiver.keptLocal
+	d.unarchiver.keptLocal = nil
+	d.sync.fileIndex.fileMapMutex.Unlock()
+
+	if len(keptLocal) == 0 || d.sync.Options.UpstreamDisabled || d.sync.upstream == nil {
+		return
+	}
+
+	for _, relativePath := range keptLocal {
+		stat, err := os.Stat(filepath.Join(d.sync.LocalPath, relativePath))
+		if err != nil || stat.IsDir() {
+			continue
+		}
+
+		// We do this out of the fileIndex lock, because otherwise this could cause a deadlock
+		d.sync.upstream.isBusyMutex.Lock()
+		d.sync.upstream.isBusy = true
+		d.sync.upstream.events <- createFileInformationFromStat(relativePath, stat)
+		d.sync.upstream.isBusyMutex.Unlock()
+	}
+}
+
+// checkConflicts checks if the files that should be uploaded were changed in the container as well since
+// they were synced the last time and returns the files that should still be uploaded.
+// s.fileIndex needs to be locked before this function is called
+func (u *upstream) checkConflicts(files []*FileInformation) []*FileInformation {
+	paths := []string{}
+	for _, file := range files {
+		synced := u.sync.fileIndex.fileMap[file.Name]
+		if file.IsDirectory || synced == nil || synced.IsDirectory || synced.IsSymbolicLink {
+			continue
+		}
+
+		paths = append(paths, file.Name)
+	}
+	if len(paths) == 0 || u.sync.isInitialSyncCompleted() == false {
+		return files
+	}
+
+	remoteStats, err := u.collectStats(paths)
+	if err != nil {
+		u.sync.log.Infof("Upstream - Couldn't check for conflicts: %v", err)
+		return files
+	}
+
+	upload := make([]*FileInformation, 0, len(files))
+	for _, file := range files {
+		remoteStat := remoteStats[file.Name]
+		synced := u.sync.fileIndex.fileMap[file.Name]
+		if remoteStat == nil || remoteStat.ChangeType == remote.ChangeType_DELETE || remoteStat.IsDir || synced == nil {
+			upload = append(upload, file)
+			continue
+		}
+
+		// only if the downstream will download the remote change we have a conflict
+		if remoteStat.MtimeUnix < synced.Mtime || (remoteStat.MtimeUnix == synced.Mtime && remoteStat.Size == synced.Size) || (remoteStat.LinkTarget != "" && remoteStat.LinkTarget == synced.LinkTarget) {
+			upload = append(upload, file)
+			continue
+		}
+
+		resolution := u.sync.resolveConflict(file.Mtime, remoteStat.MtimeUnix)
+		if resolution == ConflictResolutionKeepLocal {
+			u.sync.reportConflict(file.Name, resolution)
+			upload = append(upload, file)
+		} else if u.sync.Options.Verbose {
+			// the conflict is resolved and reported by the downstream
+			u.sync.log.Infof("Upstream - Skip upload of '%s' because it was changed in the container as well", u.getRelativeUpstreamPath(file.Name))
+		}
+	}
+
+	return upload
+}
+
+func (u *upstream) collectStats(paths []string) (map[string]*remote.Change, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*30)
+	defer cancel()
+
+	statsClient, err := u.client.Stats(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "start retrieving stats")
+	}
+
+	for j := 0; j < len(paths); j += removeFilesBufferSize {
+		end := j + removeFilesBufferSize
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		err = statsClient.Send(&remote.Paths{Paths: paths[j:end]})
+		if err != nil {
+			return nil, errors.Wrap(err, "send paths")
+		}
+	}
+
+	err = statsClient.CloseSend()
+	if err != nil {
+		return nil, errors.Wrap(err, "close send")
+	}
+
+	stats := make(map[string]*remote.Change, len(paths))
+	for {
+		chunk, err := statsClient.Recv()
+		if chunk != nil {
+			for _, change := range chunk.Changes {
+				stats[change.Path] = change
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "recv stats")
+		}
+	}
+
+	return stats, nil
+}
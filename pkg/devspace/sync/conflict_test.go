@@ -0,0 +1,148 @@
+package sync
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+type conflictTestCase struct {
+	name     string
+	strategy latest.ConflictStrategy
+
+	expectedContent   string
+	expectedConflict  string
+	expectedKeptLocal bool
+}
+
+func TestDownloadConflict(t *testing.T) {
+	testCases := []conflictTestCase{
+		{
+			name:              "Prefer local",
+			strategy:          latest.ConflictStrategyPreferLocal,
+			expectedContent:   "local change",
+			expectedKeptLocal: true,
+		},
+		{
+			name:            "Prefer remote",
+			strategy:        latest.ConflictStrategyPreferRemote,
+			expectedContent: "remote change",
+		},
+		{
+			name:              "Keep both",
+			strategy:          latest.ConflictStrategyKeepBoth,
+			expectedContent:   "local change",
+			expectedConflict:  "remote change",
+			expectedKeptLocal: true,
+		},
+		{
+			name:            "Newest wins",
+			expectedContent: "remote change",
+		},
+	}
+
+	syncedMtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	localMtime := time.Now().Add(-time.Minute).Truncate(time.Second)
+	remoteMtime := time.Now().Truncate(time.Second)
+	for _, testCase := range testCases {
+		local, err := ioutil.TempDir("", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(local, "file"), []byte("local change"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = os.Chtimes(filepath.Join(local, "file"), localMtime, localMtime)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sync, err := NewSync(local, Options{
+			ConflictStrategy: testCase.strategy,
+			Log:              log.Discard,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sync.initialSyncCompleted = true
+		sync.fileIndex.Set(&FileInformation{
+			Name:  "/file",
+			Size:  int64(len("synced")),
+			Mtime: syncedMtime.Unix(),
+		})
+
+		unarchiver := NewUnarchiver(sync, false, log.Discard)
+		err = unarchiver.Untar(createTestArchive(t, "file", "remote change", remoteMtime), compression.Legacy, sync.LocalPath)
+		if err != nil {
+			t.Fatalf("Error in test case %s: %v", testCase.name, err)
+		}
+
+		content, err := ioutil.ReadFile(filepath.Join(local, "file"))
+		if err != nil {
+			t.Fatal(err)
+		} else if string(content) != testCase.expectedContent {
+			t.Fatalf("Error in test case %s: expected content %s, got %s", testCase.name, testCase.expectedContent, string(content))
+		}
+
+		conflictContent, err := ioutil.ReadFile(filepath.Join(local, "file"+ConflictSuffix))
+		if testCase.expectedConflict == "" && err == nil {
+			t.Fatalf("Error in test case %s: unexpected conflict file", testCase.name)
+		} else if testCase.expectedConflict != "" && string(conflictContent) != testCase.expectedConflict {
+			t.Fatalf("Error in test case %s: expected conflict file content %s, got %s (%v)", testCase.name, testCase.expectedConflict, string(conflictContent), err)
+		}
+
+		if testCase.expectedKeptLocal != (len(unarchiver.keptLocal) == 1) {
+			t.Fatalf("Error in test case %s: expected kept local %v, got %v", testCase.name, testCase.expectedKeptLocal, unarchiver.keptLocal)
+		}
+		if len(sync.Conflicts()) != 1 {
+			t.Fatalf("Error in test case %s: expected 1 reported conflict, got %d", testCase.name, len(sync.Conflicts()))
+		}
+
+		os.RemoveAll(local)
+	}
+}
+
+func createTestArchive(t *testing.T, name, content string, mtime time.Time) *nopCloser {
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	tw := tar.NewWriter(gw)
+
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(content)),
+		ModTime: mtime,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = tw.Write([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tw.Close()
+	gw.Close()
+	return &nopCloser{buf}
+}
+
+type nopCloser struct {
+	*bytes.Buffer
+}
+
+func (n *nopCloser) Close() error {
+	return nil
+}
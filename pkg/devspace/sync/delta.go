@@ -0,0 +1,316 @@
+package sync
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/delta"
+	"github.com/pkg/errors"
+)
+
+// applyDeltas uploads large files that already exist in the container by only transferring the changed
+// blocks and returns the files that still need to be uploaded completely. The file index is only locked
+// while the candidates are selected and the uploaded files are recorded, but not during the upload itself
+func (u *upstream) applyDeltas(files []*FileInformation) []*FileInformation {
+	var (
+		candidates = map[string]*FileInformation{}
+		paths      = []string{}
+		rest       = make([]*FileInformation, 0, len(files))
+	)
+
+	u.sync.fileIndex.fileMapMutex.Lock()
+	for _, file := range files {
+		remoteFile := u.sync.fileIndex.fileMap[file.Name]
+		if file.IsDirectory || file.Size < deltaTransferThreshold || remoteFile == nil || remoteFile.IsDirectory || remoteFile.IsSymbolicLink {
+			rest = append(rest, file)
+			continue
+		}
+
+		candidates[file.Name] = file
+		paths = append(paths, file.Name)
+	}
+	u.sync.fileIndex.fileMapMutex.Unlock()
+	if len(paths) == 0 {
+		return files
+	}
+
+	written, err := u.uploadDeltas(paths)
+	if err != nil {
+		u.sync.log.Infof("Upstream - Delta upload failed, fall back to full upload: %v", err)
+	}
+
+	// update the uploaded files
+	u.sync.fileIndex.fileMapMutex.Lock()
+	defer u.sync.fileIndex.fileMapMutex.Unlock()
+
+	for name, fileInformation := range written {
+		u.sync.fileIndex.CreateDirInFileMap(path.Dir(name))
+		u.sync.fileIndex.fileMap[name] = fileInformation
+	}
+	for _, name := range paths {
+		if written[name] == nil {
+			rest = append(rest, candidates[name])
+		}
+	}
+
+	return rest
+}
+
+// uploadDeltas uploads the given files as delta and returns the file information of the files that were written
+func (u *upstream) uploadDeltas(paths []string) (map[string]*FileInformation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// retrieve the signatures of the remote files
+	signaturesClient, err := u.client.Signatures(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "signatures")
+	}
+
+	for j := 0; j < len(paths); j += removeFilesBufferSize {
+		end := j + removeFilesBufferSize
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		err = signaturesClient.Send(&remote.Paths{Paths: paths[j:end]})
+		if err != nil {
+			return nil, errors.Wrap(err, "send paths")
+		}
+	}
+
+	err = signaturesClient.CloseSend()
+	if err != nil {
+		return nil, errors.Wrap(err, "close send")
+	}
+
+	signatures := make([]*remote.FileSignature, 0, len(paths))
+	for {
+		signature, err := signaturesClient.Recv()
+		if signature != nil && signature.BlockSize > 0 {
+			signatures = append(signatures, signature)
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "recv signature")
+		}
+	}
+
+	// send the deltas
+	uploadClient, err := u.client.UploadDelta(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "upload delta")
+	}
+
+	var (
+		written     = map[string]*FileInformation{}
+		transferred = int64(0)
+		size        = int64(0)
+	)
+	for _, signature := range signatures {
+		absPath := path.Join(u.sync.LocalPath, signature.Path)
+		stat, err := os.Stat(absPath)
+		if err != nil || stat.IsDir() {
+			continue
+		}
+
+		literal, err := delta.Send(absPath, signature, uploadClient.Send)
+		if err != nil {
+			_, recvErr := uploadClient.CloseAndRecv()
+			if recvErr != nil {
+				return nil, errors.Wrap(recvErr, "send delta")
+			}
+
+			return nil, errors.Wrapf(err, "send delta %s", signature.Path)
+		}
+
+		if u.sync.Options.Verbose || len(signatures) <= 3 {
+			u.sync.log.Infof("Upstream - Upload File '%s' as delta", u.getRelativeUpstreamPath(signature.Path))
+		}
+
+		transferred += literal
+		u.sync.countUploaded(int(literal))
+		size += stat.Size()
+		written[signature.Path] = createFileInformationFromStat(signature.Path, stat)
+	}
+
+	failed, err := uploadClient.CloseAndRecv()
+	if err != nil {
+		return nil, errors.Wrap(err, "after delta upload")
+	}
+	for _, name := range failed.Paths {
+		delete(written, name)
+	}
+
+	u.sync.log.Infof("Upstream - Upload %d file(s) as delta (Transferred ~%0.2f KB of ~%0.2f KB)", len(written), float64(transferred)/1024.0, float64(size)/1024.0)
+	return written, nil
+}
+
+// applyDeltas downloads large files that already exist locally by only transferring the changed
+// blocks and returns the changes that still need to be downloaded completely. The file index is only locked
+// while the signatures are computed and the patched files are committed, but not during the download itself
+func (d *downstream) applyDeltas(changes []*remote.Change) []*remote.Change {
+	var (
+		candidates = map[string]*remote.Change{}
+		signatures = []*remote.FileSignature{}
+		rest       = make([]*remote.Change, 0, len(changes))
+	)
+
+	d.sync.fileIndex.fileMapMutex.Lock()
+	for _, change := range changes {
+		if change.IsDir || change.Size < deltaTransferThreshold {
+			rest = append(rest, change)
+			continue
+		}
+
+		// we only use the local file as base if it is a regular file that is not newer than the remote one and
+		// didn't change since the last sync, otherwise the unarchiver takes care of the change
+		absPath := filepath.Join(d.sync.LocalPath, change.Path)
+		stat, err := os.Lstat(absPath)
+		if err != nil || stat.Mode().IsRegular() == false || stat.ModTime().Unix() > change.MtimeUnix || d.sync.isLocallyChanged(change.Path, stat) {
+			rest = append(rest, change)
+			continue
+		}
+
+		signature, err := delta.Signature(absPath, change.Path)
+		if err != nil {
+			rest = append(rest, change)
+			continue
+		}
+
+		candidates[change.Path] = change
+		signatures = append(signatures, signature)
+	}
+	d.sync.fileIndex.fileMapMutex.Unlock()
+	if len(signatures) == 0 {
+		return changes
+	}
+
+	downloaded, err := d.downloadDeltas(signatures)
+	if err != nil {
+		d.sync.log.Infof("Downstream - Delta download failed, fall back to full download: %v", err)
+	}
+
+	for _, signature := range signatures {
+		if downloaded[signature.Path] == false {
+			rest = append(rest, candidates[signature.Path])
+		}
+	}
+
+	return rest
+}
+
+func (d *downstream) downloadDeltas(signatures []*remote.FileSignature) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	downloadClient, err := d.client.DownloadDelta(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "download delta")
+	}
+
+	for _, signature := range signatures {
+		err = downloadClient.Send(signature)
+		if err != nil {
+			return nil, errors.Wrap(err, "send signature")
+		}
+	}
+
+	err = downloadClient.CloseSend()
+	if err != nil {
+		return nil, errors.Wrap(err, "close send")
+	}
+
+	patcher := delta.NewPatcher(d.sync.LocalPath)
+	defer patcher.Close()
+
+	downloaded := map[string]bool{}
+	for {
+		chunk, err := downloadClient.Recv()
+		if chunk != nil {
+			for _, operation := range chunk.Operations {
+				d.sync.countDownloaded(len(operation.Data))
+			}
+
+			file, err := patcher.Apply(chunk)
+			if err != nil {
+				return downloaded, errors.Wrap(err, "apply delta")
+			} else if file != nil {
+				d.sync.fileIndex.fileMapMutex.Lock()
+				err = d.commitPatchedFile(file)
+				d.sync.fileIndex.fileMapMutex.Unlock()
+				if err != nil {
+					d.sync.log.Infof("Downstream - Skip delta download of '.%s': %v", file.Header.Path, err)
+				} else {
+					if d.sync.Options.Verbose || len(signatures) <= 3 {
+						d.sync.log.Infof("Downstream - Download file '.%s' as delta", file.Header.Path)
+					}
+
+					downloaded[file.Header.Path] = true
+				}
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return downloaded, errors.Wrap(err, "recv delta")
+		}
+	}
+
+	d.sync.log.Infof("Downstream - Downloaded %d file(s) as delta", len(downloaded))
+	return downloaded, nil
+}
+
+// s.fileIndex needs to be locked before this function is called
+func (d *downstream) commitPatchedFile(file *delta.PatchedFile) error {
+	if file.Err != nil {
+		return file.Err
+	}
+
+	stat, _ := os.Stat(file.TargetPath)
+	err := file.Commit()
+	if err != nil {
+		file.Discard()
+		return err
+	}
+
+	// Set old permissions correctly
+	if stat != nil {
+		_ = os.Chmod(file.TargetPath, stat.Mode())
+	} else {
+		_ = os.Chmod(file.TargetPath, os.FileMode(file.Header.Mode))
+	}
+
+	// Apply the configured permissions
+	d.sync.applyMappedMode(file.TargetPath, file.Header.Path, false)
+
+	// Set mod time correctly
+	_ = os.Chtimes(file.TargetPath, time.Now(), time.Unix(file.Header.MtimeUnix, 0))
+
+	// Execute command if defined
+	err = d.unarchiver.executeFileChangeCmd(file.TargetPath)
+	if err != nil {
+		return err
+	}
+
+	// Update fileMap so that upstream does not upload the file
+	relativePath := file.Header.Path
+	d.sync.fileIndex.CreateDirInFileMap(path.Dir(relativePath))
+	d.sync.fileIndex.fileMap[relativePath] = &FileInformation{
+		Name:        relativePath,
+		Mtime:       file.Header.MtimeUnix,
+		Mode:        os.FileMode(file.Header.Mode),
+		Size:        file.Header.Size,
+		IsDirectory: false,
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Diff holds the paths the initial sync would change, categorized by what would happen to them
+type Diff struct {
+	Upload       []string `json:"upload"`
+	Download     []string `json:"download"`
+	DeleteLocal  []string `json:"deleteLocal"`
+	DeleteRemote []string `json:"deleteRemote"`
+}
+
+// Empty returns true if the initial sync would not change anything
+func (d *Diff) Empty() bool {
+	return len(d.Upload) == 0 && len(d.Download) == 0 && len(d.DeleteLocal) == 0 && len(d.DeleteRemote) == 0
+}
+
+// DryRun calculates the changes the initial sync would apply with the configured strategy without
+// changing anything locally or in the container. InitDownstream has to be called before
+func (s *Sync) DryRun() (*Diff, error) {
+	if s.downstream == nil {
+		return nil, errors.New("downstream is not initialized")
+	}
+
+	err := s.downstream.populateFileMap()
+	if err != nil {
+		return nil, errors.Wrap(err, "populate file map")
+	}
+
+	initialSync := newInitialSyncer(&initialSyncOptions{
+		LocalPath: s.LocalPath,
+		Strategy:  s.Options.InitialSync,
+		CompareBy: s.Options.InitialSyncCompareBy,
+		Symlinks:  s.Options.Symlinks,
+
+		IgnoreMatcher:         s.ignoreMatcher,
+		DownloadIgnoreMatcher: s.downloadIgnoreMatcher,
+		UploadIgnoreMatcher:   s.uploadIgnoreMatcher,
+
+		UpstreamDisabled:   s.Options.UpstreamDisabled,
+		DownstreamDisabled: s.Options.DownstreamDisabled,
+		FileIndex:          s.fileIndex,
+		Snapshot:           s.loadSnapshot(),
+
+		AddSymlink:      s.statSymlink,
+		RemoteChecksums: s.downstream.collectChecksums,
+		Log:             s.log,
+	})
+
+	plan, err := initialSync.plan(s.remoteState())
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &Diff{
+		Upload:       []string{},
+		Download:     []string{},
+		DeleteLocal:  []string{},
+		DeleteRemote: []string{},
+	}
+	for _, element := range plan.upload {
+		diff.Upload = append(diff.Upload, element.Name)
+	}
+	for _, element := range plan.deleteRemote {
+		diff.DeleteRemote = append(diff.DeleteRemote, element.Name)
+	}
+	for _, change := range plan.download {
+		diff.Download = append(diff.Download, change.Path)
+	}
+	for _, change := range plan.deleteLocal {
+		diff.DeleteLocal = append(diff.DeleteLocal, change.Path)
+	}
+
+	sort.Strings(diff.Upload)
+	sort.Strings(diff.Download)
+	sort.Strings(diff.DeleteLocal)
+	sort.Strings(diff.DeleteRemote)
+	return diff, nil
+}
+
+// statSymlink resolves a local symlink like upstream.AddSymlink, but without watching its target
+func (s *Sync) statSymlink(relativePath, absPath string) (os.FileInfo, error) {
+	targetPath, err := filepath.EvalSymlinks(absPath)
+	if err != nil {
+		return nil, nil
+	}
+
+	stat, err := os.Stat(targetPath)
+	if err != nil {
+		return nil, nil
+	} else if s.ignoreMatcher != nil && s.ignoreMatcher.Matches(relativePath, stat.IsDir()) {
+		return nil, nil
+	}
+
+	return stat, nil
+}
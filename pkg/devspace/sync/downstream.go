@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
@@ -26,6 +27,7 @@ type downstream struct {
 	client remote.DownstreamClient
 
 	unarchiver *Unarchiver
+	negotiator *negotiator
 }
 
 const downloadFilesBufferSize = 64
@@ -51,13 +53,15 @@ func newDownstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*do
 		return nil, errors.Wrap(err, "new client connection")
 	}
 
+	client := remote.NewDownstreamClient(conn)
 	return &downstream{
 		interrupt:  make(chan bool, 1),
 		sync:       sync,
 		reader:     reader,
 		writer:     writer,
-		client:     remote.NewDownstreamClient(conn),
+		client:     client,
 		unarchiver: NewUnarchiver(sync, false, sync.log),
+		negotiator: newNegotiator(client.Handshake, &sync.Options),
 	}, nil
 }
 
@@ -94,7 +98,12 @@ func (d *downstream) collectChanges() ([]*remote.Change, error) {
 		changeChunk, err := changesClient.Recv()
 		if changeChunk != nil {
 			for _, change := range changeChunk.Changes {
-				if d.shouldKeep(change) {
+				accept, collision := d.sync.fileIndex.AcceptChange(change)
+				if collision != nil {
+					d.sync.reportCollision(collision)
+				}
+
+				if accept && d.shouldKeep(change) {
 					changes = append(changes, change)
 				}
 			}
@@ -110,6 +119,54 @@ func (d *downstream) collectChanges() ([]*remote.Change, error) {
 	return changes, nil
 }
 
+// collectChecksums retrieves the remote content checksums for the given paths
+func (d *downstream) collectChecksums(paths []string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*30)
+	defer cancel()
+
+	checksumsClient, err := d.client.Checksums(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "start retrieving checksums")
+	}
+
+	for j := 0; j < len(paths); j += downloadFilesBufferSize {
+		end := j + downloadFilesBufferSize
+		if end > len(paths) {
+			end = len(paths)
+		}
+
+		err = checksumsClient.Send(&remote.Paths{
+			Paths: paths[j:end],
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "send paths")
+		}
+	}
+
+	err = checksumsClient.CloseSend()
+	if err != nil {
+		return nil, errors.Wrap(err, "close send")
+	}
+
+	checksums := make(map[string]string, len(paths))
+	for {
+		checksumChunk, err := checksumsClient.Recv()
+		if checksumChunk != nil {
+			for _, checksum := range checksumChunk.Checksums {
+				checksums[checksum.Path] = checksum.Checksum
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, "recv checksum")
+		}
+	}
+
+	return checksums, nil
+}
+
 func (d *downstream) startPing(doneChan chan struct{}) {
 	go func() {
 		for {
@@ -151,6 +208,7 @@ func (d *downstream) mainLoop() error {
 		if err != nil {
 			return errors.Wrap(err, "count changes")
 		}
+		d.sync.setPendingDownloads(changeAmount.Amount)
 
 		// Compare change amount
 		if lastAmountChanges > 0 && changeAmount.Amount == lastAmountChanges {
@@ -198,6 +256,7 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 	var (
 		download = make([]*remote.Change, 0, len(changes)/2)
 		remove   = make([]*remote.Change, 0, len(changes)/2)
+		symlinks = []*remote.Change{}
 	)
 
 	// Skip if there are no changes
@@ -209,6 +268,8 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 	for _, change := range changes {
 		if change.ChangeType == remote.ChangeType_DELETE {
 			remove = append(remove, change)
+		} else if change.LinkTarget != "" {
+			symlinks = append(symlinks, change)
 		} else {
 			download = append(download, change)
 		}
@@ -217,6 +278,16 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 	// Remove all files and folders that should be deleted first and we ignore errors
 	d.remove(remove, force)
 
+	// Preserved symlinks don't need to be downloaded, because the change contains their target
+	if len(symlinks) > 0 {
+		d.createSymlinks(symlinks)
+	}
+
+	// Large files that already exist locally are downloaded as delta first
+	if len(download) > 0 {
+		download = d.applyDeltas(download)
+	}
+
 	// Extract downloaded archive
 	if len(download) > 0 {
 		for i := 0; i < syncRetries; i++ {
@@ -228,6 +299,7 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 			}
 
 			d.sync.log.Infof("Downstream - Retry download because of error: %v", err)
+			d.sync.setLastError(err)
 
 			download = d.updateDownloadChanges(download)
 			if len(download) == 0 {
@@ -236,7 +308,11 @@ func (d *downstream) applyChanges(changes []*remote.Change, force bool) error {
 		}
 	}
 
+	// Upload the local versions of conflicting files that were kept
+	d.uploadKeptLocal()
+
 	d.sync.log.Infof("Downstream - Successfully processed %d change(s)", len(changes))
+	d.sync.downloadDone()
 	return nil
 }
 
@@ -255,11 +331,28 @@ func (d *downstream) updateDownloadChanges(download []*remote.Change) []*remote.
 }
 
 func (d *downstream) initDownload(download []*remote.Change) error {
+	codec, err := d.negotiator.compression()
+	if err != nil {
+		return err
+	}
+
 	reader, writer := io.Pipe()
 
 	defer reader.Close()
 	defer writer.Close()
 
+	size := int64(0)
+	for _, change := range download {
+		size += change.Size
+	}
+
+	progress := d.sync.startProgress(ProgressDirectionDownload, len(download), size)
+	defer progress.Done()
+	d.unarchiver.progress = progress
+	defer func() {
+		d.unarchiver.progress = nil
+	}()
+
 	errorChan := make(chan error)
 	go func() {
 		errorChan <- d.downloadFiles(writer, download)
@@ -267,7 +360,7 @@ func (d *downstream) initDownload(download []*remote.Change) error {
 
 	// Untaring all downloaded files to the right location
 	// this can be a lengthy process when we downloaded a lot of files
-	err := d.unarchiver.Untar(reader, d.sync.LocalPath)
+	err = d.unarchiver.Untar(reader, codec, d.sync.LocalPath)
 	if err != nil {
 		return errors.Wrap(err, "untar files")
 	}
@@ -339,6 +432,7 @@ func (d *downstream) downloadFiles(writer io.WriteCloser, changes []*remote.Chan
 	for {
 		chunk, err := downloadClient.Recv()
 		if chunk != nil {
+			d.sync.countDownloaded(len(chunk.Content))
 			_, err := writer.Write(chunk.Content)
 			if err != nil {
 				// this means the tar is done already, so we just exit here
@@ -396,6 +490,37 @@ func (d *downstream) remove(remove []*remote.Change, force bool) {
 	}
 }
 
+// createSymlinks creates the preserved remote symlinks locally. Existing files and symlinks at their
+// paths are replaced, while existing directories are kept
+func (d *downstream) createSymlinks(symlinks []*remote.Change) {
+	d.sync.fileIndex.fileMapMutex.Lock()
+	defer d.sync.fileIndex.fileMapMutex.Unlock()
+
+	for _, change := range symlinks {
+		absFilepath := filepath.Join(d.sync.LocalPath, change.Path)
+		if stat, err := os.Lstat(absFilepath); err == nil && stat.IsDir() {
+			d.sync.log.Infof("Downstream - Skip symlink '.%s', because a directory exists at its path", change.Path)
+			continue
+		}
+
+		err := os.MkdirAll(filepath.Dir(absFilepath), 0755)
+		if err == nil {
+			err = util.CreateSymlink(change.LinkTarget, absFilepath)
+		}
+		if err != nil {
+			d.sync.log.Infof("Downstream - Skip symlink '.%s': %v", change.Path, err)
+			continue
+		}
+
+		if d.sync.Options.Verbose || len(symlinks) <= 3 {
+			d.sync.log.Infof("Downstream - Symlink '.%s' -> '%s'", change.Path, change.LinkTarget)
+		}
+
+		d.sync.fileIndex.CreateDirInFileMap(path.Dir(change.Path))
+		d.sync.fileIndex.fileMap[change.Path] = parseFileInformation(change)
+	}
+}
+
 func (d *downstream) deleteSafeRecursive(relativePath string, deleteChanges []*remote.Change, force bool) {
 	absolutePath := filepath.Join(d.sync.LocalPath, relativePath)
 	relativePath = getRelativeFromFullPath(absolutePath, d.sync.LocalPath)
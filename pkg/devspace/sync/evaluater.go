@@ -71,6 +71,11 @@ func shouldUpload(s *Sync, fileInformation *FileInformation) bool {
 			return false
 		}
 
+		// Preserved symlink still points to the same target
+		if fileInformation.LinkTarget != "" && fileInformation.LinkTarget == s.fileIndex.fileMap[fileInformation.Name].LinkTarget {
+			return false
+		}
+
 		// File did not change or was changed by downstream
 		if fileInformation.Mtime == s.fileIndex.fileMap[fileInformation.Name].Mtime && fileInformation.Size == s.fileIndex.fileMap[fileInformation.Name].Size {
 			return false
@@ -82,6 +87,11 @@ func shouldUpload(s *Sync, fileInformation *FileInformation) bool {
 
 // s.fileIndex needs to be locked before this function is called
 func shouldDownload(change *remote.Change, s *Sync) bool {
+	// Preserved symlinks are only downloaded if their target changed
+	if change.LinkTarget != "" {
+		return s.fileIndex.fileMap[change.Path] == nil || s.fileIndex.fileMap[change.Path].LinkTarget != change.LinkTarget
+	}
+
 	// Does file already exist in the filemap?
 	if s.fileIndex.fileMap[change.Path] != nil {
 		// Don't override folders that exist in the filemap
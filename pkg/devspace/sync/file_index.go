@@ -8,11 +8,24 @@ import (
 type fileIndex struct {
 	fileMap      map[string]*FileInformation
 	fileMapMutex sync.Mutex
+
+	// caseInsensitive is true if the local filesystem doesn't distinguish paths that only differ
+	// in case. The first remote path of such a group owns it, all others are reported as collisions
+	caseInsensitive bool
+
+	// windows is true if remote paths have to be valid windows paths
+	windows bool
+
+	owners         map[string]string
+	collisions     map[string]Collision
+	collisionMutex sync.Mutex
 }
 
 func newFileIndex() *fileIndex {
 	return &fileIndex{
-		fileMap: make(map[string]*FileInformation),
+		fileMap:    make(map[string]*FileInformation),
+		owners:     make(map[string]string),
+		collisions: make(map[string]Collision),
 	}
 }
 
@@ -16,6 +16,10 @@ type FileInformation struct {
 
 	IsSymbolicLink bool
 	IsDirectory    bool
+
+	// LinkTarget is the target of a preserved symlink, which is synced as a symlink
+	// instead of the file or directory it points to
+	LinkTarget string
 }
 
 // Sys implements interface
@@ -44,5 +48,6 @@ func parseFileInformation(change *remote.Change) *FileInformation {
 		Mtime:       change.MtimeUnix,
 		MtimeNano:   change.MtimeUnixNano,
 		IsDirectory: change.IsDir,
+		LinkTarget:  change.LinkTarget,
 	}
 }
@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type handshakeFn func(ctx context.Context, in *remote.HandshakeRequest, opts ...grpc.CallOption) (*remote.HandshakeResponse, error)
+
+// negotiator negotiates the compression with the helper on first use
+type negotiator struct {
+	handshake handshakeFn
+	options   *Options
+
+	mutex      sync.Mutex
+	negotiated bool
+	codec      compression.Codec
+}
+
+func newNegotiator(handshake handshakeFn, options *Options) *negotiator {
+	return &negotiator{
+		handshake: handshake,
+		options:   options,
+	}
+}
+
+// compression returns the compression the helper has chosen. Helpers that do not support the
+// handshake use the legacy compression
+func (n *negotiator) compression() (compression.Codec, error) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.negotiated {
+		return n.codec, nil
+	}
+
+	preferred := n.options.Compression
+	if preferred == compression.Legacy {
+		preferred = compression.Gzip
+	}
+
+	requested := []string{string(preferred)}
+	if preferred != compression.Gzip {
+		requested = append(requested, string(compression.Gzip))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	response, err := n.handshake(ctx, &remote.HandshakeRequest{
+		Compression:      requested,
+		CompressionLevel: int32(n.options.CompressionLevel),
+	})
+	if err != nil {
+		if status.Code(err) != codes.Unimplemented {
+			return compression.Legacy, errors.Wrap(err, "handshake")
+		}
+
+		n.codec = compression.Legacy
+	} else {
+		n.codec = compression.Codec(response.Compression)
+	}
+
+	n.negotiated = true
+	return n.codec, nil
+}
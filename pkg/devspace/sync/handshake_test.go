@@ -0,0 +1,48 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestNegotiator(t *testing.T) {
+	calls := 0
+	n := newNegotiator(func(ctx context.Context, in *remote.HandshakeRequest, opts ...grpc.CallOption) (*remote.HandshakeResponse, error) {
+		calls++
+		if len(in.Compression) != 2 || in.Compression[0] != "zstd" || in.Compression[1] != "gzip" {
+			t.Fatalf("Unexpected requested compression %v", in.Compression)
+		}
+
+		return &remote.HandshakeResponse{Compression: string(compression.Negotiate(in.Compression))}, nil
+	}, &Options{Compression: compression.Zstd})
+
+	for i := 0; i < 2; i++ {
+		codec, err := n.compression()
+		if err != nil {
+			t.Fatal(err)
+		} else if codec != compression.Zstd {
+			t.Fatalf("Expected zstd, got %s", codec)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("Expected a single handshake, got %d", calls)
+	}
+
+	// helpers without handshake support use the legacy compression
+	n = newNegotiator(func(ctx context.Context, in *remote.HandshakeRequest, opts ...grpc.CallOption) (*remote.HandshakeResponse, error) {
+		return nil, status.Error(codes.Unimplemented, "unknown method Handshake")
+	}, &Options{})
+
+	codec, err := n.compression()
+	if err != nil {
+		t.Fatal(err)
+	} else if codec != compression.Legacy {
+		t.Fatalf("Expected legacy compression, got %s", codec)
+	}
+}
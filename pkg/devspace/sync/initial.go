@@ -7,6 +7,7 @@ import (
 	"path"
 
 	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/util"
 	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/log"
 
@@ -15,6 +16,15 @@ import (
 
 type initialSyncer struct {
 	o *initialSyncOptions
+
+	// equalContents holds the paths of the files whose local and remote
+	// checksums are equal. This is only filled if CompareBy is checksum
+	equalContents map[string]bool
+
+	// deleteLocal and deleteRemote hold the files that were deleted on the other
+	// side since the last sync session. These are only filled if there is a snapshot
+	deleteLocal  []*FileInformation
+	deleteRemote []*FileInformation
 }
 
 type initialSyncOptions struct {
@@ -22,6 +32,7 @@ type initialSyncOptions struct {
 
 	CompareBy latest.InitialSyncCompareBy
 	Strategy  latest.InitialSyncStrategy
+	Symlinks  latest.SyncSymlinks
 
 	IgnoreMatcher         ignoreparser.IgnoreParser
 	DownloadIgnoreMatcher ignoreparser.IgnoreParser
@@ -31,9 +42,14 @@ type initialSyncOptions struct {
 	DownstreamDisabled bool
 	FileIndex          *fileIndex
 
-	ApplyRemote func(changes []*FileInformation, remove bool)
-	ApplyLocal  func(changes []*remote.Change, force bool) error
-	AddSymlink  func(relativePath, absPath string) (os.FileInfo, error)
+	// Snapshot is the synced state of the last sync session. If set, only the paths
+	// that changed on either side since then are reconciled
+	Snapshot map[string]*FileInformation
+
+	ApplyRemote     func(changes []*FileInformation, remove bool)
+	ApplyLocal      func(changes []*remote.Change, force bool) error
+	AddSymlink      func(relativePath, absPath string) (os.FileInfo, error)
+	RemoteChecksums func(paths []string) (map[string]string, error)
 
 	UpstreamDone   func()
 	DownstreamDone func()
@@ -46,133 +62,140 @@ func newInitialSyncer(options *initialSyncOptions) *initialSyncer {
 		options.Strategy = latest.InitialSyncStrategyMirrorLocal
 	}
 
-	return &initialSyncer{o: options}
+	return &initialSyncer{
+		o:             options,
+		equalContents: map[string]bool{},
+	}
+}
+
+// initialSyncPlan holds the changes the initial sync applies on both sides
+type initialSyncPlan struct {
+	upload       []*FileInformation
+	deleteRemote []*FileInformation
+
+	download    []*remote.Change
+	deleteLocal []*remote.Change
 }
 
 func (i *initialSyncer) Run(remoteState map[string]*FileInformation) error {
+	plan, err := i.plan(remoteState)
+	if err != nil {
+		return err
+	}
+
+	// Upstream initial sync
+	go func() {
+		if len(plan.deleteRemote) > 0 {
+			i.o.ApplyRemote(plan.deleteRemote, true)
+		}
+		if len(plan.upload) > 0 {
+			i.o.ApplyRemote(plan.upload, false)
+		}
+
+		i.o.UpstreamDone()
+	}()
+
+	// Downstream initial sync
+	if len(plan.deleteLocal) > 0 {
+		err = i.o.ApplyLocal(plan.deleteLocal, true)
+		if err != nil {
+			return errors.Wrap(err, "apply changes")
+		}
+	}
+	if len(plan.download) > 0 {
+		err = i.o.ApplyLocal(plan.download, false)
+		if err != nil {
+			return errors.Wrap(err, "apply changes")
+		}
+	}
+
+	i.o.DownstreamDone()
+	return nil
+}
+
+// plan calculates the changes of the initial sync without applying them
+func (i *initialSyncer) plan(remoteState map[string]*FileInformation) (*initialSyncPlan, error) {
 	// Here we calculate the delta between the remote and local state, the result of this operation
 	// are files we should download (new and override) and files we should upload (new and override)
 	download := remoteState
 	upload, err := i.CalculateDelta(download)
 	if err != nil {
-		return errors.Wrap(err, "diff server client")
+		return nil, errors.Wrap(err, "diff server client")
 	}
 
-	// Upstream initial sync
-	go func() {
-		if i.o.UpstreamDisabled == false {
-			// Remove remote if mirror local
-			if len(download) > 0 && i.o.Strategy == latest.InitialSyncStrategyMirrorLocal {
-				deleteRemote := make([]*FileInformation, 0, len(download))
-				for _, element := range download {
-					if i.o.UploadIgnoreMatcher != nil && i.o.UploadIgnoreMatcher.Matches(element.Name, element.IsDirectory) {
-						continue
-					}
-
-					deleteRemote = append(deleteRemote, &FileInformation{
-						Name:        element.Name,
-						IsDirectory: element.IsDirectory,
-					})
+	plan := &initialSyncPlan{}
+	if i.o.UpstreamDisabled == false {
+		// Remove remote files that were deleted locally since the last session
+		plan.deleteRemote = append(plan.deleteRemote, i.deleteRemote...)
+
+		// Remove remote if mirror local
+		if i.o.Strategy == latest.InitialSyncStrategyMirrorLocal {
+			for _, element := range download {
+				if i.o.UploadIgnoreMatcher != nil && i.o.UploadIgnoreMatcher.Matches(element.Name, element.IsDirectory) {
+					continue
 				}
 
-				i.o.ApplyRemote(deleteRemote, true)
+				plan.deleteRemote = append(plan.deleteRemote, &FileInformation{
+					Name:        element.Name,
+					IsDirectory: element.IsDirectory,
+				})
 			}
+		}
 
-			// Upload remote if not mirror remote
-			if len(upload) > 0 {
-				if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
-					// only apply the ones that match the downstream ignore matcher
-					changes := []*FileInformation{}
-					for _, element := range upload {
-						if i.o.DownloadIgnoreMatcher != nil && i.o.DownloadIgnoreMatcher.Matches(element.Name, element.IsDirectory) {
-							changes = append(changes, element)
-						}
-					}
-					if len(changes) > 0 {
-						i.o.ApplyRemote(changes, false)
-					}
-				} else {
-					i.o.ApplyRemote(upload, false)
+		// Upload remote if not mirror remote
+		if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
+			// only apply the ones that match the downstream ignore matcher
+			for _, element := range upload {
+				if i.o.DownloadIgnoreMatcher != nil && i.o.DownloadIgnoreMatcher.Matches(element.Name, element.IsDirectory) {
+					plan.upload = append(plan.upload, element)
 				}
 			}
-
+		} else {
+			plan.upload = upload
 		}
+	}
 
-		i.o.UpstreamDone()
-	}()
-
-	// Download changes if enabled
 	if i.o.DownstreamDisabled == false {
+		// Remove local files that were deleted remotely since the last session
+		for _, element := range i.deleteLocal {
+			plan.deleteLocal = append(plan.deleteLocal, toRemoteChange(element, remote.ChangeType_DELETE))
+		}
+
 		// Remove local if mirror remote
-		if len(upload) > 0 && i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
-			remoteChanges := make([]*remote.Change, 0, len(upload))
+		if i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
 			for _, element := range upload {
 				if i.o.DownloadIgnoreMatcher != nil && i.o.DownloadIgnoreMatcher.Matches(element.Name, element.IsDirectory) {
 					continue
 				}
 
-				remoteChanges = append(remoteChanges, &remote.Change{
-					ChangeType:    remote.ChangeType_DELETE,
-					Path:          element.Name,
-					MtimeUnix:     element.Mtime,
-					MtimeUnixNano: element.MtimeNano,
-					Size:          element.Size,
-					IsDir:         element.IsDirectory,
-				})
-			}
-
-			err = i.o.ApplyLocal(remoteChanges, true)
-			if err != nil {
-				return errors.Wrap(err, "apply changes")
+				plan.deleteLocal = append(plan.deleteLocal, toRemoteChange(element, remote.ChangeType_DELETE))
 			}
 		}
 
 		// Download local if not mirror local
-		if len(download) > 0 {
-			if i.o.Strategy == latest.InitialSyncStrategyMirrorLocal {
-				// only apply the ones that match the upstream ignore matcher
-				remoteChanges := make([]*remote.Change, 0, len(download))
-				for _, element := range download {
-					if i.o.UploadIgnoreMatcher != nil && i.o.UploadIgnoreMatcher.Matches(element.Name, element.IsDirectory) {
-						remoteChanges = append(remoteChanges, &remote.Change{
-							ChangeType:    remote.ChangeType_CHANGE,
-							Path:          element.Name,
-							MtimeUnix:     element.Mtime,
-							MtimeUnixNano: element.MtimeNano,
-							Size:          element.Size,
-							IsDir:         element.IsDirectory,
-						})
-					}
-				}
-				if len(remoteChanges) > 0 {
-					err = i.o.ApplyLocal(remoteChanges, false)
-					if err != nil {
-						return errors.Wrap(err, "apply changes")
-					}
-				}
-			} else {
-				remoteChanges := make([]*remote.Change, 0, len(download))
-				for _, element := range download {
-					remoteChanges = append(remoteChanges, &remote.Change{
-						ChangeType:    remote.ChangeType_CHANGE,
-						Path:          element.Name,
-						MtimeUnix:     element.Mtime,
-						MtimeUnixNano: element.MtimeNano,
-						Size:          element.Size,
-						IsDir:         element.IsDirectory,
-					})
-				}
-
-				err = i.o.ApplyLocal(remoteChanges, false)
-				if err != nil {
-					return errors.Wrap(err, "apply changes")
-				}
+		for _, element := range download {
+			// only apply the ones that match the upstream ignore matcher
+			if i.o.Strategy == latest.InitialSyncStrategyMirrorLocal && (i.o.UploadIgnoreMatcher == nil || i.o.UploadIgnoreMatcher.Matches(element.Name, element.IsDirectory) == false) {
+				continue
 			}
+
+			plan.download = append(plan.download, toRemoteChange(element, remote.ChangeType_CHANGE))
 		}
 	}
 
-	i.o.DownstreamDone()
-	return nil
+	return plan, nil
+}
+
+func toRemoteChange(element *FileInformation, changeType remote.ChangeType) *remote.Change {
+	return &remote.Change{
+		ChangeType:    changeType,
+		Path:          element.Name,
+		MtimeUnix:     element.Mtime,
+		MtimeUnixNano: element.MtimeNano,
+		Size:          element.Size,
+		IsDir:         element.IsDirectory,
+		LinkTarget:    element.LinkTarget,
+	}
 }
 
 func (i *initialSyncer) CalculateDelta(remoteState map[string]*FileInformation) ([]*FileInformation, error) {
@@ -183,11 +206,148 @@ func (i *initialSyncer) CalculateDelta(remoteState map[string]*FileInformation)
 		strategy = latest.InitialSyncStrategyPreferLocal
 	}
 
-	return i.deltaPath(i.o.LocalPath, remoteState, strategy, false)
+	if i.o.CompareBy == latest.InitialSyncCompareByChecksum {
+		err := i.compareChecksums(remoteState)
+		if err != nil {
+			return nil, errors.Wrap(err, "compare checksums")
+		}
+	}
+
+	upload, err := i.deltaPath(i.o.LocalPath, remoteState, strategy, false)
+	if err != nil {
+		return nil, err
+	}
+
+	i.collectLocalDeletes(remoteState)
+	return upload, nil
+}
+
+// collectLocalDeletes removes the files from the remote state that were deleted locally since the
+// last session and didn't change remotely, and marks them for deletion instead of downloading them
+func (i *initialSyncer) collectLocalDeletes(remoteState map[string]*FileInformation) {
+	if i.o.Snapshot == nil || i.o.Strategy == latest.InitialSyncStrategyMirrorRemote {
+		return
+	}
+
+	for name, element := range remoteState {
+		snapshot := i.o.Snapshot[name]
+		if snapshot == nil || snapshot.IsDirectory || element.IsDirectory || element.IsSymbolicLink || equalState(element, snapshot) == false {
+			continue
+		} else if i.o.UploadIgnoreMatcher != nil && i.o.UploadIgnoreMatcher.Matches(name, false) {
+			continue
+		}
+
+		_, err := os.Lstat(path.Join(i.o.LocalPath, name))
+		if os.IsNotExist(err) == false {
+			continue
+		}
+
+		i.deleteRemote = append(i.deleteRemote, &FileInformation{
+			Name: name,
+		})
+		delete(remoteState, name)
+	}
+}
+
+// decideBySnapshot decides what to do with a local file based on the state of the last session. Files that
+// only changed on one side are synced to the other side, files that didn't change at all are skipped and files
+// that changed on both sides are left to the initial sync strategy. Returns false if no decision could be made.
+func (i *initialSyncer) decideBySnapshot(fileInformation *FileInformation) (action, bool) {
+	if i.o.Snapshot == nil || fileInformation.IsDirectory || fileInformation.LinkTarget != "" {
+		return noAction, false
+	}
+
+	snapshot := i.o.Snapshot[fileInformation.Name]
+	if snapshot == nil || snapshot.IsDirectory {
+		return noAction, false
+	}
+
+	localUnchanged := equalState(fileInformation, snapshot)
+	remoteFile := i.o.FileIndex.fileMap[fileInformation.Name]
+	if remoteFile == nil {
+		// the file was deleted remotely since the last session
+		if localUnchanged && i.o.Strategy != latest.InitialSyncStrategyMirrorLocal {
+			if i.o.DownloadIgnoreMatcher != nil && i.o.DownloadIgnoreMatcher.Matches(fileInformation.Name, false) {
+				return noAction, false
+			}
+
+			i.deleteLocal = append(i.deleteLocal, fileInformation)
+			return noAction, true
+		}
+
+		return noAction, false
+	} else if remoteFile.IsDirectory || remoteFile.IsSymbolicLink {
+		return noAction, false
+	}
+
+	remoteUnchanged := equalState(remoteFile, snapshot)
+	if localUnchanged && remoteUnchanged {
+		return noAction, true
+	} else if localUnchanged && i.o.Strategy != latest.InitialSyncStrategyMirrorLocal {
+		return downloadAction, true
+	} else if remoteUnchanged && i.o.Strategy != latest.InitialSyncStrategyMirrorRemote {
+		return uploadAction, true
+	}
+
+	return noAction, false
+}
+
+func equalState(fileInformation *FileInformation, snapshot *FileInformation) bool {
+	return fileInformation.Size == snapshot.Size && fileInformation.Mtime == snapshot.Mtime
+}
+
+// compareChecksums retrieves the remote checksums of all files that have the same size locally and
+// remotely but a different mtime and remembers the ones where the contents are actually equal
+func (i *initialSyncer) compareChecksums(remoteState map[string]*FileInformation) error {
+	candidates := []string{}
+	for name, element := range remoteState {
+		if element.IsDirectory || element.IsSymbolicLink {
+			continue
+		}
+
+		stat, err := os.Stat(path.Join(i.o.LocalPath, name))
+		if err != nil || stat.IsDir() || stat.Size() != element.Size || stat.ModTime().Unix() == element.Mtime {
+			continue
+		}
+
+		// skip files that didn't change on both sides since the last session
+		if snapshot := i.o.Snapshot[name]; snapshot != nil && equalState(element, snapshot) && snapshot.Size == stat.Size() && snapshot.Mtime == stat.ModTime().Unix() {
+			continue
+		}
+
+		candidates = append(candidates, name)
+	}
+	if len(candidates) == 0 || i.o.RemoteChecksums == nil {
+		return nil
+	}
+
+	i.o.Log.Infof("Initial Sync - Compare checksums of %d file(s)", len(candidates))
+	remoteChecksums, err := i.o.RemoteChecksums(candidates)
+	if err != nil {
+		return errors.Wrap(err, "retrieve remote checksums")
+	}
+
+	for name, remoteChecksum := range remoteChecksums {
+		localChecksum, err := util.FileChecksum(path.Join(i.o.LocalPath, name))
+		if err != nil {
+			continue
+		}
+
+		if localChecksum == remoteChecksum {
+			i.equalContents[name] = true
+		}
+	}
+
+	return nil
 }
 
 func (i *initialSyncer) deltaPath(absPath string, remoteState map[string]*FileInformation, strategy latest.InitialSyncStrategy, ignore bool) ([]*FileInformation, error) {
-	relativePath := getRelativeFromFullPath(absPath, i.o.LocalPath)
+	relativePath := i.o.FileIndex.CanonicalPath(getRelativeFromFullPath(absPath, i.o.LocalPath))
+
+	// Preserved symlinks are compared by their target and ignored symlinks are neither uploaded nor overridden
+	if isSymlink, fileInfo := lstatSymlink(i.o.Symlinks, relativePath, absPath); isSymlink {
+		return i.deltaSymlink(fileInfo, relativePath, remoteState, strategy, ignore), nil
+	}
 
 	// We skip files that are suddenly not there anymore
 	stat, err := os.Stat(absPath)
@@ -283,7 +443,7 @@ func (i *initialSyncer) deltaPath(absPath string, remoteState map[string]*FileIn
 }
 
 func (i *initialSyncer) deltaDir(filepath string, stat os.FileInfo, remoteState map[string]*FileInformation, strategy latest.InitialSyncStrategy, ignore bool) ([]*FileInformation, error) {
-	relativePath := getRelativeFromFullPath(filepath, i.o.LocalPath)
+	relativePath := i.o.FileIndex.CanonicalPath(getRelativeFromFullPath(filepath, i.o.LocalPath))
 
 	files, err := ioutil.ReadDir(filepath)
 	if err != nil {
@@ -325,6 +485,28 @@ func (i *initialSyncer) deltaDir(filepath string, stat os.FileInfo, remoteState
 	return upload, nil
 }
 
+// deltaSymlink decides what to do with a local symlink that is not followed, fileInfo is nil if it is ignored
+func (i *initialSyncer) deltaSymlink(fileInfo *FileInformation, relativePath string, remoteState map[string]*FileInformation, strategy latest.InitialSyncStrategy, ignore bool) []*FileInformation {
+	if fileInfo == nil {
+		delete(remoteState, relativePath)
+		return nil
+	} else if ignore {
+		return nil
+	}
+
+	i.o.FileIndex.Lock()
+	action := i.decide(fileInfo, strategy)
+	i.o.FileIndex.Unlock()
+	if action == uploadAction {
+		delete(remoteState, relativePath)
+		return []*FileInformation{fileInfo}
+	} else if action == noAction {
+		delete(remoteState, relativePath)
+	}
+
+	return nil
+}
+
 type action int
 
 const (
@@ -351,6 +533,11 @@ func (i *initialSyncer) decide(fileInformation *FileInformation, strategy latest
 		}
 	}
 
+	// Check if the file changed since the last session
+	if decision, ok := i.decideBySnapshot(fileInformation); ok {
+		return decision
+	}
+
 	// Check if we already tracked the path
 	if i.o.FileIndex.fileMap[fileInformation.Name] != nil {
 		// Folder already exists, don't send change
@@ -363,12 +550,19 @@ func (i *initialSyncer) decide(fileInformation *FileInformation, strategy latest
 			return noAction
 		}
 
+		// Preserved symlinks are equal if they point to the same target
+		if fileInformation.LinkTarget != "" && fileInformation.LinkTarget == i.o.FileIndex.fileMap[fileInformation.Name].LinkTarget {
+			return noAction
+		}
+
 		// File did not change or was changed by downstream
 		if fileInformation.Size == i.o.FileIndex.fileMap[fileInformation.Name].Size {
 			if fileInformation.Mtime == i.o.FileIndex.fileMap[fileInformation.Name].Mtime {
 				return noAction
 			} else if i.o.CompareBy == latest.InitialSyncCompareBySize {
 				return noAction
+			} else if i.o.CompareBy == latest.InitialSyncCompareByChecksum && i.equalContents[fileInformation.Name] {
+				return noAction
 			}
 		}
 
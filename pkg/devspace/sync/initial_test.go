@@ -0,0 +1,270 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestCalculateDeltaCompareByChecksum(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	// equal has the same content remotely, changed has the same size but different content
+	files := map[string]string{
+		"equal":   "same content",
+		"changed": "local change",
+		"new":     "only local",
+	}
+	for name, content := range files {
+		err = ioutil.WriteFile(filepath.Join(local, name), []byte(content), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remoteMtime := time.Now().Add(-time.Hour).Unix()
+	remoteChecksums := map[string]string{}
+	remoteChecksums["/equal"], err = util.FileChecksum(filepath.Join(local, "equal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteChecksums["/changed"] = "0000"
+
+	for _, compareBy := range []latest.InitialSyncCompareBy{latest.InitialSyncCompareByMTime, latest.InitialSyncCompareByChecksum} {
+		fileIndex := newFileIndex()
+		remoteState := map[string]*FileInformation{}
+		for _, name := range []string{"/equal", "/changed"} {
+			fileIndex.Set(&FileInformation{
+				Name:  name,
+				Size:  int64(len(files[name[1:]])),
+				Mtime: remoteMtime,
+			})
+			remoteState[name] = fileIndex.fileMap[name]
+		}
+
+		requested := 0
+		syncer := newInitialSyncer(&initialSyncOptions{
+			LocalPath: local,
+			CompareBy: compareBy,
+			Strategy:  latest.InitialSyncStrategyPreferLocal,
+			FileIndex: fileIndex,
+			RemoteChecksums: func(paths []string) (map[string]string, error) {
+				requested += len(paths)
+				return remoteChecksums, nil
+			},
+			Log: log.Discard,
+		})
+
+		upload, err := syncer.CalculateDelta(remoteState)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		uploaded := map[string]bool{}
+		for _, change := range upload {
+			uploaded[change.Name] = true
+		}
+
+		if compareBy == latest.InitialSyncCompareByChecksum {
+			if requested != 2 {
+				t.Fatalf("Expected checksums for 2 files to be requested, got %d", requested)
+			}
+			if len(upload) != 2 || uploaded["/changed"] == false || uploaded["/new"] == false {
+				t.Fatalf("Expected /changed and /new to be uploaded, got %#+v", uploaded)
+			}
+			if _, ok := remoteState["/equal"]; ok {
+				t.Fatal("Expected /equal to be removed from the remote state")
+			}
+		} else {
+			if requested != 0 {
+				t.Fatalf("Expected no checksums to be requested, got %d", requested)
+			}
+			if len(upload) != 3 {
+				t.Fatalf("Expected all files to be uploaded, got %#+v", uploaded)
+			}
+		}
+	}
+}
+
+func TestCalculateDeltaWithSnapshot(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	syncedMtime := time.Now().Add(-time.Hour).Unix()
+	changedMtime := time.Now().Unix()
+
+	// localDeleted only exists remotely and remoteDeleted only locally
+	localFiles := map[string]int64{
+		"unchanged":     syncedMtime,
+		"localChanged":  changedMtime,
+		"remoteChanged": syncedMtime,
+		"remoteDeleted": syncedMtime,
+	}
+	for name, mtime := range localFiles {
+		err = ioutil.WriteFile(filepath.Join(local, name), []byte("content"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.Chtimes(filepath.Join(local, name), time.Unix(mtime, 0), time.Unix(mtime, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshot := map[string]*FileInformation{}
+	for _, name := range []string{"/unchanged", "/localChanged", "/remoteChanged", "/remoteDeleted", "/localDeleted"} {
+		snapshot[name] = &FileInformation{Name: name, Size: int64(len("content")), Mtime: syncedMtime}
+	}
+
+	fileIndex := newFileIndex()
+	remoteState := map[string]*FileInformation{}
+	for name, mtime := range map[string]int64{"/unchanged": syncedMtime, "/localChanged": syncedMtime, "/remoteChanged": changedMtime, "/localDeleted": syncedMtime} {
+		fileIndex.Set(&FileInformation{
+			Name:  name,
+			Size:  int64(len("content")),
+			Mtime: mtime,
+		})
+		remoteState[name] = fileIndex.fileMap[name]
+	}
+
+	syncer := newInitialSyncer(&initialSyncOptions{
+		LocalPath: local,
+		Strategy:  latest.InitialSyncStrategyPreferLocal,
+		FileIndex: fileIndex,
+		Snapshot:  snapshot,
+		Log:       log.Discard,
+	})
+
+	upload, err := syncer.CalculateDelta(remoteState)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(upload) != 1 || upload[0].Name != "/localChanged" {
+		t.Fatalf("Expected only /localChanged to be uploaded, got %#+v", upload)
+	}
+	if len(remoteState) != 1 || remoteState["/remoteChanged"] == nil {
+		t.Fatalf("Expected only /remoteChanged to be downloaded, got %#+v", remoteState)
+	}
+	if len(syncer.deleteLocal) != 1 || syncer.deleteLocal[0].Name != "/remoteDeleted" {
+		t.Fatalf("Expected only /remoteDeleted to be deleted locally, got %#+v", syncer.deleteLocal)
+	}
+	if len(syncer.deleteRemote) != 1 || syncer.deleteRemote[0].Name != "/localDeleted" {
+		t.Fatalf("Expected only /localDeleted to be deleted remotely, got %#+v", syncer.deleteRemote)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	mtime := time.Now().Add(-time.Hour).Unix()
+	for _, name := range []string{"local", "shared"} {
+		err = ioutil.WriteFile(filepath.Join(local, name), []byte("content"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.Chtimes(filepath.Join(local, name), time.Unix(mtime, 0), time.Unix(mtime, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		strategy latest.InitialSyncStrategy
+
+		expectedUpload       []string
+		expectedDeleteRemote []string
+		expectedDownload     []string
+		expectedDeleteLocal  []string
+	}{
+		{
+			strategy:             latest.InitialSyncStrategyMirrorLocal,
+			expectedUpload:       []string{"/local"},
+			expectedDeleteRemote: []string{"/remote"},
+		},
+		{
+			strategy:            latest.InitialSyncStrategyMirrorRemote,
+			expectedDownload:    []string{"/remote"},
+			expectedDeleteLocal: []string{"/local"},
+		},
+		{
+			strategy:         latest.InitialSyncStrategyPreferLocal,
+			expectedUpload:   []string{"/local"},
+			expectedDownload: []string{"/remote"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		fileIndex := newFileIndex()
+		remoteState := map[string]*FileInformation{}
+		for _, name := range []string{"/shared", "/remote"} {
+			fileIndex.Set(&FileInformation{
+				Name:  name,
+				Size:  int64(len("content")),
+				Mtime: mtime,
+			})
+			remoteState[name] = fileIndex.fileMap[name]
+		}
+
+		syncer := newInitialSyncer(&initialSyncOptions{
+			LocalPath: local,
+			Strategy:  testCase.strategy,
+			FileIndex: fileIndex,
+			Log:       log.Discard,
+		})
+
+		plan, err := syncer.plan(remoteState)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		upload, deleteRemote, download, deleteLocal := []string{}, []string{}, []string{}, []string{}
+		for _, element := range plan.upload {
+			upload = append(upload, element.Name)
+		}
+		for _, element := range plan.deleteRemote {
+			deleteRemote = append(deleteRemote, element.Name)
+		}
+		for _, change := range plan.download {
+			download = append(download, change.Path)
+		}
+		for _, change := range plan.deleteLocal {
+			deleteLocal = append(deleteLocal, change.Path)
+		}
+
+		for _, check := range []struct {
+			name     string
+			actual   []string
+			expected []string
+		}{
+			{"upload", upload, testCase.expectedUpload},
+			{"delete remote", deleteRemote, testCase.expectedDeleteRemote},
+			{"download", download, testCase.expectedDownload},
+			{"delete local", deleteLocal, testCase.expectedDeleteLocal},
+		} {
+			if strings.Join(check.actual, ",") != strings.Join(check.expected, ",") {
+				t.Fatalf("Unexpected %s for %s: expected %v, got %v", check.name, testCase.strategy, check.expected, check.actual)
+			}
+		}
+	}
+}
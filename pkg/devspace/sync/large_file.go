@@ -0,0 +1,212 @@
+package sync
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// largeFileChunkSize is the size of the chunks a large file is uploaded in
+var largeFileChunkSize = 512 * 1024
+
+// errLargeFilesUnsupported is returned by uploadLargeFile if the helper doesn't support chunked uploads
+var errLargeFilesUnsupported = errors.New("helper doesn't support chunked uploads")
+
+// errLargeFileChanged is returned by uploadLargeFile if the file was truncated during the upload
+var errLargeFileChanged = errors.New("file changed during upload")
+
+// applyLargeFiles uploads the files above the large file threshold one by one in chunks and returns the files that
+// still need to be uploaded within an archive. If an upload is interrupted, the helper keeps the already written part
+// and the next upload of the same file resumes from there. The uploads can take long, so s.fileIndex is only locked
+// to update the file map and must not be locked before this function is called
+func (u *upstream) applyLargeFiles(files []*FileInformation) ([]*FileInformation, error) {
+	u.sync.fileIndex.fileMapMutex.Lock()
+	largeFilesUnsupported := u.largeFilesUnsupported
+	u.sync.fileIndex.fileMapMutex.Unlock()
+	if largeFilesUnsupported {
+		return files, nil
+	}
+
+	var (
+		rest    = make([]*FileInformation, 0, len(files))
+		requeue = []*FileInformation{}
+	)
+	defer func() {
+		u.requeue(requeue)
+	}()
+
+	for i, file := range files {
+		if file.IsDirectory || file.IsSymbolicLink || file.LinkTarget != "" || file.Size < largeFileThreshold {
+			rest = append(rest, file)
+			continue
+		}
+
+		uploaded, err := u.uploadLargeFile(file)
+		if err == errLargeFilesUnsupported {
+			u.sync.fileIndex.fileMapMutex.Lock()
+			u.largeFilesUnsupported = true
+			u.sync.fileIndex.fileMapMutex.Unlock()
+			return append(rest, files[i:]...), nil
+		} else if os.IsNotExist(errors.Cause(err)) {
+			// the file was removed in the meantime
+			continue
+		} else if err != nil && err != errLargeFileChanged {
+			return nil, errors.Wrapf(err, "upload %s", file.Name)
+		}
+
+		u.sync.fileIndex.fileMapMutex.Lock()
+		if err == errLargeFileChanged {
+			if u.retryUpload(file, "File changed during upload") {
+				requeue = append(requeue, file)
+			}
+		} else if uploaded == nil {
+			if u.retryUpload(file, "Checksum mismatch") {
+				requeue = append(requeue, file)
+			}
+		} else {
+			delete(u.uploadFailures, file.Name)
+			u.sync.fileIndex.CreateDirInFileMap(path.Dir(file.Name))
+			u.sync.fileIndex.fileMap[file.Name] = uploaded
+		}
+		u.sync.fileIndex.fileMapMutex.Unlock()
+	}
+
+	return rest, nil
+}
+
+// uploadLargeFile uploads a single file in chunks, starting at the offset the helper has already written. It returns
+// the uploaded file information or nil if the helper discarded the file, because its checksum didn't match. If the
+// file is truncated during the upload, errLargeFileChanged is returned
+func (u *upstream) uploadLargeFile(file *FileInformation) (*FileInformation, error) {
+	f, err := os.Open(path.Join(u.sync.LocalPath, file.Name))
+	if err != nil {
+		return nil, errors.Wrap(err, "open")
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "stat")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	uploadClient, err := u.client.UploadFile(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "upload file")
+	}
+
+	err = uploadClient.Send(&remote.FileChunk{
+		Path:      file.Name,
+		MtimeUnix: stat.ModTime().Unix(),
+		Size:      stat.Size(),
+		Mode:      uint32(chmodTarEntry(stat.Mode().Perm())),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "send header")
+	}
+
+	resume, err := uploadClient.Recv()
+	if status.Code(err) == codes.Unimplemented {
+		return nil, errLargeFilesUnsupported
+	} else if err != nil {
+		return nil, errors.Wrap(err, "receive offset")
+	}
+
+	offset := resume.Offset
+	if offset > 0 {
+		u.sync.log.Infof("Upstream - Resume upload of '%s' at %s of %s", u.getRelativeUpstreamPath(file.Name), FormatBytes(offset), FormatBytes(stat.Size()))
+	} else {
+		u.sync.log.Infof("Upstream - Upload File '%s' in chunks (~%0.2f KB)", u.getRelativeUpstreamPath(file.Name), float64(stat.Size())/1024.0)
+	}
+
+	// The already uploaded part only needs to be hashed for the checksum
+	hash := sha256.New()
+	if _, err := io.CopyN(hash, f, offset); err != nil {
+		return nil, errors.Wrap(err, "hash uploaded part")
+	}
+
+	// The helper acknowledges every chunk, the acknowledgements are received concurrently
+	// so that the helper is never blocked by them
+	type result struct {
+		last *remote.FileOffset
+		err  error
+	}
+	resultChan := make(chan result, 1)
+	go func() {
+		var last *remote.FileOffset
+		for {
+			ack, err := uploadClient.Recv()
+			if err == io.EOF {
+				resultChan <- result{last: last}
+				return
+			} else if err != nil {
+				resultChan <- result{err: err}
+				return
+			}
+
+			last = ack
+		}
+	}()
+
+	progress := u.sync.startProgress(ProgressDirectionUpload, 1, stat.Size()-offset)
+	defer progress.Done()
+
+	reader := progress.reader(io.TeeReader(f, hash))
+	buf := make([]byte, largeFileChunkSize)
+	for offset < stat.Size() {
+		n := int64(len(buf))
+		if stat.Size()-offset < n {
+			n = stat.Size() - offset
+		}
+
+		_, err := io.ReadFull(reader, buf[:n])
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			// the helper discards the partial file if the upload ends early
+			_ = uploadClient.CloseSend()
+			<-resultChan
+			return nil, errLargeFileChanged
+		} else if err != nil {
+			return nil, errors.Wrap(err, "read")
+		}
+
+		err = uploadClient.Send(&remote.FileChunk{
+			Offset:  offset,
+			Content: buf[:n],
+		})
+		if err != nil {
+			// the stream was closed, the actual error is returned by the receiver
+			break
+		}
+
+		u.sync.countUploaded(int(n))
+		offset += n
+	}
+
+	if offset == stat.Size() {
+		err = uploadClient.Send(&remote.FileChunk{Checksum: hex.EncodeToString(hash.Sum(nil))})
+		if err == nil {
+			_ = uploadClient.CloseSend()
+		}
+	}
+
+	r := <-resultChan
+	if r.err != nil {
+		return nil, r.err
+	} else if r.last == nil || r.last.Offset != stat.Size() {
+		return nil, errors.Errorf("upload incomplete")
+	} else if r.last.ChecksumMismatch {
+		return nil, nil
+	}
+
+	return createFileInformationFromStat(file.Name, stat), nil
+}
@@ -0,0 +1,121 @@
+package sync
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestApplyLargeFiles(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+	container, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(container)
+
+	defer func(threshold int64, chunkSize int) {
+		largeFileThreshold = threshold
+		largeFileChunkSize = chunkSize
+	}(largeFileThreshold, largeFileChunkSize)
+	largeFileThreshold = 1024
+	largeFileChunkSize = 1024
+
+	content := bytes.Repeat([]byte("0123456789"), 500)
+	err = ioutil.WriteFile(filepath.Join(local, "large"), content, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(local, "small"), []byte("small"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stat, err := os.Stat(filepath.Join(local, "large"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the partial file of an interrupted upload with corrupted content
+	partialPath := filepath.Join(container, ".large.devspace-partial-"+strconv.FormatInt(stat.Size(), 36)+"-"+strconv.FormatInt(stat.ModTime().Unix(), 36))
+	err = ioutil.WriteFile(partialPath, bytes.Repeat([]byte("x"), 1500), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clientReader, clientWriter := io.Pipe()
+	serverReader, serverWriter := io.Pipe()
+	defer clientWriter.Close()
+	defer serverWriter.Close()
+	go func() {
+		_ = server.StartUpstreamServer(serverReader, clientWriter, &server.UpstreamOptions{
+			UploadPath: container,
+		})
+	}()
+
+	conn, err := util.NewClientConnection(clientReader, serverWriter)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync, err := NewSync(local, Options{Log: log.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &upstream{
+		sync:           sync,
+		client:         remote.NewUpstreamClient(conn),
+		uploadFailures: map[string]int{},
+	}
+	files := []*FileInformation{
+		{Name: "/large", Size: stat.Size()},
+		{Name: "/small", Size: 5},
+	}
+
+	// the upload resumes the partial file, which fails the checksum verification
+	rest, err := u.applyLargeFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(rest) != 1 || rest[0].Name != "/small" {
+		t.Fatalf("Expected only /small to be left for the archive, got %v", rest)
+	} else if len(u.eventBuffer) != 1 || u.uploadFailures["/large"] != 1 {
+		t.Fatalf("Expected /large to be requeued")
+	} else if _, err := os.Stat(partialPath); os.IsNotExist(err) == false {
+		t.Fatal("Expected corrupted partial file to be removed")
+	}
+
+	// the retry uploads the complete file
+	_, err = u.applyLargeFiles(files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(container, "large"))
+	if err != nil {
+		t.Fatal(err)
+	} else if bytes.Equal(data, content) == false {
+		t.Fatal("Uploaded file differs from local file")
+	} else if sync.fileIndex.fileMap["/large"] == nil || sync.fileIndex.fileMap["/large"].Size != stat.Size() {
+		t.Fatalf("Expected /large in file map, got %v", sync.fileIndex.fileMap["/large"])
+	} else if len(u.uploadFailures) > 0 {
+		t.Fatalf("Expected upload failures to be reset, got %v", u.uploadFailures)
+	}
+
+	entries, err := ioutil.ReadDir(container)
+	if err != nil {
+		t.Fatal(err)
+	} else if len(entries) != 1 {
+		t.Fatalf("Expected only the uploaded file in the container, got %d entries", len(entries))
+	}
+}
@@ -0,0 +1,194 @@
+package sync
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/loft-sh/devspace/helper/remote"
+)
+
+// List of reasons why a remote path is not synced
+const (
+	CollisionReasonCase    = "differs only in case"
+	CollisionReasonWindows = "is not a valid windows path"
+)
+
+// Collision is a remote path that is not synced, because it cannot be represented on the local filesystem
+type Collision struct {
+	Path         string    `json:"path"`
+	CollidesWith string    `json:"collidesWith,omitempty"`
+	Reason       string    `json:"reason"`
+	Time         time.Time `json:"time"`
+}
+
+// Collisions returns the remote paths that are currently not synced, because they collide with
+// another path on the local filesystem
+func (s *Sync) Collisions() []Collision {
+	return s.fileIndex.Collisions()
+}
+
+func (s *Sync) reportCollision(collision *Collision) {
+	if collision.CollidesWith != "" {
+		s.log.Warnf("Collision - '.%s' %s from '.%s' and is not synced, because the local filesystem is case insensitive", collision.Path, collision.Reason, collision.CollidesWith)
+		return
+	}
+
+	s.log.Warnf("Collision - '.%s' %s and is not synced", collision.Path, collision.Reason)
+}
+
+// detectCaseInsensitive checks if the filesystem of the given directory ignores the case of paths by
+// looking up the directory with a swapped case. If the name has no letters the os default is assumed
+func detectCaseInsensitive(absPath string) bool {
+	dir, name := filepath.Split(absPath)
+	swapped := strings.Map(func(r rune) rune {
+		if unicode.IsUpper(r) {
+			return unicode.ToLower(r)
+		}
+
+		return unicode.ToUpper(r)
+	}, name)
+	if swapped == name {
+		return runtime.GOOS == "windows" || runtime.GOOS == "darwin"
+	}
+
+	stat, err := os.Stat(absPath)
+	if err != nil {
+		return false
+	}
+
+	swappedStat, err := os.Stat(filepath.Join(dir, swapped))
+	if err != nil {
+		return false
+	}
+
+	return os.SameFile(stat, swappedStat)
+}
+
+// invalidWindowsPath checks if the remote path contains characters that cannot be used on windows
+func invalidWindowsPath(path string) bool {
+	for _, part := range strings.Split(path, "/") {
+		if part == "" {
+			continue
+		} else if strings.ContainsAny(part, "\\:*?\"<>|") || strings.HasSuffix(part, ".") || strings.HasSuffix(part, " ") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AcceptChange checks if the remote change can be applied locally without overwriting or deleting
+// a path that only differs in case. The first path of such a group owns it and is synced, all others
+// are skipped in both directions, which prevents them from overwriting each other endlessly. A collision
+// is returned if the change is skipped and the path wasn't reported before
+func (f *fileIndex) AcceptChange(change *remote.Change) (bool, *Collision) {
+	f.collisionMutex.Lock()
+	defer f.collisionMutex.Unlock()
+
+	if _, ok := f.collisions[change.Path]; ok {
+		if change.ChangeType == remote.ChangeType_DELETE {
+			delete(f.collisions, change.Path)
+		}
+
+		return false, nil
+	}
+
+	if change.ChangeType == remote.ChangeType_DELETE {
+		if f.caseInsensitive {
+			if owner := f.owner(change.Path); owner != "" && owner != change.Path {
+				return false, nil
+			}
+
+			// release the path and everything below it
+			folded := strings.ToLower(change.Path)
+			delete(f.owners, folded)
+			for key := range f.owners {
+				if strings.HasPrefix(key, folded+"/") {
+					delete(f.owners, key)
+				}
+			}
+		}
+
+		return true, nil
+	}
+
+	collision := Collision{
+		Path: change.Path,
+		Time: time.Now(),
+	}
+	if f.windows && invalidWindowsPath(change.Path) {
+		collision.Reason = CollisionReasonWindows
+	} else if f.caseInsensitive {
+		owner := f.owner(change.Path)
+		if owner == "" {
+			f.owners[strings.ToLower(change.Path)] = change.Path
+			return true, nil
+		} else if owner == change.Path {
+			return true, nil
+		}
+
+		collision.CollidesWith = owner
+		collision.Reason = CollisionReasonCase
+	} else {
+		return true, nil
+	}
+
+	f.collisions[change.Path] = collision
+	return false, &collision
+}
+
+// CanonicalPath replaces the parts of a local path that are owned by a remote path with a different case by
+// the remote path, so that local changes are applied to the existing remote path instead of creating a new one
+func (f *fileIndex) CanonicalPath(path string) string {
+	if f.caseInsensitive == false {
+		return path
+	}
+
+	f.collisionMutex.Lock()
+	defer f.collisionMutex.Unlock()
+
+	parts := strings.Split(path, "/")
+	for i := len(parts); i > 0; i-- {
+		prefix := strings.Join(parts[:i], "/")
+		if owner, ok := f.owners[strings.ToLower(prefix)]; ok {
+			return owner + path[len(prefix):]
+		}
+	}
+
+	return path
+}
+
+// Collisions returns the currently skipped remote paths sorted by path
+func (f *fileIndex) Collisions() []Collision {
+	f.collisionMutex.Lock()
+	defer f.collisionMutex.Unlock()
+
+	collisions := make([]Collision, 0, len(f.collisions))
+	for _, collision := range f.collisions {
+		collisions = append(collisions, collision)
+	}
+
+	sort.Slice(collisions, func(i, j int) bool {
+		return collisions[i].Path < collisions[j].Path
+	})
+	return collisions
+}
+
+// owner returns the remote path that owns the path or the first parent directory that is owned by a
+// path with a different case. f.collisionMutex needs to be locked before this function is called
+func (f *fileIndex) owner(path string) string {
+	parts := strings.Split(path, "/")
+	for i := 1; i < len(parts); i++ {
+		prefix := strings.Join(parts[:i], "/")
+		if owner, ok := f.owners[strings.ToLower(prefix)]; ok && owner != prefix {
+			return owner
+		}
+	}
+
+	return f.owners[strings.ToLower(path)]
+}
@@ -0,0 +1,109 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/loft-sh/devspace/helper/remote"
+)
+
+func TestAcceptChangeCaseInsensitive(t *testing.T) {
+	// simulate a case folding local filesystem
+	fileIndex := newFileIndex()
+	fileIndex.caseInsensitive = true
+
+	type step struct {
+		change            *remote.Change
+		expectedAccept    bool
+		expectedCollision string
+	}
+
+	steps := []step{
+		{change: &remote.Change{Path: "/Foo.go"}, expectedAccept: true},
+		{change: &remote.Change{Path: "/foo.go"}, expectedCollision: "/Foo.go"},
+		// collisions are only reported once
+		{change: &remote.Change{Path: "/foo.go"}},
+		{change: &remote.Change{Path: "/Foo.go"}, expectedAccept: true},
+		{change: &remote.Change{Path: "/Src", IsDir: true}, expectedAccept: true},
+		{change: &remote.Change{Path: "/Src/a.go"}, expectedAccept: true},
+		{change: &remote.Change{Path: "/src/b.go"}, expectedCollision: "/Src"},
+		// deleting a colliding path must not delete the owner locally
+		{change: &remote.Change{Path: "/foo.go", ChangeType: remote.ChangeType_DELETE}},
+		{change: &remote.Change{Path: "/FOO.go", ChangeType: remote.ChangeType_DELETE}},
+		{change: &remote.Change{Path: "/Foo.go", ChangeType: remote.ChangeType_DELETE}, expectedAccept: true},
+		// the path is free again after the owner was deleted
+		{change: &remote.Change{Path: "/foo.go"}, expectedAccept: true},
+	}
+
+	for idx, step := range steps {
+		accept, collision := fileIndex.AcceptChange(step.change)
+		if accept != step.expectedAccept {
+			t.Fatalf("Step %d: expected accept %v for %s, got %v", idx, step.expectedAccept, step.change.Path, accept)
+		}
+		if step.expectedCollision == "" && collision != nil {
+			t.Fatalf("Step %d: unexpected collision %s with %s", idx, collision.Path, collision.CollidesWith)
+		} else if step.expectedCollision != "" && (collision == nil || collision.CollidesWith != step.expectedCollision || collision.Reason != CollisionReasonCase) {
+			t.Fatalf("Step %d: expected collision of %s with %s, got %#+v", idx, step.change.Path, step.expectedCollision, collision)
+		}
+	}
+
+	collisions := fileIndex.Collisions()
+	if len(collisions) != 1 || collisions[0].Path != "/src/b.go" {
+		t.Fatalf("Unexpected collisions %#+v", collisions)
+	}
+
+	canonicalPaths := map[string]string{
+		"/FOO.go":       "/foo.go",
+		"/SRC/a.go":     "/Src/a.go",
+		"/src/new.go":   "/Src/new.go",
+		"/other/x.go":   "/other/x.go",
+		"/Src/SUB/y.go": "/Src/SUB/y.go",
+	}
+	for path, expected := range canonicalPaths {
+		if canonical := fileIndex.CanonicalPath(path); canonical != expected {
+			t.Fatalf("Expected canonical path %s for %s, got %s", expected, path, canonical)
+		}
+	}
+}
+
+func TestAcceptChangeWindows(t *testing.T) {
+	fileIndex := newFileIndex()
+	fileIndex.windows = true
+
+	for path, expectedAccept := range map[string]bool{
+		"/a/b.go":     true,
+		"/a:b/c.go":   false,
+		"/what?":      false,
+		"/trailing. ": false,
+		"/dir./c.go":  false,
+	} {
+		accept, collision := fileIndex.AcceptChange(&remote.Change{Path: path})
+		if accept != expectedAccept {
+			t.Fatalf("Expected accept %v for %s, got %v", expectedAccept, path, accept)
+		} else if accept == false && (collision == nil || collision.Reason != CollisionReasonWindows) {
+			t.Fatalf("Expected windows collision for %s, got %#+v", path, collision)
+		}
+	}
+}
+
+func TestDetectCaseInsensitive(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("filesystem case sensitivity is only known on linux")
+	}
+
+	dir, err := ioutil.TempDir("", "CaseTest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if detectCaseInsensitive(dir) {
+		t.Fatalf("Expected %s to be case sensitive", dir)
+	}
+	if detectCaseInsensitive(filepath.Join(dir, "missing")) {
+		t.Fatal("Expected missing path to be case sensitive")
+	}
+}
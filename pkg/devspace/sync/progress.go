@@ -0,0 +1,175 @@
+package sync
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressInterval is the interval in which the progress of a running transfer is reported. Transfers
+// that finish faster are not reported at all
+const progressInterval = time.Second
+
+// List of directions of a transfer
+const (
+	ProgressDirectionUpload   = "upload"
+	ProgressDirectionDownload = "download"
+)
+
+// Progress is the state of a running upload or download of the sync
+type Progress struct {
+	Direction string `json:"direction"`
+	Files     int    `json:"files"`
+
+	// BytesDone and BytesTotal are the uncompressed sizes of the transferred files
+	BytesDone  int64 `json:"bytesDone"`
+	BytesTotal int64 `json:"bytesTotal"`
+
+	// Rate is the average amount of bytes per second since the transfer was started
+	Rate int64 `json:"rate"`
+
+	// ETA is the estimated remaining time in seconds, it is -1 if it cannot be estimated yet
+	ETA int64 `json:"eta"`
+
+	Started time.Time `json:"started"`
+	Done    bool      `json:"done"`
+}
+
+// String formats the progress as a single line for the terminal
+func (p Progress) String() string {
+	action := "Upstream - Upload"
+	if p.Direction == ProgressDirectionDownload {
+		action = "Downstream - Download"
+	}
+
+	percent := int64(100)
+	if p.BytesTotal > 0 {
+		percent = p.BytesDone * 100 / p.BytesTotal
+	}
+
+	eta := "unknown"
+	if p.ETA >= 0 {
+		eta = (time.Duration(p.ETA) * time.Second).String()
+	}
+
+	return fmt.Sprintf("%s %d file(s): %s / %s (%d%%), %s/s, ETA %s", action, p.Files, FormatBytes(p.BytesDone), FormatBytes(p.BytesTotal), percent, FormatBytes(p.Rate), eta)
+}
+
+// FormatBytes formats the amount of bytes with a binary unit
+func FormatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// progressTracker tracks the transferred bytes of an upload or download and periodically
+// reports the progress to the status of the sync
+type progressTracker struct {
+	// done is accessed atomically and needs to stay at the start of the struct to be 64-bit aligned
+	done int64
+
+	sync      *Sync
+	direction string
+	files     int
+	total     int64
+	started   time.Time
+
+	reported bool
+	stopOnce sync.Once
+	stopped  chan struct{}
+	finished chan struct{}
+}
+
+// startProgress starts tracking a new transfer of the given amount of files and uncompressed bytes
+func (s *Sync) startProgress(direction string, files int, total int64) *progressTracker {
+	p := &progressTracker{
+		sync:      s,
+		direction: direction,
+		files:     files,
+		total:     total,
+		started:   time.Now(),
+		stopped:   make(chan struct{}),
+		finished:  make(chan struct{}),
+	}
+
+	go p.report()
+	return p
+}
+
+// reader counts the bytes read from the given reader as transferred. It can be called on a nil tracker
+func (p *progressTracker) reader(reader io.Reader) io.Reader {
+	if p == nil {
+		return reader
+	}
+
+	return &countingReader{reader: reader, count: &p.done}
+}
+
+// Done stops the tracking and reports the final state if the transfer was reported before
+func (p *progressTracker) Done() {
+	p.stopOnce.Do(func() {
+		close(p.stopped)
+		<-p.finished
+
+		if p.reported {
+			progress := p.progress()
+			progress.Done = true
+			progress.ETA = 0
+			p.sync.setProgress(progress)
+		}
+	})
+}
+
+func (p *progressTracker) report() {
+	defer close(p.finished)
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopped:
+			return
+		case <-ticker.C:
+			p.sync.setProgress(p.progress())
+			p.reported = true
+		}
+	}
+}
+
+func (p *progressTracker) progress() Progress {
+	done := atomic.LoadInt64(&p.done)
+	if done > p.total {
+		done = p.total
+	}
+
+	progress := Progress{
+		Direction:  p.direction,
+		Files:      p.files,
+		BytesDone:  done,
+		BytesTotal: p.total,
+		ETA:        -1,
+		Started:    p.started,
+	}
+
+	elapsed := time.Since(p.started).Seconds()
+	if elapsed > 0 {
+		progress.Rate = int64(float64(done) / elapsed)
+	}
+	if progress.Rate > 0 {
+		progress.ETA = (p.total - done) / progress.Rate
+	}
+
+	return progress
+}
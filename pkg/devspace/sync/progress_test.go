@@ -0,0 +1,87 @@
+package sync
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestProgress(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	events := make(chan Progress, 10)
+	sync, err := NewSync(local, Options{
+		Log: log.Discard,
+		OnProgress: func(progress Progress) {
+			events <- progress
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fast transfers are not reported at all
+	tracker := sync.startProgress(ProgressDirectionUpload, 1, 10)
+	_, _ = ioutil.ReadAll(tracker.reader(strings.NewReader("0123456789")))
+	tracker.Done()
+	if len(events) > 0 {
+		t.Fatalf("Unexpected progress event %#v", <-events)
+	}
+
+	tracker = sync.startProgress(ProgressDirectionDownload, 2, 100)
+	_, _ = ioutil.ReadAll(tracker.reader(strings.NewReader(strings.Repeat("x", 25))))
+
+	select {
+	case progress := <-events:
+		if progress.Direction != ProgressDirectionDownload || progress.Files != 2 || progress.BytesDone != 25 || progress.BytesTotal != 100 || progress.Done {
+			t.Fatalf("Unexpected progress %#v", progress)
+		} else if progress.Rate <= 0 || progress.ETA < 0 {
+			t.Fatalf("Expected rate and eta, got %#v", progress)
+		}
+	case <-time.After(progressInterval * 3):
+		t.Fatal("Timed out waiting for progress")
+	}
+
+	if status := sync.Status(); status.DownloadProgress == nil || status.DownloadProgress.BytesDone != 25 {
+		t.Fatalf("Expected download progress in status, got %#v", status.DownloadProgress)
+	}
+
+	tracker.Done()
+	for progress := range events {
+		if progress.Done {
+			break
+		}
+	}
+	if status := sync.Status(); status.DownloadProgress != nil {
+		t.Fatalf("Expected no download progress after done, got %#v", status.DownloadProgress)
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	progress := Progress{
+		Direction:  ProgressDirectionUpload,
+		Files:      3,
+		BytesDone:  1024 * 1024,
+		BytesTotal: 4 * 1024 * 1024,
+		Rate:       512 * 1024,
+		ETA:        6,
+	}
+
+	expected := "Upstream - Upload 3 file(s): 1.0 MB / 4.0 MB (25%), 512.0 KB/s, ETA 6s"
+	if progress.String() != expected {
+		t.Fatalf("Expected %s, got %s", expected, progress.String())
+	}
+
+	progress.ETA = -1
+	if strings.HasSuffix(progress.String(), "ETA unknown") == false {
+		t.Fatalf("Expected unknown eta, got %s", progress.String())
+	}
+}
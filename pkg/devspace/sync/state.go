@@ -0,0 +1,106 @@
+package sync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// stateSaveInterval is the interval in which the sync state is persisted while the sync is running
+var stateSaveInterval = time.Second * 30
+
+// syncState is the last known synced state of the local and remote files that is persisted
+// between sync sessions
+type syncState struct {
+	Files []*FileInformation `json:"files"`
+}
+
+// loadState loads the persisted sync state from the given path. If there is no state
+// nil is returned
+func loadState(statePath string) (map[string]*FileInformation, error) {
+	out, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	state := &syncState{}
+	err = json.Unmarshal(out, state)
+	if err != nil {
+		return nil, errors.Wrapf(err, "parse %s", statePath)
+	}
+
+	files := make(map[string]*FileInformation, len(state.Files))
+	for _, file := range state.Files {
+		if file != nil && file.Name != "" {
+			files[file.Name] = file
+		}
+	}
+
+	return files, nil
+}
+
+// saveState persists the current file index to the configured state path. The state is written to
+// a temporary file first and then renamed, so that an interrupted write doesn't corrupt the old state
+func (s *Sync) saveState() error {
+	if s.Options.StatePath == "" {
+		return nil
+	}
+
+	s.fileIndex.fileMapMutex.Lock()
+	state := &syncState{
+		Files: make([]*FileInformation, 0, len(s.fileIndex.fileMap)),
+	}
+	for _, file := range s.fileIndex.fileMap {
+		if file.IsSymbolicLink {
+			continue
+		}
+
+		copied := *file
+		state.Files = append(state.Files, &copied)
+	}
+	s.fileIndex.fileMapMutex.Unlock()
+
+	out, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.Options.StatePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	tempPath := s.Options.StatePath + ".tmp"
+	err = ioutil.WriteFile(tempPath, out, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempPath, s.Options.StatePath)
+}
+
+// persistState saves the sync state periodically until the sync is stopped
+func (s *Sync) persistState() {
+	ticker := time.NewTicker(stateSaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopped:
+			return
+		case <-ticker.C:
+			err := s.saveState()
+			if err != nil {
+				s.log.Infof("Error saving sync state: %v", err)
+			}
+		}
+	}
+}
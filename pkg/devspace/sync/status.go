@@ -0,0 +1,176 @@
+package sync
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Status is the runtime state of a sync
+type Status struct {
+	InitialSyncCompleted bool `json:"initialSyncCompleted"`
+	Stopped              bool `json:"stopped"`
+
+	// PendingUploads and PendingDownloads are the amount of changes that
+	// were detected but are not yet applied on the other side
+	PendingUploads   int   `json:"pendingUploads"`
+	PendingDownloads int64 `json:"pendingDownloads"`
+
+	// BytesUploaded and BytesDownloaded are the transferred payload bytes of the
+	// archives, deltas and file chunks in each direction
+	BytesUploaded   int64 `json:"bytesUploaded"`
+	BytesDownloaded int64 `json:"bytesDownloaded"`
+
+	LastUpload    *time.Time `json:"lastUpload,omitempty"`
+	LastDownload  *time.Time `json:"lastDownload,omitempty"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+
+	// UploadProgress and DownloadProgress are the running transfers that take longer than a second
+	UploadProgress   *Progress `json:"uploadProgress,omitempty"`
+	DownloadProgress *Progress `json:"downloadProgress,omitempty"`
+}
+
+// statusTracker holds the values of the status that are updated by upstream and downstream
+type statusTracker struct {
+	// bytesUploaded and bytesDownloaded are accessed atomically and need
+	// to stay at the start of the struct to be 64-bit aligned
+	bytesUploaded   int64
+	bytesDownloaded int64
+
+	pendingUploads   int
+	pendingDownloads int64
+
+	lastUpload    time.Time
+	lastDownload  time.Time
+	lastError     string
+	lastErrorTime time.Time
+
+	uploadProgress   *Progress
+	downloadProgress *Progress
+
+	mutex sync.Mutex
+}
+
+// Status returns the current runtime state of the sync
+func (s *Sync) Status() Status {
+	status := Status{
+		InitialSyncCompleted: s.isInitialSyncCompleted(),
+		BytesUploaded:        atomic.LoadInt64(&s.status.bytesUploaded),
+		BytesDownloaded:      atomic.LoadInt64(&s.status.bytesDownloaded),
+	}
+
+	select {
+	case <-s.stopped:
+		status.Stopped = true
+	default:
+	}
+
+	s.status.mutex.Lock()
+	status.PendingUploads = s.status.pendingUploads
+	status.PendingDownloads = s.status.pendingDownloads
+	status.LastUpload = timeOrNil(s.status.lastUpload)
+	status.LastDownload = timeOrNil(s.status.lastDownload)
+	status.LastError = s.status.lastError
+	status.LastErrorTime = timeOrNil(s.status.lastErrorTime)
+	status.UploadProgress = s.status.uploadProgress
+	status.DownloadProgress = s.status.downloadProgress
+	s.status.mutex.Unlock()
+
+	// events that were not yet picked up by the upstream are pending as well
+	if s.upstream != nil {
+		s.upstream.eventBufferMutex.Lock()
+		status.PendingUploads += len(s.upstream.eventBuffer) + len(s.upstream.events)
+		s.upstream.eventBufferMutex.Unlock()
+	}
+
+	return status
+}
+
+func (s *Sync) setPendingUploads(amount int) {
+	s.status.mutex.Lock()
+	defer s.status.mutex.Unlock()
+
+	s.status.pendingUploads = amount
+}
+
+func (s *Sync) setPendingDownloads(amount int64) {
+	s.status.mutex.Lock()
+	defer s.status.mutex.Unlock()
+
+	s.status.pendingDownloads = amount
+}
+
+func (s *Sync) uploadDone() {
+	s.status.mutex.Lock()
+	defer s.status.mutex.Unlock()
+
+	s.status.pendingUploads = 0
+	s.status.lastUpload = time.Now()
+}
+
+func (s *Sync) downloadDone() {
+	s.status.mutex.Lock()
+	defer s.status.mutex.Unlock()
+
+	s.status.pendingDownloads = 0
+	s.status.lastDownload = time.Now()
+}
+
+// setProgress updates the running transfer of the status and notifies the OnProgress callback
+func (s *Sync) setProgress(progress Progress) {
+	s.status.mutex.Lock()
+	current := &progress
+	if progress.Done {
+		current = nil
+	}
+	if progress.Direction == ProgressDirectionUpload {
+		s.status.uploadProgress = current
+	} else {
+		s.status.downloadProgress = current
+	}
+	s.status.mutex.Unlock()
+
+	if s.Options.OnProgress != nil {
+		s.Options.OnProgress(progress)
+	}
+}
+
+func (s *Sync) setLastError(err error) {
+	s.status.mutex.Lock()
+	defer s.status.mutex.Unlock()
+
+	s.status.lastError = err.Error()
+	s.status.lastErrorTime = time.Now()
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
+
+// countingReader counts the bytes that are read from the underlying reader
+type countingReader struct {
+	reader io.Reader
+	count  *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	atomic.AddInt64(c.count, int64(n))
+	return n, err
+}
+
+// countUploaded adds the payload bytes of files that were sent to the container to the status
+func (s *Sync) countUploaded(n int) {
+	atomic.AddInt64(&s.status.bytesUploaded, int64(n))
+}
+
+// countDownloaded adds the payload bytes of files that were received from the container to the status
+func (s *Sync) countDownloaded(n int) {
+	atomic.AddInt64(&s.status.bytesDownloaded, int64(n))
+}
@@ -0,0 +1,51 @@
+package sync
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestStatus(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	sync, err := NewSync(local, Options{Log: log.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status := sync.Status()
+	if status.InitialSyncCompleted || status.Stopped || status.LastUpload != nil || status.LastError != "" {
+		t.Fatalf("Unexpected initial status %#v", status)
+	}
+
+	sync.countUploaded(len("upload"))
+	sync.countDownloaded(len("download"))
+
+	sync.setPendingUploads(3)
+	sync.setPendingDownloads(2)
+	status = sync.Status()
+	if status.PendingUploads != 3 || status.PendingDownloads != 2 {
+		t.Fatalf("Expected 3 pending uploads and 2 pending downloads, got %d and %d", status.PendingUploads, status.PendingDownloads)
+	}
+
+	sync.uploadDone()
+	sync.Stop(errors.New("test error"))
+	status = sync.Status()
+	if status.PendingUploads != 0 || status.LastUpload == nil || status.LastDownload != nil {
+		t.Fatalf("Unexpected status after upload %#v", status)
+	}
+	if status.BytesUploaded != int64(len("upload")) || status.BytesDownloaded != int64(len("download")) {
+		t.Fatalf("Expected %d bytes uploaded and %d bytes downloaded, got %d and %d", len("upload"), len("download"), status.BytesUploaded, status.BytesDownloaded)
+	}
+	if status.Stopped == false || status.LastError != "test error" || status.LastErrorTime == nil {
+		t.Fatalf("Unexpected status after stop %#v", status)
+	}
+}
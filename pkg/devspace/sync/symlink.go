@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/devspace/watch"
 	"github.com/loft-sh/devspace/pkg/util/log"
 	"github.com/syncthing/notify"
@@ -103,3 +104,33 @@ func (s *Symlink) Crawl() error {
 func (s *Symlink) Stop() {
 	s.watcher.Stop()
 }
+
+// lstatSymlink checks if the path is a symlink that is preserved or ignored instead of followed. For a preserved
+// symlink the file information of the symlink itself is returned, which is also the case if its target does not
+// exist. For an ignored symlink the returned file information is nil
+func lstatSymlink(symlinks latest.SyncSymlinks, relativePath, absPath string) (bool, *FileInformation) {
+	if symlinks != latest.SyncSymlinksPreserve && symlinks != latest.SyncSymlinksIgnore {
+		return false, nil
+	}
+
+	lstat, err := os.Lstat(absPath)
+	if err != nil || lstat.Mode()&os.ModeSymlink == 0 {
+		return false, nil
+	} else if symlinks == latest.SyncSymlinksIgnore {
+		return true, nil
+	}
+
+	linkTarget, err := os.Readlink(absPath)
+	if err != nil {
+		return true, nil
+	}
+
+	return true, &FileInformation{
+		Name:       relativePath,
+		Mtime:      lstat.ModTime().Unix(),
+		MtimeNano:  lstat.ModTime().UnixNano(),
+		Size:       lstat.Size(),
+		Mode:       lstat.Mode(),
+		LinkTarget: linkTarget,
+	}
+}
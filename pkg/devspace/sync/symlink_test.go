@@ -0,0 +1,178 @@
+// +build !windows
+
+package sync
+
+import (
+	"archive/tar"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/server/compression"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	"github.com/loft-sh/devspace/pkg/util/log"
+)
+
+func TestPreserveSymlinks(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	// broken symlinks are preserved as well
+	err = os.Symlink("missing", filepath.Join(local, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync, err := NewSync(local, Options{
+		Symlinks: latest.SyncSymlinksPreserve,
+		Log:      log.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isSymlink, fileInfo := lstatSymlink(sync.Options.Symlinks, "/link", filepath.Join(local, "link"))
+	if isSymlink == false || fileInfo == nil || fileInfo.LinkTarget != "missing" {
+		t.Fatalf("Expected preserved symlink, got %v", fileInfo)
+	}
+	if shouldUpload(sync, fileInfo) == false {
+		t.Fatal("Expected new symlink to be uploaded")
+	}
+
+	// the symlink is archived as symlink
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	archiver := NewArchiver(local, tar.NewWriter(w), nil)
+	archiver.symlinks = sync.Options.Symlinks
+	err = archiver.AddToArchive("/link")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	header, err := tar.NewReader(r).Next()
+	if err != nil {
+		t.Fatal(err)
+	} else if header.Typeflag != tar.TypeSymlink || header.Linkname != "missing" {
+		t.Fatalf("Expected symlink header, got %v", header)
+	}
+
+	// remote symlinks are created locally and only downloaded again if their target changes
+	sync.downstream = &downstream{sync: sync}
+	change := &remote.Change{Path: "/dir/remote", LinkTarget: "../link", MtimeUnix: 1}
+	sync.downstream.createSymlinks([]*remote.Change{change})
+
+	target, err := os.Readlink(filepath.Join(local, "dir", "remote"))
+	if err != nil {
+		t.Fatal(err)
+	} else if target != "../link" {
+		t.Fatalf("Expected symlink to ../link, got %s", target)
+	}
+	if shouldDownload(change, sync) {
+		t.Fatal("Expected unchanged symlink not to be downloaded")
+	}
+	if shouldDownload(&remote.Change{Path: "/dir/remote", LinkTarget: "other", MtimeUnix: 1}, sync) == false {
+		t.Fatal("Expected changed symlink to be downloaded")
+	}
+
+	isSymlink, fileInfo = lstatSymlink(sync.Options.Symlinks, "/dir/remote", filepath.Join(local, "dir", "remote"))
+	if isSymlink == false || shouldUpload(sync, fileInfo) {
+		t.Fatal("Expected downloaded symlink not to be uploaded again")
+	}
+}
+
+func TestUntarReplacesSymlinks(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	outside, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(outside)
+
+	err = ioutil.WriteFile(filepath.Join(outside, "target"), []byte("outside"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(filepath.Join(outside, "target"), filepath.Join(local, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Symlink(outside, filepath.Join(local, "dirlink"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync, err := NewSync(local, Options{
+		Symlinks: latest.SyncSymlinksPreserve,
+		Log:      log.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the remote symlinks were changed into a file and a folder
+	unarchiver := NewUnarchiver(sync, false, log.Discard)
+	for _, name := range []string{"link", "dirlink/file"} {
+		err = unarchiver.Untar(createTestArchive(t, name, "remote", time.Now().Add(time.Hour)), compression.Legacy, sync.LocalPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"link", "dirlink/file"} {
+		lstat, err := os.Lstat(filepath.Join(local, name))
+		if err != nil {
+			t.Fatal(err)
+		} else if lstat.Mode().IsRegular() == false {
+			t.Fatalf("Expected %s to be a regular file, got %v", name, lstat.Mode())
+		}
+	}
+
+	// nothing is written outside of the sync path
+	content, err := ioutil.ReadFile(filepath.Join(outside, "target"))
+	if err != nil {
+		t.Fatal(err)
+	} else if string(content) != "outside" {
+		t.Fatalf("Expected symlink target to be unchanged, got %s", string(content))
+	}
+	if _, err := os.Stat(filepath.Join(outside, "file")); err == nil {
+		t.Fatal("Expected no file to be written to the symlinked folder")
+	}
+}
+
+func TestIgnoreSymlinks(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	err = os.Symlink("missing", filepath.Join(local, "link"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isSymlink, fileInfo := lstatSymlink(latest.SyncSymlinksIgnore, "/link", filepath.Join(local, "link"))
+	if isSymlink == false || fileInfo != nil {
+		t.Fatal("Expected ignored symlink")
+	}
+
+	// symlinks are followed by default
+	isSymlink, _ = lstatSymlink("", "/link", filepath.Join(local, "link"))
+	if isSymlink {
+		t.Fatal("Expected followed symlink")
+	}
+}
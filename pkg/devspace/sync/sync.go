@@ -1,9 +1,13 @@
 package sync
 
 import (
+	"github.com/loft-sh/devspace/helper/server/compression"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/helper/server/permissions"
 	"io"
+	"os"
 	"path/filepath"
+	"runtime"
 	"sync"
 	"time"
 
@@ -17,6 +21,14 @@ import (
 var syncRetries = 5
 var initialUpstreamBatchSize = 1000
 
+// deltaTransferThreshold is the minimum file size for which only the changed
+// blocks of an already existing file are transferred instead of the complete file
+var deltaTransferThreshold int64 = 1024 * 1024
+
+// largeFileThreshold is the minimum file size for which a file is uploaded on its own in chunks
+// instead of within an archive, so that an interrupted upload can be resumed
+var largeFileThreshold int64 = 64 * 1024 * 1024
+
 // Options holds the sync options
 type Options struct {
 	Polling bool
@@ -25,6 +37,15 @@ type Options struct {
 	DownloadExcludePaths []string
 	UploadExcludePaths   []string
 
+	// UseGitignore and UseDockerignore add the patterns of the .gitignore
+	// and .dockerignore files in the local path to the exclude paths
+	UseGitignore    bool
+	UseDockerignore bool
+
+	// Permissions are the rules that map the permissions of downloaded files and directories,
+	// owner and group are only applied inside the container
+	Permissions []permissions.Rule
+
 	RestartContainer bool
 
 	FileChangeCmd  string
@@ -37,11 +58,28 @@ type Options struct {
 	DownstreamLimit int64
 	Verbose         bool
 
+	// Compression is the preferred compression of the transferred archives, the helper falls
+	// back to gzip if it does not support it. CompressionLevel is specific to the compression
+	Compression      compression.Codec
+	CompressionLevel int
+
 	UpstreamDisabled   bool
 	DownstreamDisabled bool
 
 	InitialSyncCompareBy latest.InitialSyncCompareBy
 	InitialSync          latest.InitialSyncStrategy
+	ConflictStrategy     latest.ConflictStrategy
+
+	// Symlinks defines if local and remote symlinks are followed, preserved or ignored
+	Symlinks latest.SyncSymlinks
+
+	// StatePath is the path where the last known synced state is persisted. If the
+	// state exists on start, only the paths that changed since then are reconciled
+	StatePath string
+
+	// OnProgress is called periodically with the progress of uploads and downloads that take
+	// longer than a second and once more after they are done
+	OnProgress func(progress Progress)
 
 	Log log.Logger
 }
@@ -57,6 +95,8 @@ type Sync struct {
 	downloadIgnoreMatcher ignoreparser.IgnoreParser
 	uploadIgnoreMatcher   ignoreparser.IgnoreParser
 
+	permissionMapper *permissions.Mapper
+
 	log log.Logger
 
 	upstream   *upstream
@@ -64,6 +104,17 @@ type Sync struct {
 
 	silent   bool
 	stopOnce sync.Once
+	stopped  chan struct{}
+
+	// initialSyncCompleted is true as soon as the initial sync is completed in both
+	// directions and the file index represents the synced state of both sides
+	initialSyncCompleted      bool
+	initialSyncCompletedMutex sync.Mutex
+
+	conflicts      []Conflict
+	conflictsMutex sync.Mutex
+
+	status *statusTracker
 
 	onError chan error
 	onDone  chan struct{}
@@ -89,6 +140,11 @@ func NewSync(localPath string, options Options) (*Sync, error) {
 		options.ExcludePaths = []string{}
 	}
 
+	options.ExcludePaths, err = ignoreparser.MergeIgnoreFiles(absoluteLocalPath, options.ExcludePaths, options.UseGitignore, options.UseDockerignore)
+	if err != nil {
+		return nil, errors.Wrap(err, "load ignore files")
+	}
+
 	// We exclude the sync log to prevent an endless loop in upstream
 	options.ExcludePaths = append(options.ExcludePaths, ".devspace/")
 
@@ -104,19 +160,31 @@ func NewSync(localPath string, options Options) (*Sync, error) {
 
 		fileIndex: newFileIndex(),
 		log:       options.Log,
+		stopped:   make(chan struct{}),
+		status:    &statusTracker{},
 	}
 
+	// paths that only differ in case or can't be created on windows are not synced to the local filesystem
+	s.fileIndex.caseInsensitive = detectCaseInsensitive(absoluteLocalPath)
+	s.fileIndex.windows = runtime.GOOS == "windows"
+
 	err = s.initIgnoreParsers()
 	if err != nil {
 		return nil, errors.Wrap(err, "init ignore parsers")
 	}
 
+	s.permissionMapper, err = permissions.NewMapper(options.Permissions)
+	if err != nil {
+		return nil, errors.Wrap(err, "compile permissions")
+	}
+
 	return s, nil
 }
 
 // Error handles a sync error
 func (s *Sync) Error(err error) {
 	s.log.Errorf("Sync Error on %s: %v", s.LocalPath, err)
+	s.setLastError(err)
 }
 
 // InitUpstream inits the upstream
@@ -241,21 +309,25 @@ func (s *Sync) initialSync(onInitUploadDone chan struct{}, onInitDownloadDone ch
 		return errors.Wrap(err, "populate file map")
 	}
 
-	downloadChanges := make(map[string]*FileInformation)
-	s.fileIndex.fileMapMutex.Lock()
-	for key, element := range s.fileIndex.fileMap {
-		if element.IsSymbolicLink {
-			continue
+	var (
+		initialSyncMutex sync.Mutex
+		initialSyncDone  = 0
+	)
+	partDone := func() {
+		initialSyncMutex.Lock()
+		defer initialSyncMutex.Unlock()
+
+		initialSyncDone++
+		if initialSyncDone == 2 {
+			s.onInitialSyncDone()
 		}
-
-		downloadChanges[key] = element
 	}
-	s.fileIndex.fileMapMutex.Unlock()
 
 	initialSync := newInitialSyncer(&initialSyncOptions{
 		LocalPath: s.LocalPath,
 		Strategy:  s.Options.InitialSync,
 		CompareBy: s.Options.InitialSyncCompareBy,
+		Symlinks:  s.Options.Symlinks,
 
 		IgnoreMatcher:         s.ignoreMatcher,
 		DownloadIgnoreMatcher: s.downloadIgnoreMatcher,
@@ -264,25 +336,31 @@ func (s *Sync) initialSync(onInitUploadDone chan struct{}, onInitDownloadDone ch
 		UpstreamDisabled:   s.Options.UpstreamDisabled,
 		DownstreamDisabled: s.Options.DownstreamDisabled,
 		FileIndex:          s.fileIndex,
+		Snapshot:           s.loadSnapshot(),
 
-		ApplyRemote: s.sendChangesToUpstream,
-		ApplyLocal:  s.downstream.applyChanges,
-		AddSymlink:  s.upstream.AddSymlink,
-		Log:         s.log,
+		ApplyRemote:     s.sendChangesToUpstream,
+		ApplyLocal:      s.downstream.applyChanges,
+		AddSymlink:      s.upstream.AddSymlink,
+		RemoteChecksums: s.downstream.collectChecksums,
+		Log:             s.log,
 
 		UpstreamDone: func() {
-			if onInitUploadDone != nil {
+			defer partDone()
+			if onInitUploadDone != nil || s.Options.StatePath != "" {
 				if s.Options.UpstreamDisabled == false {
 					for len(s.upstream.events) > 0 || s.upstream.IsBusy() {
 						time.Sleep(time.Millisecond * 100)
 					}
 				}
+			}
 
+			if onInitUploadDone != nil {
 				s.log.Info("Upstream - Initial sync completed")
 				close(onInitUploadDone)
 			}
 		},
 		DownstreamDone: func() {
+			defer partDone()
 			if onInitDownloadDone != nil {
 				s.log.Info("Downstream - Initial sync completed")
 				close(onInitDownloadDone)
@@ -290,7 +368,74 @@ func (s *Sync) initialSync(onInitUploadDone chan struct{}, onInitDownloadDone ch
 		},
 	})
 
-	return initialSync.Run(downloadChanges)
+	return initialSync.Run(s.remoteState())
+}
+
+// loadSnapshot loads the state of the last sync session, which is only usable if both directions are synced
+func (s *Sync) loadSnapshot() map[string]*FileInformation {
+	if s.Options.StatePath == "" || s.Options.UpstreamDisabled || s.Options.DownstreamDisabled {
+		return nil
+	}
+
+	snapshot, err := loadState(s.Options.StatePath)
+	if err != nil {
+		s.log.Infof("Error loading sync state, will do a full initial sync: %v", err)
+		return nil
+	} else if snapshot != nil {
+		s.log.Infof("Initial Sync - Use sync state of last session with %d path(s)", len(snapshot))
+	}
+
+	return snapshot
+}
+
+// remoteState returns the remote files of the file index without symlinks
+func (s *Sync) remoteState() map[string]*FileInformation {
+	s.fileIndex.fileMapMutex.Lock()
+	defer s.fileIndex.fileMapMutex.Unlock()
+
+	remoteState := make(map[string]*FileInformation)
+	for key, element := range s.fileIndex.fileMap {
+		if element.IsSymbolicLink {
+			continue
+		}
+
+		remoteState[key] = element
+	}
+
+	return remoteState
+}
+
+// onInitialSyncDone marks the initial sync as completed and starts persisting the sync state,
+// because the file index now represents the synced state of both sides
+func (s *Sync) onInitialSyncDone() {
+	s.initialSyncCompletedMutex.Lock()
+	s.initialSyncCompleted = true
+	s.initialSyncCompletedMutex.Unlock()
+
+	if s.Options.StatePath == "" || s.Options.UpstreamDisabled || s.Options.DownstreamDisabled {
+		return
+	}
+
+	err := s.saveState()
+	if err != nil {
+		s.log.Infof("Error saving sync state: %v", err)
+	}
+
+	go s.persistState()
+}
+
+func (s *Sync) isInitialSyncCompleted() bool {
+	s.initialSyncCompletedMutex.Lock()
+	defer s.initialSyncCompletedMutex.Unlock()
+
+	return s.initialSyncCompleted
+}
+
+// applyMappedMode sets the permissions that are configured for the given path, if there are any
+func (s *Sync) applyMappedMode(absolutePath, relativePath string, isDir bool) {
+	if mode, ok := s.permissionMapper.Mode(relativePath, isDir); ok {
+		_ = os.Chmod(absolutePath, mode)
+	}
 }
 
 func (s *Sync) sendChangesToUpstream(changes []*FileInformation, remove bool) {
@@ -352,6 +497,16 @@ func (s *Sync) Stop(fatalError error) {
 			}
 		}
 
+		close(s.stopped)
+
+		// persist the last known state, so that the next sync can continue from there
+		if s.isInitialSyncCompleted() && s.Options.UpstreamDisabled == false && s.Options.DownstreamDisabled == false {
+			err := s.saveState()
+			if err != nil {
+				s.log.Infof("Error saving sync state: %v", err)
+			}
+		}
+
 		if fatalError != nil {
 			s.Error(fatalError)
 
@@ -59,7 +59,8 @@ func createTestSyncClient(testLocalPath string, testCases testCaseList) (*Sync,
 		return nil, err
 	}
 
-	sync.onError = make(chan error)
+	// the error of a sync that is stopped with an error is not read by the tests
+	sync.onError = make(chan error, 1)
 	return sync, nil
 }
 
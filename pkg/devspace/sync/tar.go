@@ -2,8 +2,11 @@ package sync
 
 import (
 	"archive/tar"
-	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/loft-sh/devspace/helper/server/compression"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
+	"github.com/loft-sh/devspace/helper/util"
 	"io"
 	"io/ioutil"
 	"os"
@@ -14,6 +17,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
 	"github.com/loft-sh/devspace/pkg/util/log"
 
 	"github.com/pkg/errors"
@@ -24,6 +28,13 @@ type Unarchiver struct {
 	syncConfig    *Sync
 	forceOverride bool
 
+	// keptLocal holds the paths of conflicting files where the local version was kept
+	// and needs to be uploaded again
+	keptLocal []string
+
+	// progress tracks the extracted bytes of the current download
+	progress *progressTracker
+
 	log log.Logger
 }
 
@@ -37,18 +48,18 @@ func NewUnarchiver(syncConfig *Sync, forceOverride bool, log log.Logger) *Unarch
 }
 
 // Untar untars the given reader into the destination directory
-func (u *Unarchiver) Untar(fromReader io.ReadCloser, toPath string) error {
+func (u *Unarchiver) Untar(fromReader io.ReadCloser, codec compression.Codec, toPath string) error {
 	defer fromReader.Close()
 
 	fileCounter := 0
-	gzr, err := gzip.NewReader(fromReader)
+	decompressor, err := compression.NewReader(fromReader, codec)
 	if err != nil {
 		return errors.Errorf("error decompressing: %v", err)
 	}
 
-	defer gzr.Close()
+	defer decompressor.Close()
 
-	tarReader := tar.NewReader(gzr)
+	tarReader := tar.NewReader(decompressor)
 	for {
 		shouldContinue, err := u.untarNext(toPath, tarReader)
 		if err != nil {
@@ -81,9 +92,38 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 	outFileName := path.Join(destPath, relativePath)
 	baseName := path.Dir(outFileName)
 
-	// Check if newer file is there and then don't override?
+	// Check if the local file was changed as well since the last sync, a preserved or ignored
+	// symlink is compared by itself and not by its target
 	stat, err := os.Stat(outFileName)
-	if err == nil && u.forceOverride == false {
+	isSymlink, _ := lstatSymlink(u.syncConfig.Options.Symlinks, relativePath, outFileName)
+	if isSymlink {
+		stat, err = os.Lstat(outFileName)
+	}
+
+	conflictCopy := false
+	if err == nil && u.forceOverride == false && header.FileInfo().IsDir() == false && u.syncConfig.isLocallyChanged(relativePath, stat) {
+		resolution := u.syncConfig.resolveConflict(stat.ModTime().Unix(), header.ModTime.Unix())
+		u.syncConfig.reportConflict(relativePath, resolution)
+		if resolution != ConflictResolutionKeepRemote {
+			// Remember the remote state, so that the local version is uploaded again
+			u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
+				Name:        relativePath,
+				Mtime:       header.ModTime.Unix(),
+				Mode:        header.FileInfo().Mode(),
+				Size:        header.FileInfo().Size(),
+				IsDirectory: false,
+			}
+			u.keptLocal = append(u.keptLocal, relativePath)
+			if resolution == ConflictResolutionKeepLocal {
+				return true, nil
+			}
+
+			// Write the remote version next to the local one
+			outFileName = outFileName + ConflictSuffix
+			conflictCopy = true
+		}
+	} else if err == nil && u.forceOverride == false {
+		// Check if newer file is there and then don't override?
 		if stat.ModTime().Unix() > header.FileInfo().ModTime().Unix() {
 			// Update filemap otherwise we download and download again
 			u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
@@ -101,6 +141,16 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 		}
 	}
 
+	// The symlink is replaced, because writing the file or creating the folder would follow it
+	// to its target, which could be outside of the sync path
+	if isSymlink && conflictCopy == false {
+		if err := os.Remove(outFileName); err != nil && os.IsNotExist(err) == false {
+			return false, errors.Wrap(err, "remove symlink")
+		}
+
+		stat = nil
+	}
+
 	if err := u.createAllFolders(baseName, 0755); err != nil {
 		return false, err
 	}
@@ -129,7 +179,7 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 	}
 
 	defer outFile.Close()
-	if _, err := io.Copy(outFile, tarReader); err != nil {
+	if _, err := io.Copy(outFile, u.progress.reader(tarReader)); err != nil {
 		return false, errors.Wrap(err, "copy file to reader")
 	}
 
@@ -149,10 +199,36 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 		_ = os.Chmod(outFileName, header.FileInfo().Mode())
 	}
 
+	// Apply the configured permissions
+	u.syncConfig.applyMappedMode(outFileName, relativePath, false)
+
 	// Set mod time correctly
 	_ = os.Chtimes(outFileName, time.Now(), header.ModTime)
 
+	// The conflict copy is uploaded as a new file
+	if conflictCopy {
+		return true, nil
+	}
+
 	// Execute command if defined
+	err = u.executeFileChangeCmd(outFileName)
+	if err != nil {
+		return false, err
+	}
+
+	// Update fileMap so that upstream does not upload the file
+	u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
+		Name:        relativePath,
+		Mtime:       header.ModTime.Unix(),
+		Mode:        header.FileInfo().Mode(),
+		Size:        header.FileInfo().Size(),
+		IsDirectory: false,
+	}
+
+	return true, nil
+}
+
+func (u *Unarchiver) executeFileChangeCmd(outFileName string) error {
 	if u.syncConfig.Options.FileChangeCmd != "" {
 		cmdArgs := make([]string, 0, len(u.syncConfig.Options.FileChangeArgs))
 		for _, arg := range u.syncConfig.Options.FileChangeArgs {
@@ -165,20 +241,11 @@ func (u *Unarchiver) untarNext(destPath string, tarReader *tar.Reader) (bool, er
 
 		out, err := exec.Command(u.syncConfig.Options.FileChangeCmd, cmdArgs...).CombinedOutput()
 		if err != nil {
-			return false, errors.Errorf("Error executing command '%s %s': %s => %v", u.syncConfig.Options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
+			return errors.Errorf("Error executing command '%s %s': %s => %v", u.syncConfig.Options.FileChangeCmd, strings.Join(cmdArgs, " "), string(out), err)
 		}
 	}
 
-	// Update fileMap so that upstream does not upload the file
-	u.syncConfig.fileIndex.fileMap[relativePath] = &FileInformation{
-		Name:        relativePath,
-		Mtime:       header.ModTime.Unix(),
-		Mode:        header.FileInfo().Mode(),
-		Size:        header.FileInfo().Size(),
-		IsDirectory: false,
-	}
-
-	return true, nil
+	return nil
 }
 
 func (u *Unarchiver) createAllFolders(name string, perm os.FileMode) error {
@@ -187,10 +254,22 @@ func (u *Unarchiver) createAllFolders(name string, perm os.FileMode) error {
 		return err
 	}
 
+	localPath := filepath.ToSlash(u.syncConfig.LocalPath)
 	slashPath := filepath.ToSlash(absPath)
 	pathParts := strings.Split(slashPath, "/")
 	for i := 1; i < len(pathParts); i++ {
 		dirToCreate := strings.Join(pathParts[:i+1], "/")
+
+		// A preserved or ignored symlink within the sync path is replaced by the folder,
+		// otherwise the files of the folder would be written to the symlink target
+		if strings.HasPrefix(dirToCreate, localPath+"/") {
+			if isSymlink, _ := lstatSymlink(u.syncConfig.Options.Symlinks, dirToCreate[len(localPath):], dirToCreate); isSymlink {
+				if err := os.Remove(dirToCreate); err != nil && os.IsNotExist(err) == false {
+					return errors.Errorf("Error removing symlink %s: %v", dirToCreate, err)
+				}
+			}
+		}
+
 		err := os.Mkdir(dirToCreate, perm)
 		if err != nil {
 			if os.IsExist(err) {
@@ -200,6 +279,11 @@ func (u *Unarchiver) createAllFolders(name string, perm os.FileMode) error {
 			return errors.Errorf("Error creating %s: %v", dirToCreate, err)
 		}
 
+		// Apply the configured permissions to directories within the sync path
+		if strings.HasPrefix(dirToCreate, localPath+"/") {
+			u.syncConfig.applyMappedMode(dirToCreate, dirToCreate[len(localPath):], true)
+		}
+
 		if u.syncConfig.Options.DirCreateCmd != "" {
 			cmdArgs := make([]string, 0, len(u.syncConfig.Options.DirCreateArgs))
 			for _, arg := range u.syncConfig.Options.DirCreateArgs {
@@ -226,6 +310,16 @@ type Archiver struct {
 	ignoreMatcher ignoreparser.IgnoreParser
 	writer        *tar.Writer
 	writtenFiles  map[string]*FileInformation
+
+	// compressor is the compression of the archive, if set the compression is skipped
+	// for file types that are compressed already
+	compressor *compression.Writer
+
+	// progress tracks the archived bytes of the current upload
+	progress *progressTracker
+
+	// symlinks defines if symlinks are archived as the files they point to, as symlinks or not at all
+	symlinks latest.SyncSymlinks
 }
 
 // NewArchiver creates a new archiver
@@ -251,6 +345,15 @@ func (a *Archiver) AddToArchive(relativePath string) error {
 		return nil
 	}
 
+	// Preserved symlinks are archived as symlinks, ignored ones are skipped
+	if isSymlink, fileInformation := lstatSymlink(a.symlinks, relativePath, absFilepath); isSymlink {
+		if fileInformation == nil || (a.ignoreMatcher != nil && a.ignoreMatcher.Matches(relativePath, false)) {
+			return nil
+		}
+
+		return a.tarSymlink(fileInformation)
+	}
+
 	// We skip files that are suddenly not there anymore
 	stat, err := os.Stat(absFilepath)
 	if err != nil {
@@ -339,6 +442,19 @@ func (a *Archiver) tarFile(target *FileInformation, targetStat os.FileInfo) erro
 	hdr.Mode = fillGo18FileTypeBits(int64(chmodTarEntry(os.FileMode(hdr.Mode))), targetStat)
 	hdr.ModTime = time.Unix(target.Mtime, 0)
 
+	// The checksum is sent with the header, so the container can verify the file before replacing the old one
+	if targetStat.Mode().IsRegular() {
+		hash := sha256.New()
+		if _, err := io.CopyN(hash, f, targetStat.Size()); err != nil {
+			return errors.Wrap(err, "checksum file")
+		} else if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return errors.Wrap(err, "seek file")
+		}
+
+		hdr.Format = tar.FormatPAX
+		hdr.PAXRecords = map[string]string{util.ChecksumPAXRecord: hex.EncodeToString(hash.Sum(nil))}
+	}
+
 	if err := a.writer.WriteHeader(hdr); err != nil {
 		return errors.Wrap(err, "tar write header")
 	}
@@ -348,7 +464,12 @@ func (a *Archiver) tarFile(target *FileInformation, targetStat os.FileInfo) erro
 		return nil
 	}
 
-	if copied, err := io.CopyN(a.writer, f, targetStat.Size()); err != nil {
+	err = a.compressor.SkipCompression(compression.IsCompressed(target.Name))
+	if err != nil {
+		return errors.Wrap(err, "skip compression")
+	}
+
+	if copied, err := io.CopyN(a.writer, a.progress.reader(f), targetStat.Size()); err != nil {
 		return errors.Wrap(err, "tar copy file")
 	} else if copied != targetStat.Size() {
 		return errors.New("tar: file truncated during read")
@@ -358,6 +479,22 @@ func (a *Archiver) tarFile(target *FileInformation, targetStat os.FileInfo) erro
 	return nil
 }
 
+func (a *Archiver) tarSymlink(target *FileInformation) error {
+	hdr := &tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     target.Name,
+		Linkname: target.LinkTarget,
+		Mode:     int64(chmodTarEntry(0777)),
+		ModTime:  time.Unix(target.Mtime, 0),
+	}
+	if err := a.writer.WriteHeader(hdr); err != nil {
+		return errors.Wrap(err, "tar write header")
+	}
+
+	a.writtenFiles[target.Name] = target
+	return nil
+}
+
 const (
 	modeISDIR  = 040000  // Directory
 	modeISFIFO = 010000  // FIFO
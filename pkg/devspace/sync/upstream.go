@@ -2,9 +2,9 @@ package sync
 
 import (
 	"archive/tar"
-	"compress/gzip"
 	"context"
 	"fmt"
+	"github.com/loft-sh/devspace/helper/server/compression"
 	"github.com/loft-sh/devspace/helper/server/ignoreparser"
 	"io"
 	"os"
@@ -19,6 +19,8 @@ import (
 	"github.com/loft-sh/devspace/helper/util"
 	"github.com/pkg/errors"
 	"github.com/syncthing/notify"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type upstream struct {
@@ -37,9 +39,24 @@ type upstream struct {
 	eventBuffer      []notify.EventInfo
 	eventBufferMutex sync.Mutex
 
+	// eventsTaken and eventsApplied count the events that were taken from the event buffer and the
+	// events whose changes were applied remotely, both are guarded by the eventBufferMutex
+	eventsTaken   int64
+	eventsApplied int64
+
+	// uploadFailures counts the failed uploads of a file in a row, it is guarded
+	// by the fileMapMutex
+	uploadFailures map[string]int
+
+	// largeFilesUnsupported is true if the helper doesn't support chunked uploads of large files,
+	// it is guarded by the fileMapMutex
+	largeFilesUnsupported bool
+
 	workingDirectory string
 
 	ignoreMatcher ignoreparser.IgnoreParser
+
+	negotiator *negotiator
 }
 
 const removeFilesBufferSize = 64
@@ -75,6 +92,7 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 		return nil, errors.Wrap(err, "compile paths")
 	}
 
+	client := remote.NewUpstreamClient(conn)
 	workingDirectory, _ := os.Getwd()
 	return &upstream{
 		events:      make(chan notify.EventInfo, 1000), // High buffer size so we don't miss any fsevents if there are a lot of changes
@@ -84,12 +102,15 @@ func newUpstream(reader io.ReadCloser, writer io.WriteCloser, sync *Sync) (*upst
 		sync:        sync,
 		isBusy:      true,
 
+		uploadFailures: map[string]int{},
+
 		reader: reader,
 		writer: writer,
-		client: remote.NewUpstreamClient(conn),
+		client: client,
 
 		workingDirectory: workingDirectory,
 		ignoreMatcher:    ignoreMatcher,
+		negotiator:       newNegotiator(client.Handshake, &sync.Options),
 	}, nil
 }
 
@@ -159,6 +180,7 @@ func (u *upstream) getEvents() []notify.EventInfo {
 	if len(u.eventBuffer) > 0 {
 		eventsRef = u.eventBuffer
 		u.eventBuffer = make([]notify.EventInfo, 0, 64)
+		u.eventsTaken += int64(len(eventsRef))
 	}
 
 	return eventsRef
@@ -176,6 +198,7 @@ func (u *upstream) mainLoop() error {
 		var (
 			changes      []*FileInformation
 			changeAmount = 0
+			eventAmount  = 0
 		)
 
 		// gather changes
@@ -196,6 +219,13 @@ func (u *upstream) mainLoop() error {
 				}
 
 				changes = append(changes, fileInformation...)
+				eventAmount += len(events)
+			}
+
+			// Events that didn't result in any change are done already
+			if len(changes) == 0 && eventAmount > 0 {
+				u.eventsDone(eventAmount)
+				eventAmount = 0
 			}
 
 			// We gather changes till there are no more changes or
@@ -215,10 +245,13 @@ func (u *upstream) mainLoop() error {
 		}
 
 		// apply the changes
+		u.sync.setPendingUploads(len(changes))
 		err := u.applyChanges(changes)
 		if err != nil {
 			return errors.Wrap(err, "apply changes")
 		}
+
+		u.eventsDone(eventAmount)
 	}
 }
 
@@ -235,7 +268,7 @@ func (u *upstream) getFileInformationFromEvent(events []notify.EventInfo) ([]*Fi
 			changes = append(changes, fileInfo)
 		} else {
 			fullPath := event.Path()
-			relativePath := getRelativeFromFullPath(fullPath, u.sync.LocalPath)
+			relativePath := u.sync.fileIndex.CanonicalPath(getRelativeFromFullPath(fullPath, u.sync.LocalPath))
 
 			// Determine what kind of change we got (Create or Remove)
 			newChange, err := u.evaluateChange(relativePath, fullPath)
@@ -253,6 +286,17 @@ func (u *upstream) getFileInformationFromEvent(events []notify.EventInfo) ([]*Fi
 }
 
 func (u *upstream) evaluateChange(relativePath, fullpath string) (*FileInformation, error) {
+	// Preserved symlinks are uploaded as they are, even if their target does not exist
+	if isSymlink, fileInfo := lstatSymlink(u.sync.Options.Symlinks, relativePath, fullpath); isSymlink {
+		if fileInfo == nil || (u.sync.uploadIgnoreMatcher != nil && u.sync.uploadIgnoreMatcher.Matches(relativePath, false)) {
+			return nil, nil
+		} else if shouldUpload(u.sync, fileInfo) {
+			return fileInfo, nil
+		}
+
+		return nil, nil
+	}
+
 	stat, err := os.Stat(fullpath)
 
 	// File / Folder exist -> Create File or Folder
@@ -404,7 +448,30 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 
 	// Apply creates
 	if len(creates) > 0 {
+		// Skip files that were changed in the container as well
+		u.sync.fileIndex.fileMapMutex.Lock()
+		creates = u.checkConflicts(creates)
+		u.sync.fileIndex.fileMapMutex.Unlock()
+
+		// Large files that already exist remotely are uploaded as delta first
+		if len(creates) > 0 {
+			creates = u.applyDeltas(creates)
+		}
+
+		// Large files are uploaded on their own, so that an interrupted upload can be resumed
+		if len(creates) > 0 {
+			var err error
+			creates, err = u.applyLargeFiles(creates)
+			if err != nil {
+				return errors.Wrap(err, "upload large files")
+			}
+		}
+
 		err := func() error {
+			if len(creates) == 0 {
+				return nil
+			}
+
 			u.sync.fileIndex.fileMapMutex.Lock()
 			defer u.sync.fileIndex.fileMapMutex.Unlock()
 
@@ -419,6 +486,7 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 				}
 
 				u.sync.log.Infof("Upstream - Retry upload because of error: %v", err)
+				u.sync.setLastError(err)
 				creates = u.updateUploadChanges(creates)
 				if len(creates) == 0 {
 					break
@@ -432,12 +500,33 @@ func (u *upstream) applyChanges(changes []*FileInformation) error {
 		}
 	}
 
+	// The helper executes the batch commands once for all uploads of the batch
+	err := u.batchDone()
+	if err != nil {
+		return errors.Wrap(err, "batch done")
+	}
+
 	u.sync.log.Infof("Upstream - Successfully processed %d change(s)", len(changes))
+	u.sync.uploadDone()
 
 	// Restart container if needed
 	return u.RestartContainer()
 }
 
+// batchDone tells the helper that all changes of the batch were uploaded
+func (u *upstream) batchDone() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	_, err := u.client.BatchDone(ctx, &remote.Empty{})
+	if status.Code(err) == codes.Unimplemented {
+		// older helpers execute the batch commands after each upload
+		return nil
+	}
+
+	return err
+}
+
 func (u *upstream) RestartContainer() error {
 	if u.sync.Options.RestartContainer {
 		u.sync.log.Info("Upstream - Restarting container")
@@ -482,6 +571,8 @@ func (u *upstream) applyCreates(files []*FileInformation) error {
 		}
 	}
 	u.sync.log.Infof("Upstream - Upload %d create change(s) (Uncompressed ~%0.2f KB)", len(files), float64(size)/1024.0)
+	progress := u.sync.startProgress(ProgressDirectionUpload, len(files), size)
+	defer progress.Done()
 
 	// Create a pipe for reading and writing
 	reader, writer := io.Pipe()
@@ -492,12 +583,12 @@ func (u *upstream) applyCreates(files []*FileInformation) error {
 	errorChan := make(chan error)
 	go func() {
 		var compressErr error
-		archiver, compressErr = u.compress(writer, files, u.ignoreMatcher)
+		archiver, compressErr = u.compress(writer, files, u.ignoreMatcher, progress)
 		errorChan <- compressErr
 	}()
 
 	// upload the archive
-	err := u.uploadArchive(reader)
+	result, err := u.uploadArchive(reader)
 	if err != nil {
 		return errors.Wrap(err, "upload archive")
 	}
@@ -508,28 +599,103 @@ func (u *upstream) applyCreates(files []*FileInformation) error {
 		return errors.Wrap(err, "compress archive")
 	}
 
+	// files that failed the checksum verification or couldn't be written were not applied in the
+	// container, if the archive broke off the files that were not received weren't applied either
+	failedFiles := map[string]bool{}
+	for _, name := range result.Failed {
+		failedFiles[name] = true
+	}
+	receivedFiles := map[string]bool{}
+	for _, name := range result.Received {
+		receivedFiles[name] = true
+	}
+
 	// finally update written files
+	requeue := []*FileInformation{}
 	for _, element := range archiver.WrittenFiles() {
+		if failedFiles[element.Name] {
+			if u.retryUpload(element, "Couldn't apply file") {
+				requeue = append(requeue, element)
+			}
+
+			continue
+		} else if result.Incomplete && !receivedFiles[element.Name] {
+			if u.retryUpload(element, "Upload broke off") {
+				requeue = append(requeue, element)
+			}
+
+			continue
+		}
+
+		delete(u.uploadFailures, element.Name)
 		u.sync.fileIndex.CreateDirInFileMap(path.Dir(element.Name))
 		u.sync.fileIndex.fileMap[element.Name] = element
 	}
 
+	u.requeue(requeue)
 	return nil
 }
 
-func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ignoreMatcher ignoreparser.IgnoreParser) (*Archiver, error) {
+// retryUpload counts the failed upload of the file and returns true if the upload should be retried
+func (u *upstream) retryUpload(file *FileInformation, reason string) bool {
+	u.uploadFailures[file.Name]++
+	if u.uploadFailures[file.Name] > syncRetries {
+		delete(u.uploadFailures, file.Name)
+		u.sync.log.Errorf("Upstream - %s for '%s', giving up after %d retries", reason, u.getRelativeUpstreamPath(file.Name), syncRetries)
+		return false
+	}
+
+	u.sync.log.Infof("Upstream - %s for '%s', retrying upload", reason, u.getRelativeUpstreamPath(file.Name))
+	return true
+}
+
+// requeue adds the files to the event buffer again, so they are uploaded with one of the next
+// batches. The files count as neither taken nor applied anymore, so waiting for the upload includes them
+func (u *upstream) requeue(files []*FileInformation) {
+	if len(files) == 0 {
+		return
+	}
+
+	u.isBusyMutex.Lock()
+	u.isBusy = true
+	u.isBusyMutex.Unlock()
+
+	// The buffer is used instead of the events channel, because the fileIndex is locked
+	// and a full channel could cause a deadlock
+	u.eventBufferMutex.Lock()
+	defer u.eventBufferMutex.Unlock()
+
+	for _, file := range files {
+		u.eventBuffer = append(u.eventBuffer, file)
+	}
+	u.eventsTaken -= int64(len(files))
+	u.eventsApplied -= int64(len(files))
+}
+
+func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ignoreMatcher ignoreparser.IgnoreParser, progress *progressTracker) (*Archiver, error) {
 	defer writer.Close()
 
 	// Use compression
-	gw := gzip.NewWriter(writer)
-	defer gw.Close()
+	codec, err := u.negotiator.compression()
+	if err != nil {
+		return nil, err
+	}
+
+	compressor, err := compression.NewWriter(writer, codec, u.sync.Options.CompressionLevel)
+	if err != nil {
+		return nil, err
+	}
+	defer compressor.Close()
 
 	// Create tar writer
-	tarWriter := tar.NewWriter(gw)
+	tarWriter := tar.NewWriter(compressor)
 	defer tarWriter.Close()
 
 	// Archive the given files
 	archiver := NewArchiver(u.sync.LocalPath, tarWriter, ignoreMatcher)
+	archiver.compressor = compressor
+	archiver.progress = progress
+	archiver.symlinks = u.sync.Options.Symlinks
 	for _, file := range files {
 		err := archiver.AddToArchive(file.Name)
 		if err != nil {
@@ -540,7 +706,8 @@ func (u *upstream) compress(writer io.WriteCloser, files []*FileInformation, ign
 	return archiver, nil
 }
 
-func (u *upstream) uploadArchive(reader io.ReadCloser) error {
+// uploadArchive uploads the tar archive and returns which files of it the container couldn't apply
+func (u *upstream) uploadArchive(reader io.ReadCloser) (*remote.UploadResult, error) {
 	defer reader.Close()
 
 	// cancel after 1 hour
@@ -550,7 +717,7 @@ func (u *upstream) uploadArchive(reader io.ReadCloser) error {
 	// Create upload client
 	uploadClient, err := u.client.Upload(ctx)
 	if err != nil {
-		return errors.Wrap(err, "upload")
+		return nil, errors.Wrap(err, "upload")
 	}
 
 	buf := make([]byte, 16*1024)
@@ -563,26 +730,26 @@ func (u *upstream) uploadArchive(reader io.ReadCloser) error {
 			if err != nil {
 				_, recvErr := uploadClient.CloseAndRecv()
 				if recvErr != nil {
-					return errors.Wrap(recvErr, "upload send")
+					return nil, errors.Wrap(recvErr, "upload send")
 				}
 
-				return errors.Wrap(err, "upload send")
+				return nil, errors.Wrap(err, "upload send")
 			}
+
+			u.sync.countUploaded(n)
 		}
 
 		if err == io.EOF {
-			_, err := uploadClient.CloseAndRecv()
+			result, err := uploadClient.CloseAndRecv()
 			if err != nil {
-				return errors.Wrap(err, "after upload")
+				return nil, errors.Wrap(err, "after upload")
 			}
 
-			break
+			return result, nil
 		} else if err != nil {
-			return errors.Wrap(err, "read tar")
+			return nil, errors.Wrap(err, "read tar")
 		}
 	}
-
-	return nil
 }
 
 func (u *upstream) applyRemoves(files []*FileInformation) error {
@@ -0,0 +1,63 @@
+package sync
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// waitInterval is the interval in which the upstream progress is checked while waiting
+var waitInterval = time.Millisecond * 100
+
+// WaitForUpload blocks until the initial sync is completed and all local changes that were
+// observed before the call were applied in the container
+func (s *Sync) WaitForUpload(ctx context.Context) error {
+	if s.Options.UpstreamDisabled || s.upstream == nil {
+		return nil
+	}
+
+	target := int64(-1)
+	for {
+		if s.isInitialSyncCompleted() {
+			// The target is determined after the initial sync, because the initial sync
+			// sends its changes through the upstream events as well
+			if target == -1 {
+				target = s.upstream.eventsObserved()
+			}
+			if s.upstream.eventsAppliedUntil(target) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Wrap(ctx.Err(), "wait for upload")
+		case <-s.stopped:
+			return errors.Errorf("sync on %s was stopped", s.LocalPath)
+		case <-time.After(waitInterval):
+		}
+	}
+}
+
+// eventsObserved returns the amount of events the upstream has received so far
+func (u *upstream) eventsObserved() int64 {
+	u.eventBufferMutex.Lock()
+	defer u.eventBufferMutex.Unlock()
+
+	return u.eventsTaken + int64(len(u.eventBuffer)) + int64(len(u.events))
+}
+
+func (u *upstream) eventsAppliedUntil(target int64) bool {
+	u.eventBufferMutex.Lock()
+	defer u.eventBufferMutex.Unlock()
+
+	return u.eventsApplied >= target
+}
+
+func (u *upstream) eventsDone(amount int) {
+	u.eventBufferMutex.Lock()
+	defer u.eventBufferMutex.Unlock()
+
+	u.eventsApplied += int64(amount)
+}
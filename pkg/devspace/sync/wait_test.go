@@ -0,0 +1,114 @@
+package sync
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/syncthing/notify"
+)
+
+func TestWaitForUpload(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	sync, err := NewSync(local, Options{Log: log.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync.upstream = &upstream{
+		events:      make(chan notify.EventInfo, 10),
+		eventBuffer: make([]notify.EventInfo, 0, 64),
+		sync:        sync,
+	}
+	sync.upstream.eventsTaken = 2
+	sync.upstream.eventBuffer = append(sync.upstream.eventBuffer, nil)
+
+	// The initial sync is not completed yet
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*300)
+	err = sync.WaitForUpload(ctx)
+	cancel()
+	if err == nil {
+		t.Fatal("Expected wait to fail before the initial sync is completed")
+	}
+
+	sync.initialSyncCompleted = true
+	sync.upstream.eventsDone(2)
+
+	// The buffered event was observed before the call and is not applied yet
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond*300)
+	err = sync.WaitForUpload(ctx)
+	cancel()
+	if err == nil {
+		t.Fatal("Expected wait to fail while observed events are pending")
+	}
+
+	go func() {
+		time.Sleep(time.Millisecond * 200)
+		sync.upstream.getEvents()
+		sync.upstream.eventsDone(1)
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = sync.WaitForUpload(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error while waiting for upload: %v", err)
+	}
+}
+
+func TestWaitForUploadRequeue(t *testing.T) {
+	local, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(local)
+
+	sync, err := NewSync(local, Options{Log: log.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sync.upstream = &upstream{
+		events:      make(chan notify.EventInfo, 10),
+		eventBuffer: make([]notify.EventInfo, 0, 64),
+		sync:        sync,
+	}
+	sync.initialSyncCompleted = true
+	sync.upstream.eventsTaken = 1
+
+	// The file of the taken event failed the checksum verification and is requeued
+	// before the batch is done
+	sync.upstream.requeue([]*FileInformation{{Name: "/test.txt", Mtime: 1, Size: 4}})
+	sync.upstream.eventsDone(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*300)
+	err = sync.WaitForUpload(ctx)
+	cancel()
+	if err == nil {
+		t.Fatal("Expected wait to fail while the requeued file is pending")
+	}
+	if sync.upstream.IsBusy() == false {
+		t.Fatal("Expected upstream to be busy after requeue")
+	}
+
+	events := sync.upstream.getEvents()
+	if len(events) != 1 || events[0].Path() != "/test.txt" {
+		t.Fatalf("Expected requeued file in events, got %v", events)
+	}
+	sync.upstream.eventsDone(1)
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	err = sync.WaitForUpload(ctx)
+	if err != nil {
+		t.Fatalf("Unexpected error while waiting for upload: %v", err)
+	}
+}
@@ -186,6 +186,18 @@ func StartReverseForward(reader io.ReadCloser, writer io.WriteCloser, tunnels []
 		}
 
 		c := make(chan bool, 1)
+		if portMapping.Protocol == latest.PortMappingProtocolUDP {
+			go func(closeStream chan bool, localPort, remotePort int32) {
+				err := startDatagramTunnel(client, "", localPort, remotePort, false, closeStream, logFile)
+				if err != nil {
+					errorsChan <- err
+				}
+			}(c, int32(localPort), int32(remotePort))
+			log.Donef("Reverse port forwarding started at %d:%d/udp (%s/%s)", remotePort, localPort, namespace, name)
+			closeStreams[i] = c
+			continue
+		}
+
 		go func(closeStream chan bool, localPort, remotePort int32) {
 			ctx := context.Background()
 			tunnelScheme, ok := remote.TunnelScheme_value[scheme]
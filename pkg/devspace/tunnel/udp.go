@@ -0,0 +1,139 @@
+package tunnel
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/loft-sh/devspace/helper/remote"
+	"github.com/loft-sh/devspace/helper/tunnel"
+	"github.com/loft-sh/devspace/helper/util"
+	"github.com/loft-sh/devspace/pkg/devspace/config/versions/latest"
+	logpkg "github.com/loft-sh/devspace/pkg/util/log"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// startDatagramTunnel tunnels the datagrams of a udp port mapping until closeStream is closed or the tunnel fails. If forward is
+// true, the local address is listened on and the helper dials the remote port for every session. Otherwise the helper listens
+// on the remote port and every session is dialed to the local port
+func startDatagramTunnel(client remote.TunnelClient, localAddress string, localPort, remotePort int32, forward bool, closeStream <-chan bool, log logpkg.Logger) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.InitTunnel(ctx)
+	if err != nil {
+		return fmt.Errorf("error sending init tunnel request: %v", err)
+	}
+
+	err = stream.Send(&remote.SocketDataRequest{
+		Port:    remotePort,
+		Scheme:  remote.TunnelScheme_UDP,
+		Forward: forward,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send initial tunnel request to server")
+	}
+
+	sendMutex := sync.Mutex{}
+	send := func(id uuid.UUID, data []byte, shouldClose bool) error {
+		sendMutex.Lock()
+		defer sendMutex.Unlock()
+
+		return stream.Send(&remote.SocketDataRequest{
+			RequestId:   id.String(),
+			Data:        data,
+			ShouldClose: shouldClose,
+		})
+	}
+	receive := func() (string, []byte, bool, error) {
+		m, err := stream.Recv()
+		if err != nil {
+			return "", nil, false, fmt.Errorf("error reading from stream: %v", err)
+		} else if m.HasErr {
+			return "", nil, false, errors.New(m.GetLogMessage().GetMessage())
+		}
+
+		return m.RequestId, m.Data, m.ShouldClose, nil
+	}
+
+	errorChan := make(chan error, 2)
+	if forward {
+		listener, err := net.ListenPacket("udp", net.JoinHostPort(localAddress, strconv.Itoa(int(localPort))))
+		if err != nil {
+			return errors.Wrapf(err, "listen on udp port %d", localPort)
+		}
+		defer listener.Close()
+
+		go func() {
+			errorChan <- tunnel.ListenDatagrams(listener, send)
+		}()
+		go func() {
+			errorChan <- tunnel.ReceiveDatagrams(receive, nil)
+		}()
+	} else {
+		address := fmt.Sprintf("localhost:%d", localPort)
+		go func() {
+			errorChan <- tunnel.ReceiveDatagrams(receive, func(id uuid.UUID) (*tunnel.Session, error) {
+				session, err := tunnel.DialDatagrams(id, address, send)
+				if err != nil {
+					log.Errorf("failed connecting to %s scheme udp: %v", address, err)
+				}
+
+				return session, err
+			})
+		}()
+	}
+
+	select {
+	case err := <-errorChan:
+		return err
+	case <-closeStream:
+		return nil
+	}
+}
+
+// StartForward forwards the local udp ports of the port mappings to the container through the helper tunnel
+func StartForward(reader io.ReadCloser, writer io.WriteCloser, portMappings []*latest.PortMapping, stopChan chan error, namespace string, name string, log logpkg.Logger) error {
+	conn, err := util.NewClientConnection(reader, writer)
+	if err != nil {
+		return errors.Wrap(err, "new client connection")
+	}
+	client := remote.NewTunnelClient(conn)
+
+	closeStream := make(chan bool)
+	defer close(closeStream)
+
+	errorsChan := make(chan error, len(portMappings))
+	for _, portMapping := range portMappings {
+		if portMapping.LocalPort == nil {
+			return fmt.Errorf("local port cannot be undefined")
+		}
+
+		localPort := *portMapping.LocalPort
+		remotePort := localPort
+		if portMapping.RemotePort != nil {
+			remotePort = *portMapping.RemotePort
+		}
+
+		localAddress := portMapping.BindAddress
+		if localAddress == "" {
+			localAddress = "localhost"
+		}
+
+		go func(localAddress string, localPort, remotePort int32) {
+			errorsChan <- startDatagramTunnel(client, localAddress, localPort, remotePort, true, closeStream, log)
+		}(localAddress, int32(localPort), int32(remotePort))
+		log.Donef("Port forwarding started on %d:%d/udp (%s/%s)", localPort, remotePort, namespace, name)
+	}
+
+	select {
+	case err := <-errorsChan:
+		return err
+	case <-stopChan:
+		return nil
+	}
+}
@@ -0,0 +1,41 @@
+package exit
+
+import (
+	"sync"
+)
+
+// cleanups holds the functions that remove the resources created by this process
+var (
+	cleanups      = map[string]func(){}
+	cleanupsMutex sync.Mutex
+)
+
+// RegisterCleanup registers a function under the key that is run once when Cleanup is called. A function that
+// was registered with the same key before is replaced
+func RegisterCleanup(key string, cleanup func()) {
+	cleanupsMutex.Lock()
+	defer cleanupsMutex.Unlock()
+
+	cleanups[key] = cleanup
+}
+
+// UnregisterCleanup removes the function with the key without running it
+func UnregisterCleanup(key string) {
+	cleanupsMutex.Lock()
+	defer cleanupsMutex.Unlock()
+
+	delete(cleanups, key)
+}
+
+// Cleanup runs and removes all registered functions. The functions are run without holding the lock,
+// so that they can take their own locks in any order
+func Cleanup() {
+	cleanupsMutex.Lock()
+	run := cleanups
+	cleanups = map[string]func(){}
+	cleanupsMutex.Unlock()
+
+	for _, cleanup := range run {
+		cleanup()
+	}
+}